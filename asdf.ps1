@@ -23,7 +23,10 @@ function asdf {
   $asdf = $(Get-Command -CommandType Application asdf).Source
 
   if ($args.Count -gt 0 -and $args[0] -eq 'shell') {
-    Invoke-Expression $(& $asdf 'export-shell-version' pwsh $args[1..($args.Count + -1)])
+    $changes = & $asdf 'export-shell-version' pwsh $args[1..($args.Count + -1)] | Out-String
+    if ($LASTEXITCODE -eq 0) {
+      Invoke-Expression $changes
+    }
   }
   else {
     & $asdf $args
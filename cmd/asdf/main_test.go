@@ -19,6 +19,10 @@ func TestBatsTests(t *testing.T) {
 	// Run tests with the asdf binary in the temp directory
 
 	// Uncomment these as they are implemented
+	t.Run("activate_command", func(t *testing.T) {
+		runBatsFile(t, dir, "activate_command.bats")
+	})
+
 	t.Run("current_command", func(t *testing.T) {
 		runBatsFile(t, dir, "current_command.bats")
 	})
@@ -51,14 +55,38 @@ func TestBatsTests(t *testing.T) {
 		runBatsFile(t, dir, "plugin_extension_command.bats")
 	})
 
+	t.Run("plugin_index_command", func(t *testing.T) {
+		runBatsFile(t, dir, "plugin_index_command.bats")
+	})
+
+	t.Run("plugin_info_command", func(t *testing.T) {
+		runBatsFile(t, dir, "plugin_info_command.bats")
+	})
+
 	t.Run("plugin_list_all_command", func(t *testing.T) {
 		runBatsFile(t, dir, "plugin_list_all_command.bats")
 	})
 
+	t.Run("plugin_list_command", func(t *testing.T) {
+		runBatsFile(t, dir, "plugin_list_command.bats")
+	})
+
+	t.Run("plugin_lock_command", func(t *testing.T) {
+		runBatsFile(t, dir, "plugin_lock_command.bats")
+	})
+
+	t.Run("plugin_new_command", func(t *testing.T) {
+		runBatsFile(t, dir, "plugin_new_command.bats")
+	})
+
 	t.Run("plugin_remove_command", func(t *testing.T) {
 		runBatsFile(t, dir, "plugin_remove_command.bats")
 	})
 
+	t.Run("plugin_search_command", func(t *testing.T) {
+		runBatsFile(t, dir, "plugin_search_command.bats")
+	})
+
 	t.Run("plugin_test_command", func(t *testing.T) {
 		runBatsFile(t, dir, "plugin_test_command.bats")
 	})
@@ -67,6 +95,10 @@ func TestBatsTests(t *testing.T) {
 		runBatsFile(t, dir, "plugin_update_command.bats")
 	})
 
+	t.Run("plugin_verify_command", func(t *testing.T) {
+		runBatsFile(t, dir, "plugin_verify_command.bats")
+	})
+
 	t.Run("remove_command", func(t *testing.T) {
 		runBatsFile(t, dir, "remove_command.bats")
 	})
@@ -75,6 +107,10 @@ func TestBatsTests(t *testing.T) {
 		runBatsFile(t, dir, "reshim_command.bats")
 	})
 
+	t.Run("shell_command", func(t *testing.T) {
+		runBatsFile(t, dir, "shell_command.bats")
+	})
+
 	t.Run("shim_env_command", func(t *testing.T) {
 		runBatsFile(t, dir, "shim_env_command.bats")
 	})
@@ -106,6 +142,10 @@ func TestBatsTests(t *testing.T) {
 	t.Run("which_command", func(t *testing.T) {
 		runBatsFile(t, dir, "which_command.bats")
 	})
+
+	t.Run("x_command", func(t *testing.T) {
+		runBatsFile(t, dir, "x_command.bats")
+	})
 }
 
 func runBatsFile(t *testing.T, dir, filename string) {
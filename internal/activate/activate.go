@@ -0,0 +1,252 @@
+// Package activate implements asdf's shell activation mode, an alternative to
+// shims where a prompt hook puts the executable directories of the tool
+// versions set for the current directory directly on PATH and applies their
+// exec-env variables to the shell.
+//
+// To add activation support for a shell, add a file named "hook.<shell>"
+// containing the hook code to this directory and map the shell to the format
+// hook-env output is written in for it.
+package activate
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/envformat"
+	"github.com/asdf-vm/asdf/internal/execenv"
+	"github.com/asdf-vm/asdf/internal/toolenv"
+	"github.com/asdf-vm/asdf/internal/trust"
+)
+
+// StateVariable is the environment variable hook-env stores its state in
+// between prompts.
+const StateVariable = "__ASDF_ACTIVATE_STATE"
+
+//go:embed hook.*
+var hooks embed.FS
+
+// formats maps shell names to the envformat format hook-env output is written
+// in for the shell. Elvish and Nushell parse JSON output in their hooks.
+var formats = map[string]string{
+	"bash":    "sh",
+	"zsh":     "sh",
+	"fish":    "fish",
+	"elvish":  "json",
+	"nushell": "json",
+	"pwsh":    "pwsh",
+}
+
+// state records the changes hook-env made to the environment, so they can be
+// reverted when the directory or the versions set for it change.
+type state struct {
+	Fingerprint string `json:"fingerprint"`
+	// Paths are the directories hook-env added to PATH
+	Paths []string `json:"paths"`
+	// Previous holds the values variables hook-env set had before it set them.
+	// A nil value means the variable was not set.
+	Previous map[string]*string `json:"previous"`
+}
+
+// Get returns a file containing the hook code for the given shell if it is
+// found.
+func Get(shell string) (fs.File, bool) {
+	file, err := hooks.Open("hook." + shell)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, false
+		}
+		panic(err) // This should never happen.
+	}
+	return file, true
+}
+
+// Names returns a slice of shell names that activation is available for.
+func Names() []string {
+	files, _ := fs.Glob(hooks, "hook.*")
+	for i, file := range files {
+		files[i] = strings.TrimPrefix(file, "hook.")
+	}
+	slices.Sort(files)
+	return files
+}
+
+// Format returns the name of the envformat format hook-env output is written
+// in for the shell.
+func Format(shell string) (string, bool) {
+	format, ok := formats[shell]
+	return format, ok
+}
+
+// HookEnv returns the changes that must be made to env, the environment of the
+// shell, so it contains the environment of the tool versions set in directory.
+// Changes made by a previous call, recorded in StateVariable, are reverted
+// when they no longer apply. When nothing that affects the resolved versions
+// has changed since the previous call no changes are returned. Anything the
+// exec-env callbacks print is written to stderr.
+func HookEnv(conf config.Config, directory string, env map[string]string, stderr io.Writer) (envformat.Changes, error) {
+	previous := decodeState(env[StateVariable])
+	fingerprint := fingerprint(conf, directory, env)
+	if previous.Fingerprint == fingerprint {
+		return envformat.Changes{}, nil
+	}
+
+	base := revert(env, previous)
+
+	toolVersions, err := toolenv.Resolve(conf, directory)
+	if err != nil {
+		return envformat.Changes{}, err
+	}
+
+	target, err := toolenv.Build(conf, toolVersions, base, stderr)
+	if err != nil {
+		return envformat.Changes{}, err
+	}
+
+	current := state{
+		Fingerprint: fingerprint,
+		Paths:       addedPaths(base["PATH"], target["PATH"]),
+		Previous:    map[string]*string{},
+	}
+
+	for name, value := range target {
+		if name == "PATH" {
+			continue
+		}
+
+		if baseValue, ok := base[name]; !ok {
+			current.Previous[name] = nil
+		} else if baseValue != value {
+			current.Previous[name] = &baseValue
+		}
+	}
+
+	target[StateVariable], err = encodeState(current)
+	if err != nil {
+		return envformat.Changes{}, err
+	}
+
+	set, unset := execenv.Diff(env, target)
+	return envformat.Changes{Set: set, Unset: unset}, nil
+}
+
+// revert returns a copy of env with the changes recorded in previous undone
+func revert(env map[string]string, previous state) map[string]string {
+	base := maps.Clone(env)
+	delete(base, StateVariable)
+
+	for name, value := range previous.Previous {
+		if value == nil {
+			delete(base, name)
+		} else {
+			base[name] = *value
+		}
+	}
+
+	if len(previous.Paths) > 0 {
+		var paths []string
+		removed := slices.Clone(previous.Paths)
+		for _, path := range filepath.SplitList(base["PATH"]) {
+			if index := slices.Index(removed, path); index >= 0 {
+				removed = slices.Delete(removed, index, index+1)
+				continue
+			}
+			paths = append(paths, path)
+		}
+		base["PATH"] = strings.Join(paths, string(os.PathListSeparator))
+	}
+
+	return base
+}
+
+// addedPaths returns the entries of PATH in after that are not in before
+func addedPaths(before, after string) (added []string) {
+	remaining := filepath.SplitList(before)
+	for _, path := range filepath.SplitList(after) {
+		if index := slices.Index(remaining, path); index >= 0 {
+			remaining = slices.Delete(remaining, index, index+1)
+			continue
+		}
+		added = append(added, path)
+	}
+	return added
+}
+
+// fingerprint returns a hash of everything that is checked cheaply and may
+// change the resolved tool versions: the directory, the version files in it
+// and its parents and their trust records, ASDF_*_VERSION variables and the
+// installed plugins and versions. Legacy version files are only picked up when the directory
+// changes, as finding their names requires running plugin callbacks.
+func fingerprint(conf config.Config, directory string, env map[string]string) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "dir %s\n", directory)
+
+	for dir := directory; ; dir = filepath.Dir(dir) {
+		versionFile := filepath.Join(dir, conf.DefaultToolVersionsFilename)
+		writeFileInfo(hash, versionFile)
+		if record, err := trust.RecordPath(conf, versionFile); err == nil {
+			writeFileInfo(hash, record)
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		if strings.HasPrefix(name, "ASDF_") && strings.HasSuffix(name, "_VERSION") {
+			fmt.Fprintf(hash, "env %s=%s\n", name, env[name])
+		}
+	}
+
+	writeFileInfo(hash, data.PluginsDirectory(conf.DataDir))
+	installsDir := data.InstallsDirectory(conf.DataDir)
+	writeFileInfo(hash, installsDir)
+	entries, _ := os.ReadDir(installsDir)
+	for _, entry := range entries {
+		writeFileInfo(hash, filepath.Join(installsDir, entry.Name()))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func writeFileInfo(w io.Writer, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "file %s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+}
+
+func encodeState(s state) (string, error) {
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(bytes), nil
+}
+
+// decodeState returns the state stored in value. Missing or invalid state is
+// treated as if hook-env had never run.
+func decodeState(value string) (s state) {
+	bytes, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return state{}
+	}
+	if err := json.Unmarshal(bytes, &s); err != nil {
+		return state{}
+	}
+	return s
+}
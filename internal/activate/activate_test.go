@@ -0,0 +1,164 @@
+package activate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/envformat"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/installtest"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/trust"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginName = "lua"
+
+func TestGet(t *testing.T) {
+	t.Run("returns file when hook with name exists", func(t *testing.T) {
+		file, found := Get("bash")
+		assert.True(t, found)
+		assert.NotNil(t, file)
+		file.Close()
+	})
+
+	t.Run("returns false when hook with name does not exist", func(t *testing.T) {
+		_, found := Get("non-existent")
+		assert.False(t, found)
+	})
+}
+
+func TestNames(t *testing.T) {
+	t.Run("returns a format for every shell with a hook", func(t *testing.T) {
+		for _, shell := range Names() {
+			format, ok := Format(shell)
+			assert.True(t, ok, shell)
+			assert.Contains(t, envformat.Names(), format)
+		}
+		assert.Len(t, Names(), len(formats))
+	})
+}
+
+func TestHookEnv(t *testing.T) {
+	conf, plugin := generateConfig(t)
+	version := toolversions.Version{Type: "version", Value: "1.0.0"}
+	assert.Nil(t, installtest.InstallOneVersion(conf, plugin, "version", version.Value))
+	assert.Nil(t, repotest.WritePluginCallback(plugin.Dir, "exec-env", "#!/usr/bin/env bash\nexport FOO=lua HOME=/lua"))
+	luaBin := filepath.Join(installs.InstallPath(conf, plugin, version), "bin")
+
+	projectDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(projectDir, ".tool-versions"), []byte("lua 1.0.0\n"), 0o666))
+	otherDir := t.TempDir()
+
+	initialEnv := map[string]string{"PATH": "/usr/bin", "HOME": "/home/test"}
+
+	t.Run("puts executable paths on PATH and applies exec-env variables", func(t *testing.T) {
+		changes, err := HookEnv(conf, projectDir, initialEnv, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, luaBin+":/usr/bin", changes.Set["PATH"])
+		assert.Equal(t, "lua", changes.Set["FOO"])
+		assert.Equal(t, "/lua", changes.Set["HOME"])
+		assert.NotEmpty(t, changes.Set[StateVariable])
+		assert.Empty(t, changes.Unset)
+	})
+
+	t.Run("returns no changes when nothing changed since the last call", func(t *testing.T) {
+		env := activatedEnv(t, conf, projectDir, initialEnv)
+
+		changes, err := HookEnv(conf, projectDir, env, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Empty(t, changes.Set)
+		assert.Empty(t, changes.Unset)
+	})
+
+	t.Run("reverts changes when leaving directory", func(t *testing.T) {
+		env := activatedEnv(t, conf, projectDir, initialEnv)
+
+		changes, err := HookEnv(conf, otherDir, env, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, "/usr/bin", changes.Set["PATH"])
+		assert.Equal(t, "/home/test", changes.Set["HOME"])
+		assert.Contains(t, changes.Unset, "FOO")
+	})
+
+	t.Run("keeps paths added to PATH by the user", func(t *testing.T) {
+		env := activatedEnv(t, conf, projectDir, initialEnv)
+		env["PATH"] = "/user/bin:" + env["PATH"]
+
+		changes, err := HookEnv(conf, otherDir, env, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, "/user/bin:/usr/bin", changes.Set["PATH"])
+	})
+
+	t.Run("recomputes environment when version file changes", func(t *testing.T) {
+		dir := t.TempDir()
+		versionFile := filepath.Join(dir, ".tool-versions")
+		assert.Nil(t, os.WriteFile(versionFile, []byte("lua 1.0.0\n"), 0o666))
+		env := activatedEnv(t, conf, dir, initialEnv)
+
+		assert.Nil(t, os.WriteFile(versionFile, []byte("lua system\n"), 0o666))
+
+		changes, err := HookEnv(conf, dir, env, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, "/usr/bin", changes.Set["PATH"])
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	conf, _ := generateConfig(t)
+	dir := t.TempDir()
+	versionFile := filepath.Join(dir, ".tool-versions")
+	assert.Nil(t, os.WriteFile(versionFile, []byte("lua path:/tmp/lua\n"), 0o666))
+
+	t.Run("changes when version file is trusted and untrusted", func(t *testing.T) {
+		untrusted := fingerprint(conf, dir, map[string]string{})
+
+		assert.Nil(t, trust.Add(conf, versionFile))
+		trusted := fingerprint(conf, dir, map[string]string{})
+		assert.NotEqual(t, untrusted, trusted)
+
+		assert.Nil(t, trust.Remove(conf, versionFile))
+		assert.NotEqual(t, trusted, fingerprint(conf, dir, map[string]string{}))
+	})
+}
+
+// activatedEnv returns env with the changes from HookEnv applied, as the shell
+// hook would.
+func activatedEnv(t *testing.T, conf config.Config, dir string, env map[string]string) map[string]string {
+	t.Helper()
+
+	changes, err := HookEnv(conf, dir, env, &strings.Builder{})
+	assert.Nil(t, err)
+
+	activated := map[string]string{}
+	for name, value := range env {
+		activated[name] = value
+	}
+	for name, value := range changes.Set {
+		activated[name] = value
+	}
+	for _, name := range changes.Unset {
+		delete(activated, name)
+	}
+	return activated
+}
+
+func generateConfig(t *testing.T) (config.Config, plugins.Plugin) {
+	t.Helper()
+	testDataDir := t.TempDir()
+	conf, err := config.LoadConfig()
+	assert.Nil(t, err)
+	conf.DataDir = testDataDir
+	conf.ConfigFile = "non-existent"
+	conf.DefaultToolVersionsFilename = ".tool-versions"
+
+	_, err = repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	return conf, plugins.New(conf, testPluginName)
+}
@@ -0,0 +1,15 @@
+# asdf activation for Bash. Add the following to ~/.bashrc:
+#
+#   eval "$(asdf activate bash)"
+
+_asdf_hook() {
+  local previous_exit_status=$?
+  eval "$(command asdf hook-env bash)"
+  return $previous_exit_status
+}
+
+if [[ ";${PROMPT_COMMAND:-};" != *";_asdf_hook;"* ]]; then
+  PROMPT_COMMAND="_asdf_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+
+_asdf_hook
@@ -0,0 +1,9 @@
+# asdf activation for Fish. Add the following to ~/.config/fish/config.fish:
+#
+#   asdf activate fish | source
+
+function _asdf_hook --on-event fish_prompt --on-variable PWD
+    command asdf hook-env fish | source
+end
+
+_asdf_hook
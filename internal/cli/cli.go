@@ -2,31 +2,47 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
 	"os"
+	osexec "os/exec"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/asdf-vm/asdf/internal/activate"
 	"github.com/asdf-vm/asdf/internal/completions"
 	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/envformat"
+	"github.com/asdf-vm/asdf/internal/ephemeral"
 	"github.com/asdf-vm/asdf/internal/exec"
 	"github.com/asdf-vm/asdf/internal/execenv"
 	"github.com/asdf-vm/asdf/internal/execute"
+	"github.com/asdf-vm/asdf/internal/fuzzy"
+	"github.com/asdf-vm/asdf/internal/git"
 	"github.com/asdf-vm/asdf/internal/help"
 	"github.com/asdf-vm/asdf/internal/hook"
 	"github.com/asdf-vm/asdf/internal/info"
 	"github.com/asdf-vm/asdf/internal/installs"
 	"github.com/asdf-vm/asdf/internal/pluginindex"
+	"github.com/asdf-vm/asdf/internal/plugininfo"
 	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/plugintest"
+	"github.com/asdf-vm/asdf/internal/pluginversions"
 	"github.com/asdf-vm/asdf/internal/resolve"
+	"github.com/asdf-vm/asdf/internal/scaffold"
 	"github.com/asdf-vm/asdf/internal/shims"
+	"github.com/asdf-vm/asdf/internal/toolenv"
 	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/trust"
 	"github.com/asdf-vm/asdf/internal/versions"
 	"github.com/urfave/cli/v2"
 )
@@ -64,6 +80,13 @@ func Execute(version string) {
 		Usage:     "The multiple runtime version manager",
 		UsageText: usageText,
 		Commands: []*cli.Command{
+			{
+				Name: "activate",
+				Action: func(cCtx *cli.Context) error {
+					shell := cCtx.Args().Get(0)
+					return activateCommand(logger, shell)
+				},
+			},
 			{
 				Name: "cmd",
 				Action: func(cCtx *cli.Context) error {
@@ -96,10 +119,24 @@ func Execute(version string) {
 			},
 			{
 				Name: "env",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Print the environment of a tool, or all tools set in the current directory, in the given format (sh, fish, pwsh, json, dotenv)",
+					},
+					&cli.BoolFlag{
+						Name:  "full",
+						Usage: "Print the full environment instead of only the variables that differ from the current environment",
+					},
+				},
 				Action: func(cCtx *cli.Context) error {
 					shimmedCommand := cCtx.Args().Get(0)
 					args := cCtx.Args().Slice()
 
+					if format := cCtx.String("format"); format != "" {
+						return envFormatCommand(logger, format, shimmedCommand, cCtx.Bool("full"))
+					}
+
 					return envCommand(logger, shimmedCommand, args)
 				},
 			},
@@ -120,6 +157,22 @@ func Execute(version string) {
 					return helpCommand(logger, version, toolName, toolVersion)
 				},
 			},
+			{
+				Name:   "export-shell-version",
+				Hidden: true,
+				Action: func(cCtx *cli.Context) error {
+					args := cCtx.Args()
+					return exportShellVersionCommand(logger, args.Get(0), args.Get(1), args.Get(2))
+				},
+			},
+			{
+				Name:   "hook-env",
+				Hidden: true,
+				Action: func(cCtx *cli.Context) error {
+					shell := cCtx.Args().Get(0)
+					return hookEnvCommand(logger, shell)
+				},
+			},
 			{
 				Name: "info",
 				Action: func(_ *cli.Context) error {
@@ -139,11 +192,16 @@ func Execute(version string) {
 						Name:  "keep-download",
 						Usage: "Whether or not to keep download directory after successful install",
 					},
+					&cli.BoolFlag{
+						Name:  "update-plugins",
+						Usage: "Update plugins to the refs pinned in .plugin-versions",
+					},
 				},
 				Action: func(cCtx *cli.Context) error {
 					args := cCtx.Args()
 					keepDownload := cCtx.Bool("keep-download")
-					return installCommand(logger, args.Get(0), args.Get(1), keepDownload)
+					updatePlugins := cCtx.Bool("update-plugins")
+					return installCommand(logger, args.Get(0), args.Get(1), keepDownload, updatePlugins)
 				},
 			},
 			{
@@ -173,12 +231,17 @@ func Execute(version string) {
 				Name: "plugin",
 				Action: func(_ *cli.Context) error {
 					logger.Println("Unknown command: `asdf plugin`")
-					os.Exit(1)
-					return nil
+					return exitError(ExitUnknownCommand, errors.New("unknown command"))
 				},
 				Subcommands: []*cli.Command{
 					{
 						Name: "add",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "ref",
+								Usage: "The branch, tag or commit of the plugin to check out",
+							},
+						},
 						Action: func(cCtx *cli.Context) error {
 							args := cCtx.Args()
 							conf, err := config.LoadConfig()
@@ -187,7 +250,69 @@ func Execute(version string) {
 								return err
 							}
 
-							return pluginAddCommand(cCtx, conf, logger, args.Get(0), args.Get(1))
+							return pluginAddCommand(cCtx, conf, logger, args.Get(0), args.Get(1), cCtx.String("ref"))
+						},
+					},
+					{
+						Name: "info",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "Print the plugin info as JSON",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							return pluginInfoCommand(logger, cCtx.Args().Get(0), cCtx.Bool("json"))
+						},
+					},
+					{
+						Name: "index",
+						Subcommands: []*cli.Command{
+							{
+								Name: "pin",
+								Flags: []cli.Flag{
+									&cli.StringFlag{
+										Name:  "index",
+										Usage: "The plugin index to pin",
+										Value: config.DefaultPluginIndexName,
+									},
+								},
+								Action: func(cCtx *cli.Context) error {
+									return pluginIndexPinCommand(logger, cCtx.String("index"), cCtx.Args().Get(0))
+								},
+							},
+							{
+								Name: "status",
+								Action: func(_ *cli.Context) error {
+									return pluginIndexStatusCommand(logger)
+								},
+							},
+							{
+								Name: "unpin",
+								Flags: []cli.Flag{
+									&cli.StringFlag{
+										Name:  "index",
+										Usage: "The plugin index to unpin",
+										Value: config.DefaultPluginIndexName,
+									},
+								},
+								Action: func(cCtx *cli.Context) error {
+									return pluginIndexUnpinCommand(logger, cCtx.String("index"))
+								},
+							},
+							{
+								Name: "update",
+								Action: func(cCtx *cli.Context) error {
+									return pluginIndexUpdateCommand(logger, cCtx.Args().Slice())
+								},
+							},
+						},
+					},
+					{
+						Name: "link",
+						Action: func(cCtx *cli.Context) error {
+							args := cCtx.Args()
+							return pluginLinkCommand(logger, args.Get(0), args.Get(1))
 						},
 					},
 					{
@@ -201,6 +326,14 @@ func Execute(version string) {
 								Name:  "refs",
 								Usage: "Show Refs",
 							},
+							&cli.BoolFlag{
+								Name:  "outdated",
+								Usage: "Fetch each plugin's remote and show the plugins that are behind it",
+							},
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "Show --outdated output as JSON",
+							},
 						},
 						Action: func(cCtx *cli.Context) error {
 							return pluginListCommand(cCtx, logger)
@@ -214,6 +347,47 @@ func Execute(version string) {
 							},
 						},
 					},
+					{
+						Name: "lock",
+						Action: func(cCtx *cli.Context) error {
+							return pluginLockCommand(logger, cCtx.Args().Slice())
+						},
+					},
+					{
+						Name: "log",
+						Action: func(cCtx *cli.Context) error {
+							args := cCtx.Args()
+							return pluginLogCommand(logger, args.Get(0), args.Get(1), args.Get(2))
+						},
+					},
+					{
+						Name: "new",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "strategy",
+								Usage: "How the plugin installs the tool: github-releases or source",
+								Value: scaffold.GitHubReleases,
+							},
+							&cli.StringFlag{
+								Name:  "repository",
+								Usage: "The <owner>/<name> of the GitHub repository the tool is released from",
+							},
+							&cli.StringFlag{
+								Name:  "author",
+								Usage: "Who the LICENSE is granted by",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							args := cCtx.Args()
+							options := scaffold.Options{
+								Name:       args.Get(0),
+								Strategy:   cCtx.String("strategy"),
+								Repository: cCtx.String("repository"),
+								Author:     cCtx.String("author"),
+							}
+							return pluginNewCommand(logger, options, args.Get(1))
+						},
+					},
 					{
 						Name: "remove",
 						Action: func(cCtx *cli.Context) error {
@@ -221,6 +395,18 @@ func Execute(version string) {
 							return pluginRemoveCommand(cCtx, logger, args.Get(0))
 						},
 					},
+					{
+						Name: "rollback",
+						Action: func(cCtx *cli.Context) error {
+							return pluginRollbackCommand(logger, cCtx.Args().Get(0))
+						},
+					},
+					{
+						Name: "search",
+						Action: func(cCtx *cli.Context) error {
+							return pluginSearchCommand(logger, cCtx.Args().Get(0))
+						},
+					},
 					{
 						Name: "update",
 						Flags: []cli.Flag{
@@ -228,30 +414,49 @@ func Execute(version string) {
 								Name:  "all",
 								Usage: "Update all installed plugins",
 							},
+							&cli.IntFlag{
+								Name:  "jobs",
+								Usage: "Number of plugins to update at once with --all",
+								Value: runtime.NumCPU(),
+							},
+							&cli.BoolFlag{
+								Name:  "show-log",
+								Usage: "Show the commits each plugin was updated by",
+							},
 						},
 						Action: func(cCtx *cli.Context) error {
 							args := cCtx.Args()
 							return pluginUpdateCommand(cCtx, logger, args.Get(0), args.Get(1))
 						},
 					},
+					{
+						Name: "verify",
+						Action: func(cCtx *cli.Context) error {
+							return pluginVerifyCommand(logger, cCtx.Args().Slice())
+						},
+					},
 					{
 						Name: "test",
 						Flags: []cli.Flag{
-							&cli.StringFlag{
+							&cli.StringSliceFlag{
 								Name:  "asdf-tool-version",
-								Usage: "The tool version to use during testing",
+								Usage: "The tool versions to install during testing, may be repeated",
 							},
 							&cli.StringFlag{
 								Name:  "asdf-plugin-gitref",
 								Usage: "The plugin Git ref to test",
 							},
+							&cli.StringFlag{
+								Name:  "junit",
+								Usage: "Write a JUnit XML report of the checks to this file",
+							},
 						},
 						Action: func(cCtx *cli.Context) error {
-							toolVersion := cCtx.String("asdf-tool-version")
-							gitRef := cCtx.String("asdf-plugin-gitref")
-							args := cCtx.Args().Slice()
-							pluginTestCommand(logger, args, toolVersion, gitRef)
-							return nil
+							options := plugintest.Options{
+								Versions: cCtx.StringSlice("asdf-tool-version"),
+								Ref:      cCtx.String("asdf-plugin-gitref"),
+							}
+							return pluginTestCommand(logger, cCtx.Args().Slice(), options, cCtx.String("junit"))
 						},
 					},
 				},
@@ -263,6 +468,13 @@ func Execute(version string) {
 					return reshimCommand(logger, args.Get(0), args.Get(1))
 				},
 			},
+			{
+				Name:            "shell",
+				SkipFlagParsing: true,
+				Action: func(_ *cli.Context) error {
+					return shellCommand(logger)
+				},
+			},
 			{
 				Name: "shimversions",
 				Action: func(cCtx *cli.Context) error {
@@ -270,6 +482,13 @@ func Execute(version string) {
 					return shimVersionsCommand(logger, args.Get(0))
 				},
 			},
+			{
+				Name: "trust",
+				Action: func(cCtx *cli.Context) error {
+					file := cCtx.Args().Get(0)
+					return trustCommand(logger, file, true)
+				},
+			},
 			{
 				Name: "uninstall",
 				Action: func(cCtx *cli.Context) error {
@@ -279,6 +498,13 @@ func Execute(version string) {
 					return uninstallCommand(logger, tool, version)
 				},
 			},
+			{
+				Name: "untrust",
+				Action: func(cCtx *cli.Context) error {
+					file := cCtx.Args().Get(0)
+					return trustCommand(logger, file, false)
+				},
+			},
 			{
 				Name: "update",
 				Action: func(_ *cli.Context) error {
@@ -303,18 +529,187 @@ func Execute(version string) {
 					return whichCommand(logger, tool)
 				},
 			},
+			{
+				Name: "x",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "rm",
+						Usage: "Uninstall tool versions installed for the command after it exits",
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					return xCommand(logger, cCtx.Args().Slice(), cCtx.Bool("rm"))
+				},
+			},
 		},
-		Action: func(_ *cli.Context) error {
+		Action: func(cCtx *cli.Context) error {
+			if cCtx.Args().Present() {
+				return unknownCommand(logger, version, cCtx.Args().Slice())
+			}
+
 			return helpCommand(logger, version, "", "")
 		},
+		// Errors are mapped to exit codes below, after all commands have returned
+		ExitErrHandler: func(_ *cli.Context, _ error) {},
 	}
 
 	err := app.Run(os.Args)
 	if err != nil {
-		os.Exit(1)
+		os.Exit(ExitCode(err))
 	}
 }
 
+// unknownCommand prints the help text to STDERR, as the legacy implementation
+// did, when asdf is given a command that doesn't exist.
+func unknownCommand(logger *log.Logger, asdfVersion string, args []string) error {
+	logger.Printf("Unknown command: `asdf %s`", strings.Join(args, " "))
+
+	conf, err := config.LoadConfig()
+	if err == nil {
+		allPlugins, _ := plugins.List(conf, false, false)
+		help.Write(asdfVersion, allPlugins, os.Stderr)
+	}
+
+	return exitError(ExitUnknownCommand, errors.New("unknown command"))
+}
+
+func activateCommand(logger *log.Logger, shell string) error {
+	file, ok := activate.Get(shell)
+	if !ok {
+		logger.Printf(`No activation available for shell with name %q
+Activation is available for: %v`, shell, strings.Join(activate.Names(), ", "))
+		return errors.New("bad shell name")
+	}
+	defer file.Close()
+
+	io.Copy(os.Stdout, file)
+
+	return nil
+}
+
+func hookEnvCommand(logger *log.Logger, shell string) error {
+	format, ok := activate.Format(shell)
+	if !ok {
+		logger.Printf(`No activation available for shell with name %q
+Activation is available for: %v`, shell, strings.Join(activate.Names(), ", "))
+		return errors.New("bad shell name")
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		logger.Printf("unable to get current directory: %s", err)
+		return err
+	}
+
+	changes, err := activate.HookEnv(conf, currentDir, execenv.CurrentEnv(), os.Stderr)
+	if err != nil {
+		logger.Printf("unable to generate environment: %s", err)
+		return err
+	}
+
+	return envformat.Write(os.Stdout, format, changes)
+}
+
+// exportShellVersionCommand prints code for the named shell that sets or, when
+// version is --unset, unsets the ASDF_${TOOL}_VERSION variable. The shell
+// integrations evaluate its output to implement `asdf shell`, so errors are
+// followed by a `false` that makes the evaluation fail.
+func exportShellVersionCommand(logger *log.Logger, shell, toolName, version string) error {
+	if toolName == "" || version == "" {
+		logger.Printf("Usage: asdf shell <name> {<version>|--unset}")
+		fmt.Println("false")
+		return errors.New("usage: asdf shell <name> {<version>|--unset}")
+	}
+
+	variable := resolve.VariableVersionName(toolName)
+	if version == "--unset" {
+		fmt.Print(unsetShellVersion(shell, variable))
+		return nil
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		fmt.Println("false")
+		return err
+	}
+
+	plugin, err := loadPlugin(logger, conf, toolName)
+	if err == nil {
+		parsedVersion := toolversions.ParseFromCliArg(version)
+		if parsedVersion.Type == "latest" {
+			version, err = versions.Latest(conf, plugin, parsedVersion.Value)
+			if err != nil {
+				logger.Printf("unable to resolve latest version of %s: %s", toolName, err)
+				fmt.Println("false")
+				return err
+			}
+			parsedVersion = toolversions.Parse(version)
+		}
+
+		if parsedVersion.Type == "system" || installs.IsInstalled(conf, plugin, parsedVersion) {
+			fmt.Print(setShellVersion(shell, variable, version))
+			return nil
+		}
+	}
+
+	logger.Printf("version %s is not installed for %s", version, toolName)
+	fmt.Println("false")
+	return errors.New("version not installed")
+}
+
+func setShellVersion(shell, variable, version string) string {
+	switch shell {
+	case "elvish":
+		// Elvish has no `source` command and eval is banned, so the variable name
+		// and value are printed on separate lines for asdf.elv to parse and pass
+		// to set-env.
+		return fmt.Sprintf("set-env\n%s\n%s", variable, version)
+	case "nushell":
+		return fmt.Sprintf("{%q: %q}\n", variable, version)
+	default:
+		return shellChanges(shell, envformat.Changes{Set: map[string]string{variable: version}})
+	}
+}
+
+func unsetShellVersion(shell, variable string) string {
+	switch shell {
+	case "elvish":
+		return fmt.Sprintf("unset-env\n%s", variable)
+	case "nushell":
+		return fmt.Sprintf("{%q: null}\n", variable)
+	default:
+		return shellChanges(shell, envformat.Changes{Unset: []string{variable}})
+	}
+}
+
+// shellChanges renders changes for fish, pwsh or, for any other shell, sh
+// with the quoting asdf env uses, as the shell integrations evaluate them
+func shellChanges(shell string, changes envformat.Changes) string {
+	format := "sh"
+	if shell == "fish" || shell == "pwsh" {
+		format = shell
+	}
+
+	var output strings.Builder
+	envformat.Write(&output, format, changes)
+	return output.String()
+}
+
+// shellCommand is only reached when asdf is run without the shell integration
+// that wraps it, as a child process cannot change the environment of its
+// parent shell.
+func shellCommand(logger *log.Logger) error {
+	logger.Printf("Shell integration is not enabled. Please ensure you source asdf in your shell setup.")
+	return errors.New("shell integration not loaded")
+}
+
 func completionCommand(l *log.Logger, shell string) error {
 	file, ok := completions.Get(shell)
 	if !ok {
@@ -380,14 +775,15 @@ func currentCommand(logger *log.Logger, tool string, noHeader bool) error {
 		formatCurrentVersionLine(w, plugin, toolversion, versionFound, versionInstalled, err)
 		w.Flush()
 		if !versionFound {
-			os.Exit(126)
+			return exitError(ExitNoVersion, fmt.Errorf("no version is set for %s", tool))
 		}
 
 		if !versionInstalled {
-			os.Exit(1)
+			return fmt.Errorf("version of %s is not installed", tool)
 		}
 	} else {
 		fmt.Printf("No such plugin: %s\n", tool)
+		logClosestPlugins(logger, conf, tool)
 		return err
 	}
 
@@ -491,7 +887,7 @@ func envCommand(logger *log.Logger, shimmedCommand string, args []string) error
 	}
 
 	if parsedVersion.Type != "system" {
-		env, err = execenv.Generate(plugin, env)
+		env, err = execenv.Generate(plugin, env, os.Stderr)
 		if _, ok := err.(plugins.NoCallbackError); !ok && err != nil {
 			return err
 		}
@@ -509,64 +905,233 @@ func envCommand(logger *log.Logger, shimmedCommand string, args []string) error
 	return err
 }
 
-func setPath(paths []string) string {
-	return strings.Join(paths, ":") + ":" + os.Getenv("PATH")
-}
-
-func execCommand(logger *log.Logger, command string, args []string) error {
-	if command == "" {
-		logger.Printf("usage: asdf exec <command>")
-		return fmt.Errorf("usage: asdf exec <command>")
-	}
-
+func envFormatCommand(logger *log.Logger, format, tool string, full bool) error {
 	conf, err := config.LoadConfig()
 	if err != nil {
 		logger.Printf("error loading config: %s", err)
 		return err
 	}
 
-	executable, plugin, version, err := getExecutable(logger, conf, command)
+	currentDir, err := os.Getwd()
 	if err != nil {
+		logger.Printf("unable to get current directory: %s", err)
 		return err
 	}
 
-	if len(args) > 1 {
-		args = args[1:]
+	var toolVersions []toolenv.ToolVersion
+	if tool == "" {
+		toolVersions, err = toolenv.Resolve(conf, currentDir)
+		if err != nil {
+			logger.Printf("unable to resolve tool versions: %s", err)
+			return err
+		}
 	} else {
-		args = []string{}
+		plugin, err := loadPlugin(logger, conf, tool)
+		if err != nil {
+			return err
+		}
+
+		toolVersion, found, err := toolenv.ResolveTool(conf, plugin, currentDir)
+		if err != nil {
+			logger.Printf("unable to resolve version of %s: %s", tool, err)
+			return err
+		}
+
+		if !found {
+			logger.Printf("No installed version of %s is set for the current directory", tool)
+			return errors.New("no version set")
+		}
+
+		toolVersions = append(toolVersions, toolVersion)
 	}
 
-	parsedVersion := toolversions.Parse(version)
-	execPaths, err := shims.ExecutablePaths(conf, plugin, parsedVersion)
+	currentEnv := execenv.CurrentEnv()
+	env, err := toolenv.Build(conf, toolVersions, currentEnv, os.Stderr)
 	if err != nil {
+		logger.Printf("unable to generate environment: %s", err)
 		return err
 	}
-	env := map[string]string{
-		"ASDF_INSTALL_TYPE":    parsedVersion.Type,
-		"ASDF_INSTALL_VERSION": parsedVersion.Value,
-		"ASDF_INSTALL_PATH":    installs.InstallPath(conf, plugin, parsedVersion),
-		"PATH":                 setPath(execPaths),
-	}
 
-	if parsedVersion.Type != "system" {
-		env, err = execenv.Generate(plugin, env)
-		if _, ok := err.(plugins.NoCallbackError); !ok && err != nil {
-			return err
-		}
+	changes := envformat.Changes{Set: env}
+	if !full {
+		changes.Set, changes.Unset = execenv.Diff(currentEnv, env)
 	}
 
-	env = execenv.MergeEnv(execenv.SliceToMap(os.Environ()), env)
-
-	err = hook.RunWithOutput(conf, fmt.Sprintf("pre_%s_%s", plugin.Name, filepath.Base(executable)), args, os.Stdout, os.Stderr)
+	err = envformat.Write(os.Stdout, format, changes)
 	if err != nil {
-		os.Exit(1)
-		return err
+		logger.Printf("%s", err)
 	}
+	return err
+}
 
-	return exec.Exec(executable, args, execute.MapToSlice(env))
+func setPath(paths []string) string {
+	return strings.Join(paths, ":") + ":" + os.Getenv("PATH")
 }
 
-func extensionCommand(logger *log.Logger, args []string) error {
+// otherToolPaths returns the executable paths of the tools other than plugin
+// that are set for the current directory when the exec_all_tools_on_path
+// setting is enabled for plugin, so commands run by the executable find them
+// directly instead of going through shims again.
+func otherToolPaths(conf config.Config, plugin plugins.Plugin) ([]string, error) {
+	enabled, err := conf.AllToolsOnPath(plugin.Name)
+	if err != nil || !enabled {
+		return []string{}, err
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return []string{}, err
+	}
+
+	toolVersions, err := toolenv.Resolve(conf, currentDir)
+	if err != nil {
+		return []string{}, err
+	}
+
+	toolVersions = slices.DeleteFunc(toolVersions, func(toolVersion toolenv.ToolVersion) bool {
+		return toolVersion.Plugin.Name == plugin.Name
+	})
+
+	return toolenv.ExecutablePaths(conf, toolVersions)
+}
+
+func execCommand(logger *log.Logger, command string, args []string) error {
+	if command == "" {
+		logger.Printf("usage: asdf exec <command>")
+		return fmt.Errorf("usage: asdf exec <command>")
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	executable, plugin, version, err := getExecutable(logger, conf, command)
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 1 {
+		args = args[1:]
+	} else {
+		args = []string{}
+	}
+
+	parsedVersion := toolversions.Parse(version)
+	execPaths, err := shims.ExecutablePaths(conf, plugin, parsedVersion)
+	if err != nil {
+		return err
+	}
+
+	otherPaths, err := otherToolPaths(conf, plugin)
+	if err != nil {
+		return err
+	}
+	execPaths = append(execPaths, otherPaths...)
+
+	// The callback is given the full environment so it can see and unset any
+	// variable
+	env := execenv.MergeEnv(execenv.SliceToMap(os.Environ()), map[string]string{
+		"ASDF_INSTALL_TYPE":    parsedVersion.Type,
+		"ASDF_INSTALL_VERSION": parsedVersion.Value,
+		"ASDF_INSTALL_PATH":    installs.InstallPath(conf, plugin, parsedVersion),
+		"PATH":                 setPath(execPaths),
+	})
+
+	if parsedVersion.Type != "system" {
+		env, err = execenv.Generate(plugin, env, os.Stderr)
+		if _, ok := err.(plugins.NoCallbackError); !ok && err != nil {
+			return err
+		}
+	}
+
+	err = hook.RunWithOutput(conf, fmt.Sprintf("pre_%s_%s", plugin.Name, filepath.Base(executable)), args, os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	return exec.Exec(executable, args, execute.MapToSlice(env))
+}
+
+// xCommand runs a command with the tool versions given as <name>@<version>
+// arguments before --, installing them first if needed. With rm set the
+// command is run as a child process so the versions installed for it can be
+// uninstalled once it exits.
+func xCommand(logger *log.Logger, args []string, rm bool) error {
+	separator := slices.Index(args, "--")
+	if separator < 1 || separator == len(args)-1 {
+		logger.Printf("usage: asdf x [--rm] <name>@<version>... -- <command> [args...]")
+		return errors.New("usage: asdf x [--rm] <name>@<version>... -- <command> [args...]")
+	}
+
+	var specs []ephemeral.Spec
+	for _, arg := range args[:separator] {
+		spec, err := ephemeral.ParseSpec(arg)
+		if err != nil {
+			logger.Printf("%s", err)
+			return err
+		}
+		specs = append(specs, spec)
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	// Install output goes to STDERR so it can't be mixed up with the output of
+	// the command
+	toolVersions, installed, err := ephemeral.Prepare(conf, specs, os.Stderr, os.Stderr)
+	if err == nil {
+		err = runEphemeral(conf, toolVersions, args[separator+1], args[separator+2:], rm)
+	}
+
+	if rm {
+		if cleanupErr := ephemeral.Cleanup(conf, installed, os.Stderr, os.Stderr); cleanupErr != nil {
+			logger.Printf("unable to uninstall tool versions: %s", cleanupErr)
+		}
+	}
+
+	// The command prints its own errors, its exit code is passed on by Execute
+	if _, ok := err.(*osexec.ExitError); !ok && err != nil {
+		logger.Printf("%s", err)
+	}
+	return err
+}
+
+func runEphemeral(conf config.Config, toolVersions []toolenv.ToolVersion, command string, args []string, child bool) error {
+	env, err := toolenv.Build(conf, toolVersions, execenv.CurrentEnv(), os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	executable, err := shims.ExecutableOnPath(env["PATH"], command)
+	if err != nil {
+		return err
+	}
+
+	if !child {
+		return exec.Exec(executable, args, execute.MapToSlice(env))
+	}
+
+	// Interrupts are delivered to the command as well, asdf keeps running so it
+	// can clean up once the command exits. Ignoring the signal instead would be
+	// inherited by the command, which then couldn't be interrupted.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	cmd := osexec.Command(executable, args...)
+	cmd.Env = execute.MapToSlice(env)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func extensionCommand(logger *log.Logger, args []string) error {
 	if len(args) < 1 {
 		err := errors.New("no plugin name specified")
 		logger.Printf("%s", err.Error())
@@ -620,11 +1185,21 @@ func getExecutable(logger *log.Logger, conf config.Config, command string) (exec
 	executable, plugin, version, found, err := shims.FindExecutable(conf, command, currentDir)
 	if err != nil {
 
+		if _, ok := err.(trust.UntrustedFileError); ok {
+			logger.Printf("%s", err)
+			return "", plugin, version, exitError(ExitNoVersion, err)
+		}
+
 		if _, ok := err.(shims.NoExecutableForPluginError); ok {
 			logger.Printf("No executable %s found for current version. Please select a different version or install %s manually for the current version", command, command)
-			os.Exit(1)
 			return "", plugin, version, err
 		}
+
+		if _, ok := err.(shims.UnknownCommandError); ok {
+			logger.Printf("unknown command: %s. Perhaps you have to reshim?", command)
+			return "", plugin, version, exitError(ExitUnknownCommand, err)
+		}
+
 		shimPath := shims.Path(conf, command)
 		toolVersions, _ := shims.GetToolsAndVersionsFromShimFile(shimPath)
 
@@ -650,14 +1225,12 @@ func getExecutable(logger *log.Logger, conf config.Config, command string) (exec
 			}
 		}
 
-		os.Exit(126)
-		return executable, plugins.Plugin{}, "", err
+		return executable, plugins.Plugin{}, "", exitError(ExitNoVersion, err)
 	}
 
 	if !found {
 		logger.Print("executable not found")
-		os.Exit(126)
-		return executable, plugins.Plugin{}, "", fmt.Errorf("executable not found")
+		return executable, plugins.Plugin{}, "", exitError(ExitNoVersion, errors.New("executable not found"))
 	}
 
 	return executable, plugin, version, nil
@@ -676,37 +1249,161 @@ func anyInstalled(conf config.Config, toolVersions []toolversions.ToolVersions)
 	return false
 }
 
-func pluginAddCommand(_ *cli.Context, conf config.Config, logger *log.Logger, pluginName, pluginRepo string) error {
+func pluginAddCommand(cCtx *cli.Context, conf config.Config, logger *log.Logger, pluginName, pluginRepo, ref string) error {
 	if pluginName == "" {
 		// Invalid arguments
 		// Maybe one day switch this to show the generated help
 		// cli.ShowSubcommandHelp(cCtx)
-		return cli.Exit("usage: asdf plugin add <name> [<git-url>]", 1)
+		logger.Print("usage: asdf plugin add [--ref <ref>] <name> [<git-url>|<path>]")
+		return errors.New("usage: asdf plugin add [--ref <ref>] <name> [<git-url>|<path>]")
 	}
 
-	err := plugins.Add(conf, pluginName, pluginRepo, "")
+	err := plugins.Add(conf, pluginName, pluginRepo, ref)
 	if err != nil {
 		logger.Printf("%s", err)
 
 		var existsErr plugins.PluginAlreadyExists
 		if errors.As(err, &existsErr) {
-			os.Exit(0)
 			return nil
 		}
 
-		os.Exit(1)
-		return nil
+		return err
+	}
+
+	warnManifestRequirements(conf, logger, plugins.New(conf, pluginName), cCtx.App.Version)
+	return nil
+}
+
+// warnManifestRequirements warns about requirements declared in the manifest
+// of a newly added plugin that are not met. The plugin is kept as the
+// requirements may be met later.
+func warnManifestRequirements(conf config.Config, logger *log.Logger, plugin plugins.Plugin, asdfVersion string) {
+	manifest, found, err := plugin.Manifest()
+	if err != nil {
+		logger.Printf("warning: %s", err)
+		return
+	}
+	if !found {
+		return
+	}
+
+	if !manifest.SupportsAsdfVersion(asdfVersion) {
+		logger.Printf("warning: plugin %s requires asdf %s or newer", plugin.Name, manifest.MinAsdfVersion)
+	}
+
+	if err := plugin.CheckPlatform(runtime.GOOS, runtime.GOARCH); err != nil {
+		logger.Printf("warning: %s", err)
+	}
+
+	for _, dependency := range manifest.Dependencies {
+		if exists, _ := plugins.PluginExists(conf.DataDir, dependency); !exists {
+			logger.Printf("warning: plugin %s depends on plugin %s which is not installed", plugin.Name, dependency)
+		}
+	}
+}
+
+func pluginLinkCommand(logger *log.Logger, pluginName, dir string) error {
+	if pluginName == "" || dir == "" {
+		logger.Print("usage: asdf plugin link <name> <dir>")
+		return errors.New("usage: asdf plugin link <name> <dir>")
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	err = plugins.Link(conf, pluginName, dir)
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
 	}
 
-	os.Exit(0)
+	return nil
+}
+
+func pluginLockCommand(logger *log.Logger, pluginNames []string) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("unable to fetch current directory: %w", err)
+	}
+
+	entries, skipped, err := plugins.LockVersions(conf, pluginNames)
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	for _, name := range skipped {
+		logger.Printf("skipping plugin %s, it was not added from a Git repository", name)
+	}
+
+	// The entries come from the installed plugins, so the file is trusted
+	// unless it already contained entries that weren't
+	path := filepath.Join(dir, pluginversions.Filename)
+	trusted := true
+	if existing, err := pluginversions.Read(path); err == nil {
+		entries = pluginversions.Merge(existing, entries)
+		trusted, err = trust.IsTrusted(conf, path)
+		if err != nil {
+			logger.Printf("%s", err)
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	err = pluginversions.Write(path, entries)
+	if err != nil {
+		logger.Printf("unable to write %s: %s", path, err)
+		return err
+	}
+
+	if trusted {
+		return trust.Add(conf, path)
+	}
+
+	return nil
+}
+
+func pluginInfoCommand(logger *log.Logger, pluginName string, jsonOutput bool) error {
+	if pluginName == "" {
+		logger.Print("usage: asdf plugin info <name> [--json]")
+		return errors.New("usage: asdf plugin info <name> [--json]")
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	info, err := plugininfo.Build(conf, plugins.New(conf, pluginName))
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	if jsonOutput {
+		return plugininfo.WriteJSON(os.Stdout, info)
+	}
+
+	plugininfo.Write(os.Stdout, info)
 	return nil
 }
 
 func pluginRemoveCommand(_ *cli.Context, logger *log.Logger, pluginName string) error {
 	if pluginName == "" {
 		logger.Print("No plugin given")
-		os.Exit(1)
-		return nil
+		return errors.New("no plugin given")
 	}
 
 	conf, err := config.LoadConfig()
@@ -726,7 +1423,6 @@ func pluginRemoveCommand(_ *cli.Context, logger *log.Logger, pluginName string)
 	err2 := shims.RemoveAll(conf)
 	if err2 != nil {
 		logger.Printf("%s", err2)
-		os.Exit(1)
 		return err2
 	}
 
@@ -741,66 +1437,324 @@ func pluginListCommand(cCtx *cli.Context, logger *log.Logger) error {
 	conf, err := config.LoadConfig()
 	if err != nil {
 		logger.Printf("error loading config: %s", err)
-		return err
+		return err
+	}
+
+	installedPlugins, err := plugins.List(conf, urls, refs)
+	if err != nil {
+		logger.Printf("error loading plugin list: %s", err)
+		return err
+	}
+
+	if cCtx.Bool("outdated") {
+		statuses := plugins.CheckRemotes(installedPlugins, func(dir string) git.Repoer { return git.NewRepo(dir) }, runtime.NumCPU())
+		if cCtx.Bool("json") {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(statuses)
+		}
+
+		writeRemoteStatuses(os.Stdout, statuses)
+		return nil
+	}
+
+	// TODO: Add some sort of presenter logic in another file so we
+	// don't clutter up this cmd code with conditional presentation
+	// logic
+	for _, plugin := range installedPlugins {
+		if urls && refs {
+			fmt.Printf("%s\t\t%s\t%s\n", plugin.Name, plugin.URL, plugin.Ref)
+		} else if refs {
+			fmt.Printf("%s\t\t%s\n", plugin.Name, plugin.Ref)
+		} else if urls {
+			fmt.Printf("%s\t\t%s\n", plugin.Name, plugin.URL)
+		} else {
+			fmt.Printf("%s\n", plugin.Name)
+		}
+	}
+
+	return nil
+}
+
+// writeRemoteStatuses prints a table of how each plugin compares with its
+// remote
+func writeRemoteStatuses(out io.Writer, statuses []plugins.RemoteStatus) {
+	w := tabwriter.NewWriter(out, 10, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PLUGIN\tBRANCH\tSTATUS")
+	for _, status := range statuses {
+		branch := status.Branch
+		if status.Detached {
+			branch = "(detached)"
+		}
+
+		var states []string
+		switch {
+		case status.Error != "":
+			branch = "-"
+			states = append(states, fmt.Sprintf("error: %s", status.Error))
+		case status.Ahead > 0 || status.Behind > 0:
+			states = append(states, fmt.Sprintf("%d behind, %d ahead", status.Behind, status.Ahead))
+		case !status.Detached:
+			states = append(states, "up to date")
+		}
+		if status.Dirty {
+			states = append(states, "modified")
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.Name, branch, strings.Join(states, ", "))
+	}
+	w.Flush()
+}
+
+// loadPluginIndexes returns the plugin indexes the asdfrc lists, or an error
+// when short-name plugin repositories are disabled
+func loadPluginIndexes(logger *log.Logger, conf config.Config) (pluginindex.Indexes, error) {
+	disableRepo, err := conf.DisablePluginShortNameRepository()
+	if err != nil {
+		logger.Printf("unable to check config")
+		return nil, err
+	}
+	if disableRepo {
+		logger.Printf("Short-name plugin repository is disabled")
+		return nil, errors.New("short-name plugin repository is disabled")
+	}
+
+	lastCheckDuration := 0
+	// We don't care about errors here as we can use the default value
+	checkDuration, _ := conf.PluginRepositoryLastCheckDuration()
+
+	if !checkDuration.Never {
+		lastCheckDuration = checkDuration.Every
+	}
+
+	sources, err := conf.PluginIndexes()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return nil, err
+	}
+
+	return pluginindex.BuildIndexes(conf.DataDir, sources, false, lastCheckDuration), nil
+}
+
+func pluginListAllCommand(logger *log.Logger) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	indexes, err := loadPluginIndexes(logger, conf)
+	if err != nil {
+		return err
+	}
+
+	availablePlugins, err := indexes.Get()
+	if err != nil {
+		logger.Printf("error loading plugin index: %s", err)
+		return err
+	}
+
+	installedPlugins, err := plugins.List(conf, true, false)
+	if err != nil {
+		logger.Printf("error loading plugin list: %s", err)
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 15, 0, 1, ' ', 0)
+	for _, availablePlugin := range availablePlugins {
+		url := availablePlugin.URL
+		if pluginInstalled(availablePlugin, installedPlugins) {
+			url = "*" + url
+		}
+
+		// Only say which index a plugin is from when there is a choice
+		if len(indexes) > 1 {
+			fmt.Fprintf(w, "%s\t\t%s\t%s\n", availablePlugin.Name, url, availablePlugin.Index)
+		} else {
+			fmt.Fprintf(w, "%s\t\t%s\n", availablePlugin.Name, url)
+		}
+	}
+	w.Flush()
+
+	return nil
+}
+
+func pluginIndexUpdateCommand(logger *log.Logger, indexNames []string) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	indexes, err := loadPluginIndexes(logger, conf)
+	if err != nil {
+		return err
+	}
+
+	toUpdate := indexes
+	if len(indexNames) > 0 {
+		toUpdate = pluginindex.Indexes{}
+		for _, name := range indexNames {
+			index, ok := indexes.Find(name)
+			if !ok {
+				logger.Printf("No plugin index named %s in plugin_indexes", name)
+				return fmt.Errorf("no plugin index named %s", name)
+			}
+			toUpdate = append(toUpdate, index)
+		}
+	}
+
+	failed := 0
+	for _, index := range toUpdate {
+		err := index.Update()
+		if err != nil {
+			failed++
+			logger.Printf("Failed to update plugin index %s: %s", index.Name(), err)
+			continue
+		}
+
+		logger.Printf("Updated plugin index %s", index.Name())
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to update %d of %d plugin indexes", failed, len(toUpdate))
+	}
+
+	return nil
+}
+
+func pluginIndexStatusCommand(logger *log.Logger) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	indexes, err := loadPluginIndexes(logger, conf)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tURL\tCOMMIT\tUPDATED")
+	for _, index := range indexes {
+		status, err := index.Status()
+		if err != nil {
+			logger.Printf("unable to read status of plugin index %s: %s", index.Name(), err)
+			return err
+		}
+
+		commit := shortSHA(status.Commit)
+		if status.Pinned != "" {
+			commit += " (pinned)"
+		}
+
+		updated := "never"
+		if !status.Updated.IsZero() {
+			updated = status.Updated.Format(time.DateTime)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Name, status.URL, commit, updated)
+	}
+
+	return w.Flush()
+}
+
+func pluginIndexPinCommand(logger *log.Logger, indexName, ref string) error {
+	if ref == "" {
+		logger.Print("usage: asdf plugin index pin [--index <name>] <commit>")
+		return errors.New("usage: asdf plugin index pin [--index <name>] <commit>")
+	}
+
+	index, err := gitPluginIndex(logger, indexName)
+	if err != nil {
+		return err
+	}
+
+	sha, err := index.Pin(ref)
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	logger.Printf("Pinned plugin index %s to %s", indexName, sha)
+	return nil
+}
+
+func pluginIndexUnpinCommand(logger *log.Logger, indexName string) error {
+	index, err := gitPluginIndex(logger, indexName)
+	if err != nil {
+		return err
+	}
+
+	err = index.Unpin()
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	logger.Printf("Unpinned plugin index %s", indexName)
+	return nil
+}
+
+// gitPluginIndex returns the Git plugin index named name, only Git indexes
+// can be pinned
+func gitPluginIndex(logger *log.Logger, name string) (pluginindex.PluginIndex, error) {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return pluginindex.PluginIndex{}, err
 	}
 
-	plugins, err := plugins.List(conf, urls, refs)
+	indexes, err := loadPluginIndexes(logger, conf)
 	if err != nil {
-		logger.Printf("error loading plugin list: %s", err)
-		return err
+		return pluginindex.PluginIndex{}, err
 	}
 
-	// TODO: Add some sort of presenter logic in another file so we
-	// don't clutter up this cmd code with conditional presentation
-	// logic
-	for _, plugin := range plugins {
-		if urls && refs {
-			fmt.Printf("%s\t\t%s\t%s\n", plugin.Name, plugin.URL, plugin.Ref)
-		} else if refs {
-			fmt.Printf("%s\t\t%s\n", plugin.Name, plugin.Ref)
-		} else if urls {
-			fmt.Printf("%s\t\t%s\n", plugin.Name, plugin.URL)
-		} else {
-			fmt.Printf("%s\n", plugin.Name)
-		}
+	index, ok := indexes.Find(name)
+	if !ok {
+		logger.Printf("No plugin index named %s in plugin_indexes", name)
+		return pluginindex.PluginIndex{}, fmt.Errorf("no plugin index named %s", name)
 	}
 
-	return nil
+	gitIndex, ok := index.(pluginindex.PluginIndex)
+	if !ok {
+		logger.Printf("Plugin index %s is a JSON index, only Git indexes can be pinned", name)
+		return pluginindex.PluginIndex{}, fmt.Errorf("plugin index %s is a JSON index", name)
+	}
+
+	return gitIndex, nil
 }
 
-func pluginListAllCommand(logger *log.Logger) error {
+func pluginSearchCommand(logger *log.Logger, term string) error {
+	if term == "" {
+		logger.Print("usage: asdf plugin search <term>")
+		return errors.New("usage: asdf plugin search <term>")
+	}
+
 	conf, err := config.LoadConfig()
 	if err != nil {
 		logger.Printf("error loading config: %s", err)
 		return err
 	}
 
-	disableRepo, err := conf.DisablePluginShortNameRepository()
+	indexes, err := loadPluginIndexes(logger, conf)
 	if err != nil {
-		logger.Printf("unable to check config")
 		return err
 	}
-	if disableRepo {
-		logger.Printf("Short-name plugin repository is disabled")
-		os.Exit(1)
-		return nil
-	}
-
-	lastCheckDuration := 0
-	// We don't care about errors here as we can use the default value
-	checkDuration, _ := conf.PluginRepositoryLastCheckDuration()
-
-	if !checkDuration.Never {
-		lastCheckDuration = checkDuration.Every
-	}
 
-	index := pluginindex.Build(conf.DataDir, conf.PluginIndexURL, false, lastCheckDuration)
-	availablePlugins, err := index.Get()
+	availablePlugins, err := indexes.Get()
 	if err != nil {
 		logger.Printf("error loading plugin index: %s", err)
 		return err
 	}
 
+	matches := pluginindex.Search(availablePlugins, term)
+	if len(matches) == 0 {
+		logger.Printf("No plugins match %s", term)
+		return fmt.Errorf("no plugins match %s", term)
+	}
+
 	installedPlugins, err := plugins.List(conf, true, false)
 	if err != nil {
 		logger.Printf("error loading plugin list: %s", err)
@@ -808,12 +1762,20 @@ func pluginListAllCommand(logger *log.Logger) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 15, 0, 1, ' ', 0)
-	for _, availablePlugin := range availablePlugins {
-		if pluginInstalled(availablePlugin, installedPlugins) {
-			fmt.Fprintf(w, "%s\t\t*%s\n", availablePlugin.Name, availablePlugin.URL)
-		} else {
-			fmt.Fprintf(w, "%s\t\t%s\n", availablePlugin.Name, availablePlugin.URL)
+	for _, match := range matches {
+		url := match.URL
+		if pluginInstalled(match, installedPlugins) {
+			url = "*" + url
+		}
+
+		columns := []string{match.Name, "", url}
+		if len(indexes) > 1 {
+			columns = append(columns, match.Index)
 		}
+		if match.Description != "" {
+			columns = append(columns, match.Description)
+		}
+		fmt.Fprintln(w, strings.Join(columns, "\t"))
 	}
 	w.Flush()
 
@@ -843,37 +1805,25 @@ func helpCommand(logger *log.Logger, asdfVersion, tool, version string) error {
 
 	if tool != "" {
 		if version != "" {
-			err := help.PrintToolVersion(conf, tool, version)
-			if err != nil {
-				os.Exit(1)
-			}
-			return err
+			return help.PrintToolVersion(conf, tool, version)
 		}
 
-		err := help.PrintTool(conf, tool)
-		if err != nil {
-			os.Exit(1)
-		}
-		return err
+		return help.PrintTool(conf, tool)
 	}
 
 	allPlugins, err := plugins.List(conf, false, false)
 	if err != nil {
-		os.Exit(1)
-	}
-
-	err = help.Print(asdfVersion, allPlugins)
-	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
-	return err
+	return help.Print(asdfVersion, allPlugins)
 }
 
 func pluginUpdateCommand(cCtx *cli.Context, logger *log.Logger, pluginName, ref string) error {
 	updateAll := cCtx.Bool("all")
 	if !updateAll && pluginName == "" {
-		return cli.Exit("usage: asdf plugin-update {<name> [git-ref] | --all}", 1)
+		logger.Print("usage: asdf plugin-update {<name> [git-ref] | --all}")
+		return errors.New("usage: asdf plugin-update {<name> [git-ref] | --all}")
 	}
 
 	conf, err := config.LoadConfig()
@@ -882,128 +1832,319 @@ func pluginUpdateCommand(cCtx *cli.Context, logger *log.Logger, pluginName, ref
 		return err
 	}
 
+	showLog := cCtx.Bool("show-log")
+
 	if updateAll {
-		installedPlugins, err := plugins.List(conf, false, false)
+		results, err := plugins.UpdateAll(conf, cCtx.Int("jobs"))
 		if err != nil {
 			logger.Printf("failed to get plugin list: %s", err)
 			return err
 		}
 
-		for _, plugin := range installedPlugins {
-			updatedToRef, err := plugin.Update(conf, "", os.Stdout, os.Stderr)
-			formatUpdateResult(logger, plugin.Name, updatedToRef, err)
+		err = writeUpdateResults(os.Stdout, results)
+		if showLog {
+			for _, result := range results {
+				if result.Err == nil && result.OldSHA != result.NewSHA {
+					fmt.Fprintf(os.Stdout, "\n==> %s\n", result.Name)
+					writeUpdateLog(conf, logger, plugins.New(conf, result.Name), result.OldSHA, result.NewSHA)
+				}
+			}
 		}
 
-		return nil
+		return err
 	}
 
 	plugin := plugins.New(conf, pluginName)
-	updatedToRef, err := plugin.Update(conf, ref, os.Stdout, os.Stderr)
-	formatUpdateResult(logger, pluginName, updatedToRef, err)
+	result, err := plugin.Update(conf, ref, os.Stdout, os.Stderr)
+	formatUpdateResult(logger, pluginName, result.Ref, err)
+	if err == nil && showLog && result.OldSHA != result.NewSHA {
+		writeUpdateLog(conf, logger, plugin, result.OldSHA, result.NewSHA)
+	}
 	return err
 }
 
-func pluginTestCommand(l *log.Logger, args []string, toolVersion, ref string) {
+// writeUpdateLog prints the commits between from and to
+func writeUpdateLog(conf config.Config, logger *log.Logger, plugin plugins.Plugin, from, to string) {
+	commits, err := plugin.Log(conf, from, to)
+	if err != nil {
+		logger.Printf("unable to show log of %s: %s", plugin.Name, err)
+		return
+	}
+
+	writeCommits(os.Stdout, commits)
+}
+
+func writeCommits(out io.Writer, commits []git.Commit) {
+	for _, commit := range commits {
+		fmt.Fprintf(out, "%s %s (%s)\n", shortSHA(commit.SHA), commit.Subject, commit.Author)
+	}
+}
+
+func pluginLogCommand(logger *log.Logger, pluginName, from, to string) error {
+	if pluginName == "" {
+		logger.Print("usage: asdf plugin log <name> [<from-ref>] [<to-ref>]")
+		return errors.New("usage: asdf plugin log <name> [<from-ref>] [<to-ref>]")
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	commits, err := plugins.New(conf, pluginName).Log(conf, from, to)
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	writeCommits(os.Stdout, commits)
+	return nil
+}
+
+func pluginTestCommand(l *log.Logger, args []string, options plugintest.Options, junitPath string) error {
 	conf, err := config.LoadConfig()
 	if err != nil {
 		l.Printf("error loading config: %s", err)
-		os.Exit(1)
-		return
+		return err
 	}
 
 	if len(args) < 2 {
-		failTest(l, "please provide a plugin name and url")
+		return failTest(l, "please provide a plugin name and url")
 	}
 
-	name := args[0]
-	url := args[1]
-	testName := fmt.Sprintf("asdf-test-%s", name)
+	options.Name = args[0]
+	options.URL = args[1]
 
-	// Install plugin
-	err = plugins.Add(conf, testName, url, ref)
-	if err != nil {
-		failTest(l, fmt.Sprintf("%s was not properly installed", name))
+	// Options may also follow the name and URL, as with the Bash version of
+	// asdf. Everything after them, or after --, is the command to run.
+	rest := args[2:]
+	for len(rest) > 0 {
+		if rest[0] == "--" {
+			rest = rest[1:]
+			break
+		}
+
+		if len(rest) < 2 || !slices.Contains([]string{"--asdf-tool-version", "--asdf-plugin-gitref", "--junit"}, rest[0]) {
+			break
+		}
+
+		switch rest[0] {
+		case "--asdf-tool-version":
+			options.Versions = append(options.Versions, rest[1])
+		case "--asdf-plugin-gitref":
+			options.Ref = rest[1]
+		case "--junit":
+			junitPath = rest[1]
+		}
+		rest = rest[2:]
 	}
+	options.Command = rest
 
-	// Remove plugin
-	var blackhole strings.Builder
-	defer plugins.Remove(conf, testName, &blackhole, &blackhole)
+	report := plugintest.Run(conf, options, os.Stdout, os.Stderr)
+	writeTestReport(os.Stdout, report)
+
+	if junitPath != "" {
+		file, err := os.Create(junitPath)
+		if err != nil {
+			l.Printf("unable to write JUnit report: %s", err)
+			return err
+		}
+		defer file.Close()
 
-	// Assert callbacks are present
-	plugin := plugins.New(conf, testName)
-	files, err := os.ReadDir(filepath.Join(plugin.Dir, "bin"))
-	if _, ok := err.(*fs.PathError); ok {
-		failTest(l, "bin/ directory does not exist")
+		err = report.WriteJUnit(file)
+		if err != nil {
+			l.Printf("unable to write JUnit report: %s", err)
+			return err
+		}
 	}
 
-	callbacks := []string{}
-	for _, file := range files {
-		callbacks = append(callbacks, file.Name())
+	if failed := report.Count(plugintest.Failed); failed > 0 {
+		return failTest(l, fmt.Sprintf("%d of %d checks failed", failed, len(report.Results)))
 	}
 
-	for _, expectedCallback := range []string{"download", "install", "list-all"} {
-		if !slices.Contains(callbacks, expectedCallback) {
-			failTest(l, fmt.Sprintf("missing callback %s", expectedCallback))
+	return nil
+}
+
+// writeTestReport prints a table of the result of each check run by asdf
+// plugin test, followed by the number of checks that passed, failed and were
+// skipped
+func writeTestReport(out io.Writer, report plugintest.Report) {
+	writer := tabwriter.NewWriter(out, 10, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "CHECK\tRESULT\tTIME")
+	for _, result := range report.Results {
+		status := string(result.Status)
+		if result.Message != "" {
+			status = fmt.Sprintf("%s: %s", status, result.Message)
 		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", result.Name, status, result.Duration.Round(time.Millisecond))
 	}
+	writer.Flush()
+
+	fmt.Fprintf(out, "%d passed, %d failed, %d skipped\n", report.Count(plugintest.Passed), report.Count(plugintest.Failed), report.Count(plugintest.Skipped))
+}
 
-	allCallbacks := []string{"download", "install", "list-all", "latest-stable", "help.overview", "help.deps", "help.config", "help.links", "list-bin-paths", "exec-env", "exec-path", "uninstall", "list-legacy-filenames", "parse-legacy-file", "post-plugin-add", "post-plugin-update", "pre-plugin-remove"}
+func failTest(logger *log.Logger, msg string) error {
+	logger.Printf("FAILED: %s", msg)
+	return errors.New(msg)
+}
 
-	// Assert all callbacks present are executable
-	for _, file := range files {
-		// file is a callback...
-		if slices.Contains(allCallbacks, file.Name()) {
-			// check if it is executable
-			info, _ := file.Info()
-			if !(info.Mode()&0o111 != 0) {
-				failTest(l, fmt.Sprintf("callback lacks executable permission: %s", file.Name()))
-			}
+// writeUpdateResults prints the output of each update followed by a table of
+// the commits each plugin was updated from and to, and returns an error when
+// any update failed. Plugins that can't be updated are reported as skipped.
+func writeUpdateResults(out io.Writer, results []plugins.UpdateResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Output != "" {
+			fmt.Fprintf(out, "==> %s\n%s", result.Name, result.Output)
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 10, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PLUGIN\tOLD REF\tNEW REF\tRESULT")
+	for _, result := range results {
+		status := "updated"
+		_, notUpdatable := result.Err.(plugins.NotUpdatableError)
+		switch {
+		case notUpdatable:
+			status = fmt.Sprintf("skipped: %s", result.Err)
+		case result.Err != nil:
+			failed++
+			status = fmt.Sprintf("failed: %s", result.Err)
+		case result.OldSHA == result.NewSHA:
+			status = "up to date"
 		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name, shortSHA(result.OldSHA), shortSHA(result.NewSHA), status)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("failed to update %d of %d plugins", failed, len(results))
+	}
+
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "-"
 	}
 
-	// Assert has license
-	licensePath := filepath.Join(plugin.Dir, "LICENSE")
-	if _, err := os.Stat(licensePath); errors.Is(err, os.ErrNotExist) {
-		failTest(l, "LICENSE file must be present in the plugin repository")
+	return sha[:min(len(sha), 7)]
+}
+
+func pluginNewCommand(logger *log.Logger, options scaffold.Options, dir string) error {
+	if options.Name == "" || options.Repository == "" {
+		logger.Print("usage: asdf plugin new [--strategy <strategy>] --repository <owner>/<name> <name> [<dir>]")
+		return errors.New("usage: asdf plugin new [--strategy <strategy>] --repository <owner>/<name> <name> [<dir>]")
 	}
 
-	bytes, err := os.ReadFile(licensePath)
+	err := plugins.ValidateName(options.Name)
 	if err != nil {
-		failTest(l, "LICENSE file must be present in the plugin repository")
+		logger.Printf("%s", err)
+		return err
 	}
 
-	// Validate license file not empty
-	if len(bytes) == 0 {
-		failTest(l, "LICENSE file in the plugin repository must not be empty")
+	if dir == "" {
+		dir = "asdf-" + options.Name
+	}
+	if options.Author == "" {
+		options.Author = fmt.Sprintf("asdf-%s authors", options.Name)
 	}
+	options.Year = time.Now().Year()
 
-	// Validate it returns at least one available version
-	var output strings.Builder
-	err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &output, &blackhole)
+	files, err := scaffold.Write(dir, options)
 	if err != nil {
-		failTest(l, "Unable to list available versions")
+		logger.Printf("unable to create plugin: %s", err)
+		return err
 	}
 
-	allVersions := strings.Fields(output.String())
-	if len(allVersions) < 1 {
-		failTest(l, "list-all did not return any version")
+	for _, file := range files {
+		fmt.Println(filepath.Join(dir, file))
+	}
+	logger.Printf("Created plugin %s in %s, test it with %s", options.Name, dir, filepath.Join(dir, "scripts", "test.bash"))
+
+	return nil
+}
+
+func pluginRollbackCommand(logger *log.Logger, pluginName string) error {
+	if pluginName == "" {
+		logger.Print("usage: asdf plugin rollback <name>")
+		return errors.New("usage: asdf plugin rollback <name>")
 	}
 
-	// grab first version returned by list-all callback if no version provided as
-	// a CLI argument
-	if toolVersion == "" {
-		toolVersion = allVersions[0]
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
 	}
 
-	err = versions.InstallOneVersion(conf, plugin, toolVersion, false, os.Stdout, os.Stderr)
+	ref, err := plugins.New(conf, pluginName).Rollback(conf, os.Stdout, os.Stderr)
 	if err != nil {
-		failTest(l, "install exited with an error")
+		logger.Printf("failed to roll back %s due to error: %s\n", pluginName, err)
+		return err
 	}
+
+	logger.Printf("rolled back %s to ref %s\n", pluginName, ref)
+	return nil
 }
 
-func failTest(logger *log.Logger, msg string) {
-	logger.Printf("FAILED: %s", msg)
-	os.Exit(1)
+func pluginVerifyCommand(logger *log.Logger, pluginNames []string) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	mode, err := conf.PluginVerification()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+	if mode == "" {
+		logger.Print("plugin_verification is not set, set it to pinned or signed in your asdfrc to verify plugins")
+		return errors.New("plugin_verification is not set")
+	}
+
+	toVerify := []plugins.Plugin{}
+	if len(pluginNames) == 0 {
+		toVerify, err = plugins.List(conf, false, false)
+		if err != nil {
+			logger.Printf("error loading plugin list: %s", err)
+			return err
+		}
+	}
+	for _, name := range pluginNames {
+		toVerify = append(toVerify, plugins.New(conf, name))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PLUGIN\tCOMMIT\tRESULT")
+	failed := 0
+	for _, plugin := range toVerify {
+		verification, err := plugin.Verify(conf)
+
+		var result string
+		switch {
+		case err != nil:
+			failed++
+			result = fmt.Sprintf("failed: %s", err)
+		case verification.Signer != "":
+			result = fmt.Sprintf("signed by %s", verification.Signer)
+		default:
+			result = fmt.Sprintf("pinned in %s", verification.Lockfile)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", plugin.Name, shortSHA(verification.SHA), result)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("failed to verify %d of %d plugins", failed, len(toVerify))
+	}
+
+	return nil
 }
 
 func formatUpdateResult(logger *log.Logger, pluginName, updatedToRef string, err error) {
@@ -1016,7 +2157,7 @@ func formatUpdateResult(logger *log.Logger, pluginName, updatedToRef string, err
 	logger.Printf("updated %s to ref %s\n", pluginName, updatedToRef)
 }
 
-func installCommand(logger *log.Logger, toolName, version string, keepDownload bool) error {
+func installCommand(logger *log.Logger, toolName, version string, keepDownload, updatePlugins bool) error {
 	conf, err := config.LoadConfig()
 	if err != nil {
 		logger.Printf("error loading config: %s", err)
@@ -1028,6 +2169,11 @@ func installCommand(logger *log.Logger, toolName, version string, keepDownload b
 		return fmt.Errorf("unable to fetch current directory: %w", err)
 	}
 
+	err = syncPluginVersions(conf, logger, dir, toolName, updatePlugins)
+	if err != nil {
+		return err
+	}
+
 	if toolName == "" {
 		// Install all versions
 		errs := versions.InstallAll(conf, dir, os.Stdout, os.Stderr)
@@ -1053,13 +2199,11 @@ func installCommand(logger *log.Logger, toolName, version string, keepDownload b
 
 		if version == "" {
 			err = versions.Install(conf, plugin, dir, os.Stdout, os.Stderr)
-			if err != nil {
-				if _, ok := err.(versions.NoVersionSetError); ok {
-					logger.Printf("No versions specified for %s in config files or environment", toolName)
-					os.Exit(1)
-				}
-
-				return err
+			if _, ok := err.(versions.NoVersionSetError); ok {
+				logger.Printf("No versions specified for %s in config files or environment", toolName)
+			}
+			if _, ok := err.(plugins.PluginMissing); ok {
+				logger.Printf("No such plugin: %s", toolName)
 			}
 		} else {
 			parsedVersion := toolversions.ParseFromCliArg(version)
@@ -1077,6 +2221,70 @@ func installCommand(logger *log.Logger, toolName, version string, keepDownload b
 				logger.Printf("error installing version: %s", err)
 			}
 		}
+
+		if _, ok := err.(plugins.PluginMissing); ok {
+			logClosestPlugins(logger, conf, toolName)
+		}
+	}
+
+	return err
+}
+
+// maxPluginSuggestions is how many plugin names are suggested for a plugin
+// that isn't installed
+const maxPluginSuggestions = 3
+
+// logClosestPlugins suggests the installed plugins named closest to tool, for
+// when no plugin named tool is installed
+func logClosestPlugins(logger *log.Logger, conf config.Config, tool string) {
+	installedPlugins, err := plugins.List(conf, false, false)
+	if err != nil {
+		return
+	}
+
+	names := []string{}
+	for _, plugin := range installedPlugins {
+		names = append(names, plugin.Name)
+	}
+
+	if suggestion := fuzzy.DidYouMean(fuzzy.Closest(tool, names, maxPluginSuggestions)); suggestion != "" {
+		logger.Print(suggestion)
+	}
+}
+
+// syncPluginVersions adds the plugins pinned in the closest .plugin-versions
+// file that are missing, and updates or warns about plugins at another ref.
+// A file that isn't trusted is skipped with a warning when plugins would have
+// to be added or updated, so the tools of installed plugins still install.
+func syncPluginVersions(conf config.Config, logger *log.Logger, dir, toolName string, update bool) error {
+	path, found := pluginversions.Find(dir)
+	if !found {
+		return nil
+	}
+
+	var names []string
+	if toolName != "" {
+		names = []string{toolName}
+	}
+
+	results, err := plugins.SyncVersions(conf, path, names, update, os.Stdout, os.Stderr)
+	if _, ok := err.(trust.UntrustedFileError); ok {
+		// Tools can still be installed with the plugins that are already there
+		logger.Printf("warning: not syncing plugins pinned in %s, it is not trusted. Review the file and run `asdf trust %s` to allow it", path, path)
+		return nil
+	}
+	for _, result := range results {
+		switch result.Action {
+		case pluginversions.Added:
+			logger.Printf("added plugin %s from %s", result.Name, result.URL)
+		case pluginversions.Updated:
+			logger.Printf("updated plugin %s to %s", result.Name, result.Ref)
+		case pluginversions.Outdated:
+			logger.Printf("plugin %s is at %s but %s pins %s, run asdf install --update-plugins to update it", result.Name, result.InstalledRef, path, result.Ref)
+		}
+	}
+	if err != nil {
+		logger.Printf("%s", err)
 	}
 
 	return err
@@ -1100,12 +2308,7 @@ func latestCommand(logger *log.Logger, all bool, toolName, pattern string) (err
 	}
 
 	if !all {
-		err = latestForPlugin(conf, toolName, pattern, false)
-		if err != nil {
-			os.Exit(1)
-		}
-
-		return err
+		return latestForPlugin(conf, toolName, pattern, false)
 	}
 
 	plugins, err := plugins.List(conf, false, false)
@@ -1114,20 +2317,15 @@ func latestCommand(logger *log.Logger, all bool, toolName, pattern string) (err
 		return err
 	}
 
-	var maybeErr error
 	// loop over all plugins and show latest for each one.
 	for _, plugin := range plugins {
-		maybeErr = latestForPlugin(conf, plugin.Name, "", true)
+		maybeErr := latestForPlugin(conf, plugin.Name, "", true)
 		if maybeErr != nil {
 			err = maybeErr
 		}
 	}
 
-	if err != nil {
-		os.Exit(1)
-		return maybeErr
-	}
-	return nil
+	return err
 }
 
 func listCommand(logger *log.Logger, first, second, third string) (err error) {
@@ -1149,27 +2347,23 @@ func listCommand(logger *log.Logger, first, second, third string) (err error) {
 func listAllCommand(logger *log.Logger, conf config.Config, toolName, filter string) error {
 	if toolName == "" {
 		logger.Print("No plugin given")
-		os.Exit(1)
-		return nil
+		return errors.New("no plugin given")
 	}
 
 	plugin, err := loadPlugin(logger, conf, toolName)
 	if err != nil {
-		os.Exit(1)
 		return err
 	}
 
 	var stdout strings.Builder
 	var stderr strings.Builder
 
-	err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stderr)
+	err = plugin.RunCallback(conf, "list-all", []string{}, map[string]string{}, &stdout, &stderr)
 	if err != nil {
 		fmt.Printf("Plugin %s's list-all callback script failed with output:\n", plugin.Name)
 		// Print to stderr
 		os.Stderr.WriteString(stderr.String())
 		os.Stderr.WriteString(stdout.String())
-
-		os.Exit(1)
 		return err
 	}
 
@@ -1181,8 +2375,7 @@ func listAllCommand(logger *log.Logger, conf config.Config, toolName, filter str
 
 	if len(versions) == 0 {
 		logger.Printf("No compatible versions available (%s %s)", plugin.Name, filter)
-		os.Exit(1)
-		return nil
+		return errors.New("no compatible versions available")
 	}
 
 	for _, version := range versions {
@@ -1212,7 +2405,6 @@ func listLocalCommand(logger *log.Logger, conf config.Config, pluginName, filter
 	if pluginName != "" {
 		plugin, err := loadPlugin(logger, conf, pluginName)
 		if err != nil {
-			os.Exit(1)
 			return err
 		}
 		versions, _ := installs.Installed(conf, plugin)
@@ -1223,13 +2415,12 @@ func listLocalCommand(logger *log.Logger, conf config.Config, pluginName, filter
 
 		if len(versions) == 0 {
 			logger.Printf("No compatible versions installed (%s %s)", plugin.Name, filter)
-			os.Exit(1)
-			return nil
+			return errors.New("no compatible versions installed")
 		}
 
 		currentVersions, _, err := resolve.Version(conf, plugin, currentDir)
 		if err != nil {
-			os.Exit(1)
+			logger.Printf("unable to resolve version of %s: %s", plugin.Name, err)
 			return err
 		}
 
@@ -1256,7 +2447,7 @@ func listLocalCommand(logger *log.Logger, conf config.Config, pluginName, filter
 		if len(versions) > 0 {
 			currentVersions, _, err := resolve.Version(conf, plugin, currentDir)
 			if err != nil {
-				os.Exit(1)
+				logger.Printf("unable to resolve version of %s: %s", plugin.Name, err)
 				return err
 			}
 			for _, version := range versions {
@@ -1349,6 +2540,11 @@ func whichCommand(logger *log.Logger, command string) error {
 		return errors.New("no executable for tool version")
 	}
 
+	if _, ok := err.(trust.UntrustedFileError); ok {
+		logger.Printf("%s", err.Error())
+		return errors.New("untrusted version file")
+	}
+
 	if err != nil {
 		fmt.Printf("unexpected error: %s\n", err.Error())
 		return err
@@ -1358,17 +2554,53 @@ func whichCommand(logger *log.Logger, command string) error {
 	return nil
 }
 
+func trustCommand(logger *log.Logger, file string, trusted bool) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	if file == "" {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			logger.Printf("unable to get current directory: %s", err)
+			return err
+		}
+
+		file = filepath.Join(currentDir, conf.DefaultToolVersionsFilename)
+	}
+
+	if !trusted {
+		err = trust.Remove(conf, file)
+		if err != nil {
+			logger.Printf("unable to untrust %s: %s", file, err)
+			return err
+		}
+
+		fmt.Printf("Untrusted %s\n", file)
+		return nil
+	}
+
+	err = trust.Add(conf, file)
+	if err != nil {
+		logger.Printf("unable to trust %s: %s", file, err)
+		return err
+	}
+
+	fmt.Printf("Trusted %s\n", file)
+	return nil
+}
+
 func uninstallCommand(logger *log.Logger, tool, version string) error {
 	if tool == "" || version == "" {
 		logger.Print("No plugin given")
-		os.Exit(1)
-		return nil
+		return errors.New("no plugin given")
 	}
 
 	conf, err := config.LoadConfig()
 	if err != nil {
 		logger.Printf("error loading config: %s", err)
-		os.Exit(1)
 		return err
 	}
 
@@ -1376,7 +2608,6 @@ func uninstallCommand(logger *log.Logger, tool, version string) error {
 	err = versions.Uninstall(conf, plugin, version, os.Stdout, os.Stderr)
 	if err != nil {
 		logger.Printf("%s", err)
-		os.Exit(1)
 		return err
 	}
 
@@ -1385,7 +2616,6 @@ func uninstallCommand(logger *log.Logger, tool, version string) error {
 	err = shims.RemoveAll(conf)
 	if err != nil {
 		logger.Printf("%s", err)
-		os.Exit(1)
 		return err
 	}
 
@@ -1474,7 +2704,7 @@ func reshimToolVersion(conf config.Config, tool, versionStr string, out io.Write
 func latestForPlugin(conf config.Config, toolName, pattern string, showStatus bool) error {
 	// show single plugin
 	plugin := plugins.New(conf, toolName)
-	latest, err := versions.Latest(plugin, pattern)
+	latest, err := versions.Latest(conf, plugin, pattern)
 	if err != nil && err.Error() != "no latest version found" {
 		fmt.Printf("unable to load latest version: %s\n", err)
 		return err
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"log"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/pluginversions"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/asdf-vm/asdf/internal/trust"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunEphemeral(t *testing.T) {
+	t.Run("command run as child can be interrupted", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+
+		err := runEphemeral(conf, nil, "sh", []string{"-c", "kill -INT $$; sleep 1"}, true)
+		exitErr, ok := err.(*osexec.ExitError)
+		if assert.True(t, ok, "command should be killed by SIGINT, got %v", err) {
+			status := exitErr.Sys().(syscall.WaitStatus)
+			assert.True(t, status.Signaled())
+			assert.Equal(t, os.Interrupt, status.Signal())
+		}
+	})
+}
+
+func TestSyncPluginVersions(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir(), ConfigFile: "non-existent"}
+	repoPath, err := repotest.GeneratePlugin("dummy_plugin", t.TempDir(), "lua")
+	assert.Nil(t, err)
+
+	t.Run("warns and continues when file is not trusted", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, pluginversions.Filename)
+		assert.Nil(t, os.WriteFile(path, []byte("lua "+repoPath+"\n"), 0o666))
+		var output strings.Builder
+
+		err := syncPluginVersions(conf, log.New(&output, "", 0), dir, "", false)
+		assert.Nil(t, err)
+		assert.Contains(t, output.String(), "not syncing plugins pinned in "+path)
+		assert.Contains(t, output.String(), "asdf trust "+path)
+		assert.NoDirExists(t, filepath.Join(conf.DataDir, "plugins", "lua"))
+	})
+
+	t.Run("ignores file when it doesn't pin the tool", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, pluginversions.Filename)
+		assert.Nil(t, os.WriteFile(path, []byte("lua "+repoPath+"\n"), 0o666))
+		var output strings.Builder
+
+		err := syncPluginVersions(conf, log.New(&output, "", 0), dir, "ruby", false)
+		assert.Nil(t, err)
+		assert.Empty(t, output.String())
+	})
+
+	t.Run("adds plugins when file is trusted", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, pluginversions.Filename)
+		assert.Nil(t, os.WriteFile(path, []byte("lua "+repoPath+"\n"), 0o666))
+		assert.Nil(t, trust.Add(conf, path))
+		var output strings.Builder
+
+		err := syncPluginVersions(conf, log.New(&output, "", 0), dir, "", false)
+		assert.Nil(t, err)
+		assert.Contains(t, output.String(), "added plugin lua from "+repoPath)
+		assert.DirExists(t, filepath.Join(conf.DataDir, "plugins", "lua"))
+	})
+}
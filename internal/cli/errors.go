@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	osexec "os/exec"
+
+	"github.com/asdf-vm/asdf/internal/execute"
+)
+
+// Exit codes returned by asdf. They are documented in docs/manage/commands.md
+// and must not change once released as scripts depend on them.
+const (
+	// ExitSuccess is returned when the command succeeded
+	ExitSuccess = 0
+	// ExitFailure is returned for all errors without a more specific code
+	ExitFailure = 1
+	// ExitTimeout is returned when a plugin callback or hook was killed because
+	// it reached its timeout, the same code the timeout command uses
+	ExitTimeout = 124
+	// ExitNoVersion is returned when no version is set for a tool or command,
+	// when the version file setting it is not trusted, or when no executable
+	// could be found for the selected version
+	ExitNoVersion = 126
+	// ExitUnknownCommand is returned when the asdf command or the command to
+	// execute does not exist
+	ExitUnknownCommand = 127
+	// ExitInterrupted is returned when asdf was interrupted while running a
+	// plugin callback or hook
+	ExitInterrupted = 130
+)
+
+// ExitError is an error that carries the exit code asdf should terminate
+// with. Commands return it instead of calling os.Exit so deferred cleanups run
+// and commands can be called from tests.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for the error
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+func exitError(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode maps an error returned by a command to the exit code asdf
+// terminates with. It is the only place exit codes are decided.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	if errors.As(err, &execute.TimeoutError{}) {
+		return ExitTimeout
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ExitInterrupted
+	}
+
+	// A command run as a child process exits with its own code
+	var processErr *osexec.ExitError
+	if errors.As(err, &processErr) && processErr.ExitCode() > 0 {
+		return processErr.ExitCode()
+	}
+
+	return ExitFailure
+}
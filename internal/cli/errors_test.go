@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	osexec "os/exec"
+	"testing"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/execute"
+	"github.com/asdf-vm/asdf/internal/plugintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Run("returns success when there is no error", func(t *testing.T) {
+		assert.Equal(t, ExitSuccess, ExitCode(nil))
+	})
+
+	t.Run("returns failure for errors without exit code", func(t *testing.T) {
+		assert.Equal(t, ExitFailure, ExitCode(errors.New("failed")))
+	})
+
+	t.Run("returns code of exit error", func(t *testing.T) {
+		assert.Equal(t, ExitNoVersion, ExitCode(exitError(ExitNoVersion, errors.New("no version"))))
+	})
+
+	t.Run("returns code of wrapped exit error", func(t *testing.T) {
+		err := fmt.Errorf("wrapped: %w", exitError(ExitUnknownCommand, errors.New("unknown")))
+		assert.Equal(t, ExitUnknownCommand, ExitCode(err))
+	})
+
+	t.Run("returns timeout code when callback timed out", func(t *testing.T) {
+		err := fmt.Errorf("failed to run install callback: %w", execute.TimeoutError{Timeout: time.Second})
+		assert.Equal(t, ExitTimeout, ExitCode(err))
+	})
+
+	t.Run("returns interrupted code when callback was canceled", func(t *testing.T) {
+		assert.Equal(t, ExitInterrupted, ExitCode(context.Canceled))
+	})
+
+	t.Run("returns exit code of child process", func(t *testing.T) {
+		err := osexec.Command("sh", "-c", "exit 3").Run()
+		assert.Equal(t, 3, ExitCode(err))
+	})
+}
+
+func TestExitError(t *testing.T) {
+	err := exitError(ExitNoVersion, errors.New("no version is set"))
+
+	assert.Equal(t, "no version is set", err.Error())
+	assert.Equal(t, "no version is set", errors.Unwrap(err).Error())
+}
+
+func TestCommandsReturnErrors(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+
+	t.Run("uninstall without arguments", func(t *testing.T) {
+		assert.Equal(t, ExitFailure, ExitCode(uninstallCommand(logger, "", "")))
+	})
+
+	t.Run("plugin test without arguments", func(t *testing.T) {
+		assert.Equal(t, ExitFailure, ExitCode(pluginTestCommand(logger, []string{}, plugintest.Options{}, "")))
+	})
+}
@@ -4,9 +4,13 @@ package config
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/sethvargo/go-envconfig"
@@ -19,6 +23,9 @@ const (
 	configFileDefault                  = "~/.asdfrc"
 	defaultToolVersionsFilenameDefault = ".tool-versions"
 	defaultPluginIndexURL              = "https://github.com/asdf-vm/asdf-plugins.git"
+	// DefaultPluginIndexName is the name of the index at PluginIndexURL in the
+	// plugin_indexes setting
+	DefaultPluginIndexName = "default"
 )
 
 /* PluginRepoCheckDuration represents the remote plugin repo check duration
@@ -31,6 +38,18 @@ type PluginRepoCheckDuration struct {
 
 var pluginRepoCheckDurationDefault = PluginRepoCheckDuration{Every: 60}
 
+// pluginIndexName matches the names plugin indexes may be given, they are used
+// as directory names
+var pluginIndexName = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
+// PluginIndex is a plugin index listed by the plugin_indexes setting
+type PluginIndex struct {
+	Name string
+	// URL is the URL of a Git repository, or of a JSON document when it ends
+	// in .json
+	URL string
+}
+
 // Config is the primary value this package builds and returns
 type Config struct {
 	Home                        string
@@ -40,6 +59,8 @@ type Config struct {
 	// AsdfDir string
 	DataDir      string `env:"ASDF_DATA_DIR, overwrite"`
 	ForcePrepend bool   `env:"ASDF_FORCE_PREPEND, overwrite"`
+	// Overrides the exec_all_tools_on_path settings when set to yes or no
+	ExecAllToolsOnPath string `env:"ASDF_EXEC_ALL_TOOLS_ON_PATH, overwrite"`
 	// Field that stores the settings struct if it is loaded
 	Settings       Settings
 	PluginIndexURL string
@@ -56,6 +77,12 @@ type Settings struct {
 	PluginRepositoryLastCheckDuration PluginRepoCheckDuration
 	DisablePluginShortNameRepository  bool
 	Concurrency                       string
+	RequireTrust                      bool
+	ExecAllToolsOnPath                bool
+	PluginVerification                string
+	PluginGPGKeyring                  string
+	PluginAllowedSigners              string
+	PluginIndexes                     string
 }
 
 func defaultConfig(dataDir, configFile string) *Config {
@@ -76,6 +103,8 @@ func defaultSettings() *Settings {
 		AlwaysKeepDownload:                false,
 		PluginRepositoryLastCheckDuration: pluginRepoCheckDurationDefault,
 		DisablePluginShortNameRepository:  false,
+		RequireTrust:                      true,
+		ExecAllToolsOnPath:                false,
 	}
 }
 
@@ -168,6 +197,161 @@ func (c *Config) Concurrency() (string, error) {
 	return c.Settings.Concurrency, nil
 }
 
+// RequireTrust loads the asdfrc if it isn't already loaded and fetches the
+// flag that restricts settings like `path:` versions to trusted files
+func (c *Config) RequireTrust() (bool, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return false, err
+	}
+
+	return c.Settings.RequireTrust, nil
+}
+
+// PluginVerification loads the asdfrc if it isn't already loaded and returns
+// what plugin commits must satisfy to be added or updated: "pinned" requires
+// the commit a .plugin-versions file pins, "signed" requires a signature by
+// one of the allowed keys and "" means plugins are not verified
+func (c *Config) PluginVerification() (string, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return "", err
+	}
+
+	switch c.Settings.PluginVerification {
+	case "", "none":
+		return "", nil
+	case "pinned", "signed":
+		return c.Settings.PluginVerification, nil
+	}
+
+	return "", fmt.Errorf("invalid plugin_verification setting %q, expected none, pinned or signed", c.Settings.PluginVerification)
+}
+
+// PluginSigners loads the asdfrc if it isn't already loaded and returns the
+// paths of the armored GPG keyring and the SSH allowed signers file holding
+// the keys plugin commits may be signed with. Either may be empty.
+func (c *Config) PluginSigners() (gpgKeyring, allowedSigners string, err error) {
+	err = c.loadSettings()
+	if err != nil {
+		return "", "", err
+	}
+
+	gpgKeyring, err = homedir.Expand(c.Settings.PluginGPGKeyring)
+	if err != nil {
+		return "", "", err
+	}
+
+	allowedSigners, err = homedir.Expand(c.Settings.PluginAllowedSigners)
+	return gpgKeyring, allowedSigners, err
+}
+
+// PluginIndexes loads the asdfrc if it isn't already loaded and returns the
+// plugin indexes to look plugins up in, in order of priority. The
+// plugin_indexes setting is a comma separated list of <name>=<url> entries
+// and of default, the index at PluginIndexURL, which is also the only index
+// when the setting is empty.
+func (c *Config) PluginIndexes() (indexes []PluginIndex, err error) {
+	err = c.loadSettings()
+	if err != nil {
+		return indexes, err
+	}
+
+	if strings.TrimSpace(c.Settings.PluginIndexes) == "" {
+		return []PluginIndex{{Name: DefaultPluginIndexName, URL: c.PluginIndexURL}}, nil
+	}
+
+	for _, entry := range strings.Split(c.Settings.PluginIndexes, ",") {
+		entry = strings.TrimSpace(entry)
+		index := PluginIndex{Name: DefaultPluginIndexName, URL: c.PluginIndexURL}
+		if entry != DefaultPluginIndexName {
+			name, url, found := strings.Cut(entry, "=")
+			index = PluginIndex{Name: strings.TrimSpace(name), URL: strings.TrimSpace(url)}
+			if !found || index.URL == "" || !pluginIndexName.MatchString(index.Name) {
+				return nil, fmt.Errorf("invalid plugin_indexes entry %q, expected <name>=<url> or %s", entry, DefaultPluginIndexName)
+			}
+		}
+
+		if slices.ContainsFunc(indexes, func(other PluginIndex) bool { return other.Name == index.Name }) {
+			return nil, fmt.Errorf("invalid plugin_indexes setting, index %s is listed more than once", index.Name)
+		}
+		indexes = append(indexes, index)
+	}
+
+	return indexes, nil
+}
+
+// AllToolsOnPath loads the asdfrc if it isn't already loaded and returns
+// whether the executable paths of all tools set for the current directory are
+// put on PATH when running an executable of the named plugin. The
+// ASDF_EXEC_ALL_TOOLS_ON_PATH environment variable takes precedence over the
+// exec_all_tools_on_path_<plugin> setting, which takes precedence over
+// exec_all_tools_on_path.
+func (c *Config) AllToolsOnPath(pluginName string) (bool, error) {
+	switch strings.ToLower(c.ExecAllToolsOnPath) {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	}
+
+	err := c.loadSettings()
+	if err != nil {
+		return false, err
+	}
+
+	enabled := c.Settings.ExecAllToolsOnPath
+	if c.Settings.Raw != nil {
+		boolOverride(&enabled, c.Settings.Raw, "exec_all_tools_on_path_"+pluginName)
+	}
+
+	return enabled, nil
+}
+
+// CallbackTimeout loads the asdfrc if it isn't already loaded and returns how
+// long the named plugin callback may run before it is killed. The
+// callback_timeout_<callback> setting, with - and . in the callback name
+// replaced by _, takes precedence over callback_timeout, so setting it to 0
+// lets that callback run without a timeout. Zero means no timeout.
+func (c *Config) CallbackTimeout(callback string) (time.Duration, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	key := "callback_timeout_" + strings.NewReplacer("-", "_", ".", "_").Replace(callback)
+	if c.Settings.Raw != nil && c.Settings.Raw.HasKey(key) {
+		return c.durationSetting(key)
+	}
+
+	return c.durationSetting("callback_timeout")
+}
+
+// HookTimeout loads the asdfrc if it isn't already loaded and returns how long
+// a hook command may run before it is killed. Zero means no timeout.
+func (c *Config) HookTimeout() (time.Duration, error) {
+	return c.durationSetting("hook_timeout")
+}
+
+func (c *Config) durationSetting(key string) (time.Duration, error) {
+	err := c.loadSettings()
+	if err != nil || c.Settings.Raw == nil {
+		return 0, err
+	}
+
+	value := c.Settings.Raw.Key(key).String()
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid %s setting %q, expected a duration like 30s or 5m", key, value)
+	}
+
+	return duration, nil
+}
+
 // GetHook returns a hook command from config if it is there
 func (c *Config) GetHook(hook string) (string, error) {
 	err := c.loadSettings()
@@ -241,7 +425,13 @@ func loadSettings(asdfrcPath string) (Settings, error) {
 	boolOverride(&settings.LegacyVersionFile, mainConf, "legacy_version_file")
 	boolOverride(&settings.AlwaysKeepDownload, mainConf, "always_keep_download")
 	boolOverride(&settings.DisablePluginShortNameRepository, mainConf, "disable_plugin_short_name_repository")
+	boolOverride(&settings.RequireTrust, mainConf, "require_trust")
+	boolOverride(&settings.ExecAllToolsOnPath, mainConf, "exec_all_tools_on_path")
 	settings.Concurrency = strings.ToLower(mainConf.Key("concurrency").String())
+	settings.PluginVerification = strings.ToLower(mainConf.Key("plugin_verification").String())
+	settings.PluginGPGKeyring = mainConf.Key("plugin_gpg_keyring").String()
+	settings.PluginAllowedSigners = mainConf.Key("plugin_allowed_signers").String()
+	settings.PluginIndexes = mainConf.Key("plugin_indexes").String()
 
 	return *settings, nil
 }
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -46,6 +49,9 @@ func TestLoadSettings(t *testing.T) {
 		assert.True(t, settings.PluginRepositoryLastCheckDuration.Never, "PluginRepositoryLastCheckDuration field has wrong value")
 		assert.Zero(t, settings.PluginRepositoryLastCheckDuration.Every, "PluginRepositoryLastCheckDuration field has wrong value")
 		assert.True(t, settings.DisablePluginShortNameRepository, "DisablePluginShortNameRepository field has wrong value")
+		assert.False(t, settings.RequireTrust, "RequireTrust field has wrong value")
+		assert.True(t, settings.ExecAllToolsOnPath, "ExecAllToolsOnPath field has wrong value")
+		assert.Equal(t, "signed", settings.PluginVerification, "PluginVerification field has wrong value")
 	})
 
 	t.Run("When given path to empty file returns settings struct with defaults", func(t *testing.T) {
@@ -58,6 +64,9 @@ func TestLoadSettings(t *testing.T) {
 		assert.False(t, settings.PluginRepositoryLastCheckDuration.Never, "PluginRepositoryLastCheckDuration field has wrong value")
 		assert.Equal(t, settings.PluginRepositoryLastCheckDuration.Every, 60, "PluginRepositoryLastCheckDuration field has wrong value")
 		assert.False(t, settings.DisablePluginShortNameRepository, "DisablePluginShortNameRepository field has wrong value")
+		assert.True(t, settings.RequireTrust, "RequireTrust field has wrong value")
+		assert.False(t, settings.ExecAllToolsOnPath, "ExecAllToolsOnPath field has wrong value")
+		assert.Empty(t, settings.PluginVerification, "PluginVerification field has wrong value")
 	})
 }
 
@@ -93,6 +102,107 @@ func TestConfigMethods(t *testing.T) {
 		assert.True(t, DisablePluginShortNameRepository, "Expected DisablePluginShortNameRepository to be set")
 	})
 
+	t.Run("Returns RequireTrust from asdfrc file", func(t *testing.T) {
+		requireTrust, err := config.RequireTrust()
+		assert.Nil(t, err, "Returned error when loading settings")
+		assert.False(t, requireTrust, "Expected RequireTrust to be unset")
+	})
+
+	t.Run("Returns PluginVerification from asdfrc file", func(t *testing.T) {
+		verification, err := config.PluginVerification()
+		assert.Nil(t, err, "Returned error when loading settings")
+		assert.Equal(t, "signed", verification)
+	})
+
+	t.Run("Returns error for invalid PluginVerification", func(t *testing.T) {
+		config := Config{Settings: Settings{Loaded: true, PluginVerification: "maybe"}}
+		_, err := config.PluginVerification()
+		assert.ErrorContains(t, err, `invalid plugin_verification setting "maybe"`)
+	})
+
+	t.Run("Returns PluginSigners from asdfrc file with home directory expanded", func(t *testing.T) {
+		gpgKeyring, allowedSigners, err := config.PluginSigners()
+		assert.Nil(t, err, "Returned error when loading settings")
+		assert.Equal(t, filepath.Join(config.Home, ".config/asdf/plugin-keys.asc"), gpgKeyring)
+		assert.Equal(t, "/etc/asdf/allowed_signers", allowedSigners)
+	})
+
+	t.Run("Returns PluginIndexes from asdfrc file in order", func(t *testing.T) {
+		indexes, err := config.PluginIndexes()
+		assert.Nil(t, err, "Returned error when loading settings")
+		assert.Equal(t, []PluginIndex{
+			{Name: "company", URL: "https://example.com/asdf-plugins/index.json"},
+			{Name: DefaultPluginIndexName, URL: defaultPluginIndexURL},
+		}, indexes)
+	})
+
+	t.Run("Returns default PluginIndexes when not set", func(t *testing.T) {
+		config := Config{PluginIndexURL: defaultPluginIndexURL, Settings: Settings{Loaded: true}}
+		indexes, err := config.PluginIndexes()
+		assert.Nil(t, err)
+		assert.Equal(t, []PluginIndex{{Name: DefaultPluginIndexName, URL: defaultPluginIndexURL}}, indexes)
+	})
+
+	t.Run("Returns error for invalid PluginIndexes", func(t *testing.T) {
+		for _, setting := range []string{"https://example.com/index.json", "a/b=https://example.com/index.json", "default, default"} {
+			config := Config{Settings: Settings{Loaded: true, PluginIndexes: setting}}
+			_, err := config.PluginIndexes()
+			assert.ErrorContains(t, err, "invalid plugin_indexes", setting)
+		}
+	})
+
+	t.Run("Returns AllToolsOnPath from asdfrc file", func(t *testing.T) {
+		allTools, err := config.AllToolsOnPath("ruby")
+		assert.Nil(t, err, "Returned error when loading settings")
+		assert.True(t, allTools, "Expected AllToolsOnPath to be set")
+	})
+
+	t.Run("Returns AllToolsOnPath override for plugin from asdfrc file", func(t *testing.T) {
+		allTools, err := config.AllToolsOnPath("lua")
+		assert.Nil(t, err, "Returned error when loading settings")
+		assert.False(t, allTools, "Expected AllToolsOnPath to be overridden for plugin")
+	})
+
+	t.Run("Returns AllToolsOnPath from environment variable over asdfrc file", func(t *testing.T) {
+		config := Config{ConfigFile: "testdata/asdfrc", ExecAllToolsOnPath: "no"}
+		allTools, err := config.AllToolsOnPath("ruby")
+		assert.Nil(t, err)
+		assert.False(t, allTools)
+	})
+
+	t.Run("Returns CallbackTimeout from asdfrc file", func(t *testing.T) {
+		timeout, err := config.CallbackTimeout("install")
+		assert.Nil(t, err)
+		assert.Equal(t, 10*time.Minute, timeout)
+	})
+
+	t.Run("Returns CallbackTimeout override for callback from asdfrc file", func(t *testing.T) {
+		timeout, err := config.CallbackTimeout("list-all")
+		assert.Nil(t, err)
+		assert.Equal(t, 30*time.Second, timeout)
+	})
+
+	t.Run("Returns no CallbackTimeout when override for callback is zero", func(t *testing.T) {
+		timeout, err := config.CallbackTimeout("download")
+		assert.Nil(t, err)
+		assert.Zero(t, timeout)
+	})
+
+	t.Run("Returns HookTimeout from asdfrc file", func(t *testing.T) {
+		timeout, err := config.HookTimeout()
+		assert.Nil(t, err)
+		assert.Equal(t, time.Minute, timeout)
+	})
+
+	t.Run("Returns error when timeout is invalid", func(t *testing.T) {
+		asdfrc := filepath.Join(t.TempDir(), ".asdfrc")
+		assert.Nil(t, os.WriteFile(asdfrc, []byte("callback_timeout = soon\n"), 0o666))
+		config := Config{ConfigFile: asdfrc}
+
+		_, err := config.CallbackTimeout("install")
+		assert.ErrorContains(t, err, "invalid callback_timeout setting \"soon\"")
+	})
+
 	t.Run("When file does not exist returns settings struct with defaults", func(t *testing.T) {
 		config := Config{ConfigFile: "non-existant"}
 
@@ -115,6 +225,18 @@ func TestConfigMethods(t *testing.T) {
 		shortName, err := config.DisablePluginShortNameRepository()
 		assert.Nil(t, err)
 		assert.False(t, shortName)
+
+		requireTrust, err := config.RequireTrust()
+		assert.Nil(t, err)
+		assert.True(t, requireTrust)
+
+		allTools, err := config.AllToolsOnPath("lua")
+		assert.Nil(t, err)
+		assert.False(t, allTools)
+
+		timeout, err := config.CallbackTimeout("install")
+		assert.Nil(t, err)
+		assert.Zero(t, timeout)
 	})
 }
 
@@ -10,6 +10,9 @@ const (
 	dataDirDownloads = "downloads"
 	dataDirInstalls  = "installs"
 	dataDirPlugins   = "plugins"
+	// dataDirPluginMetadata holds what asdf records about plugins outside of
+	// their directories, so plugin Git repositories stay clean
+	dataDirPluginMetadata = "plugin-metadata"
 )
 
 // DownloadDirectory returns the directory a plugin will be placing
@@ -23,6 +26,11 @@ func InstallDirectory(dataDir, pluginName string) string {
 	return filepath.Join(dataDir, dataDirInstalls, pluginName)
 }
 
+// InstallsDirectory returns the path to the installs directory in the data dir
+func InstallsDirectory(dataDir string) string {
+	return filepath.Join(dataDir, dataDirInstalls)
+}
+
 // PluginsDirectory returns the path to the plugins directory in the data dir
 func PluginsDirectory(dataDir string) string {
 	return filepath.Join(dataDir, dataDirPlugins)
@@ -33,3 +41,9 @@ func PluginsDirectory(dataDir string) string {
 func PluginDirectory(dataDir, pluginName string) string {
 	return filepath.Join(dataDir, dataDirPlugins, pluginName)
 }
+
+// PluginMetadataFile returns the path of the file asdf records metadata about
+// a plugin in
+func PluginMetadataFile(dataDir, pluginName string) string {
+	return filepath.Join(dataDir, dataDirPluginMetadata, pluginName+".json")
+}
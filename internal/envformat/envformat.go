@@ -0,0 +1,178 @@
+// Package envformat renders environment variable changes in the syntax of
+// shells and other tools, so environments computed by asdf can be evaluated by
+// a shell or consumed by programs like Docker, systemd or editors.
+package envformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Changes represents a set of changes to an environment. Variables in Set are
+// set to the given values and variables in Unset are removed.
+type Changes struct {
+	Set   map[string]string
+	Unset []string
+}
+
+// UnknownFormatError is returned when no formatter exists for a format name
+type UnknownFormatError struct {
+	format string
+}
+
+func (e UnknownFormatError) Error() string {
+	return fmt.Sprintf("unknown format %q, available formats are: %s", e.format, strings.Join(Names(), ", "))
+}
+
+type formatter func(w io.Writer, changes Changes) error
+
+var formatters = map[string]formatter{
+	"sh":     writeSh,
+	"fish":   writeFish,
+	"json":   writeJSON,
+	"dotenv": writeDotenv,
+	"pwsh":   writePwsh,
+}
+
+// Write writes the changes to the writer in the named format.
+func Write(w io.Writer, format string, changes Changes) error {
+	formatter, ok := formatters[format]
+	if !ok {
+		return UnknownFormatError{format: format}
+	}
+
+	return formatter(w, changes)
+}
+
+// Names returns a sorted slice of all available format names.
+func Names() []string {
+	var names []string
+	for name := range formatters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func writeSh(w io.Writer, changes Changes) error {
+	for _, name := range sortedUnset(changes) {
+		if _, err := fmt.Fprintf(w, "unset %s\n", name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sortedKeys(changes.Set) {
+		if _, err := fmt.Fprintf(w, "export %s=%s\n", name, singleQuote(changes.Set[name])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFish(w io.Writer, changes Changes) error {
+	for _, name := range sortedUnset(changes) {
+		if _, err := fmt.Fprintf(w, "set -e %s\n", name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sortedKeys(changes.Set) {
+		// Fish treats variables ending in PATH as lists that are joined with
+		// colons when exported, so each element must be passed separately.
+		values := []string{changes.Set[name]}
+		if strings.HasSuffix(name, "PATH") {
+			values = strings.Split(changes.Set[name], ":")
+		}
+
+		var quoted []string
+		for _, value := range values {
+			quoted = append(quoted, fishQuote(value))
+		}
+
+		if _, err := fmt.Fprintf(w, "set -gx %s %s\n", name, strings.Join(quoted, " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writePwsh(w io.Writer, changes Changes) error {
+	for _, name := range sortedUnset(changes) {
+		if _, err := fmt.Fprintf(w, "Remove-Item -ErrorAction SilentlyContinue Env:%s\n", name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sortedKeys(changes.Set) {
+		if _, err := fmt.Fprintf(w, "$Env:%s = %s\n", name, pwshQuote(changes.Set[name])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(w io.Writer, changes Changes) error {
+	// Unset variables are represented with null values
+	env := map[string]*string{}
+	for _, name := range changes.Unset {
+		env[name] = nil
+	}
+
+	for name, value := range changes.Set {
+		env[name] = &value
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(env)
+}
+
+func writeDotenv(w io.Writer, changes Changes) error {
+	// dotenv files have no way of unsetting a variable, so unsets are ignored
+	for _, name := range sortedKeys(changes.Set) {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, doubleQuote(changes.Set[name])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func singleQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func fishQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+func pwshQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func doubleQuote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func sortedKeys(env map[string]string) []string {
+	var keys []string
+	for key := range env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func sortedUnset(changes Changes) []string {
+	names := slices.Clone(changes.Unset)
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,81 @@
+package envformat
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	changes := Changes{
+		Set:   map[string]string{"FOO": "bar", "JAVA_OPTS": "-Dfoo=bar -Dbaz='qux'"},
+		Unset: []string{"OLD"},
+	}
+
+	t.Run("returns error for unknown format", func(t *testing.T) {
+		var output strings.Builder
+		err := Write(&output, "foo", changes)
+		assert.ErrorContains(t, err, `unknown format "foo"`)
+		assert.Empty(t, output.String())
+	})
+
+	t.Run("writes sh syntax", func(t *testing.T) {
+		var output strings.Builder
+		assert.Nil(t, Write(&output, "sh", changes))
+		expected := "unset OLD\nexport FOO='bar'\nexport JAVA_OPTS='-Dfoo=bar -Dbaz='\\''qux'\\'''\n"
+		assert.Equal(t, expected, output.String())
+	})
+
+	t.Run("writes fish syntax", func(t *testing.T) {
+		var output strings.Builder
+		assert.Nil(t, Write(&output, "fish", changes))
+		expected := "set -e OLD\nset -gx FOO 'bar'\nset -gx JAVA_OPTS '-Dfoo=bar -Dbaz=\\'qux\\''\n"
+		assert.Equal(t, expected, output.String())
+	})
+
+	t.Run("writes fish PATH variables as lists", func(t *testing.T) {
+		var output strings.Builder
+		assert.Nil(t, Write(&output, "fish", Changes{Set: map[string]string{"PATH": "/foo/bin:/usr/bin"}}))
+		assert.Equal(t, "set -gx PATH '/foo/bin' '/usr/bin'\n", output.String())
+	})
+
+	t.Run("writes pwsh syntax", func(t *testing.T) {
+		var output strings.Builder
+		assert.Nil(t, Write(&output, "pwsh", changes))
+		expected := "Remove-Item -ErrorAction SilentlyContinue Env:OLD\n$Env:FOO = 'bar'\n$Env:JAVA_OPTS = '-Dfoo=bar -Dbaz=''qux'''\n"
+		assert.Equal(t, expected, output.String())
+	})
+
+	t.Run("writes json with null for unset variables", func(t *testing.T) {
+		var output strings.Builder
+		assert.Nil(t, Write(&output, "json", changes))
+		expected := "{\n  \"FOO\": \"bar\",\n  \"JAVA_OPTS\": \"-Dfoo=bar -Dbaz='qux'\",\n  \"OLD\": null\n}\n"
+		assert.Equal(t, expected, output.String())
+	})
+
+	t.Run("writes dotenv syntax and ignores unset variables", func(t *testing.T) {
+		var output strings.Builder
+		changes := Changes{Set: map[string]string{"FOO": "a \"b\"\nc"}, Unset: []string{"OLD"}}
+		assert.Nil(t, Write(&output, "dotenv", changes))
+		assert.Equal(t, "FOO=\"a \\\"b\\\"\\nc\"\n", output.String())
+	})
+
+	t.Run("sh output evaluates to the original values", func(t *testing.T) {
+		value := "it's a \"test\" with $dollar `tick` and\nnewline"
+		var output strings.Builder
+		assert.Nil(t, Write(&output, "sh", Changes{Set: map[string]string{"FOO": value}}))
+
+		cmd := exec.Command("bash", "-c", output.String()+`printf '%s' "$FOO"`)
+		result, err := cmd.Output()
+		assert.Nil(t, err)
+		assert.Equal(t, value, string(result))
+	})
+}
+
+func TestNames(t *testing.T) {
+	t.Run("returns sorted format names", func(t *testing.T) {
+		assert.Equal(t, []string{"dotenv", "fish", "json", "pwsh", "sh"}, Names())
+	})
+}
@@ -0,0 +1,106 @@
+// Package ephemeral prepares tool versions given on the command line for a
+// single command run by `asdf x`, installing them when missing and optionally
+// removing them afterwards, without reading or writing any version file.
+package ephemeral
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/shims"
+	"github.com/asdf-vm/asdf/internal/toolenv"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/versions"
+)
+
+// Spec is a tool and version requested on the command line in the form
+// <name>@<version>
+type Spec struct {
+	Name    string
+	Version string
+}
+
+// InvalidSpecError is returned when an argument is not in the form
+// <name>@<version>
+type InvalidSpecError struct {
+	Arg string
+}
+
+func (e InvalidSpecError) Error() string {
+	return fmt.Sprintf("invalid tool version %q, expected <name>@<version>", e.Arg)
+}
+
+// ParseSpec parses an argument in the form <name>@<version>. The version may
+// be anything accepted by `asdf install`, including latest and latest:<query>.
+func ParseSpec(arg string) (Spec, error) {
+	name, version, found := strings.Cut(arg, "@")
+	if !found || name == "" || version == "" {
+		return Spec{}, InvalidSpecError{Arg: arg}
+	}
+
+	return Spec{Name: name, Version: version}, nil
+}
+
+// Prepare resolves each spec to a concrete version and installs the versions
+// that are not installed yet. It returns the tool versions to use, in the order
+// of specs, and the ones that were installed by this call. On error the tool
+// versions installed before it occurred are still returned so they can be
+// cleaned up.
+func Prepare(conf config.Config, specs []Spec, stdout, stderr io.Writer) (toolVersions, installed []toolenv.ToolVersion, err error) {
+	for _, spec := range specs {
+		plugin := plugins.New(conf, spec.Name)
+		if err := plugin.Exists(); err != nil {
+			return toolVersions, installed, err
+		}
+
+		version := toolversions.ParseFromCliArg(spec.Version)
+		if version.Type == "latest" {
+			latest, err := versions.Latest(conf, plugin, version.Value)
+			if err != nil {
+				return toolVersions, installed, fmt.Errorf("unable to resolve latest version of %s: %w", spec.Name, err)
+			}
+			version = toolversions.Parse(latest)
+		}
+
+		toolVersion := toolenv.ToolVersion{Plugin: plugin, Version: version}
+		toolVersions = append(toolVersions, toolVersion)
+
+		if version.Type == "system" || version.Type == "path" || installs.IsInstalled(conf, plugin, version) {
+			continue
+		}
+
+		err := versions.InstallOneVersion(conf, plugin, toolversions.Format(version), false, stdout, stderr)
+		if err != nil {
+			return toolVersions, installed, err
+		}
+		installed = append(installed, toolVersion)
+	}
+
+	return toolVersions, installed, nil
+}
+
+// Cleanup uninstalls the given tool versions and regenerates shims so none are
+// left pointing at them.
+func Cleanup(conf config.Config, toolVersions []toolenv.ToolVersion, stdout, stderr io.Writer) error {
+	if len(toolVersions) == 0 {
+		return nil
+	}
+
+	for _, toolVersion := range toolVersions {
+		err := versions.Uninstall(conf, toolVersion.Plugin, toolversions.Format(toolVersion.Version), stdout, stderr)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Shims are regenerated the same way `asdf uninstall` does it
+	if err := shims.RemoveAll(conf); err != nil {
+		return err
+	}
+
+	return shims.GenerateAll(conf, stdout, stderr)
+}
@@ -0,0 +1,105 @@
+package ephemeral
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/installtest"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginName = "lua"
+
+func TestParseSpec(t *testing.T) {
+	t.Run("returns name and version", func(t *testing.T) {
+		spec, err := ParseSpec("lua@5.4.6")
+		assert.Nil(t, err)
+		assert.Equal(t, Spec{Name: "lua", Version: "5.4.6"}, spec)
+	})
+
+	t.Run("keeps latest query in version", func(t *testing.T) {
+		spec, err := ParseSpec("lua@latest:5.3")
+		assert.Nil(t, err)
+		assert.Equal(t, Spec{Name: "lua", Version: "latest:5.3"}, spec)
+	})
+
+	t.Run("returns error when version is missing", func(t *testing.T) {
+		for _, arg := range []string{"lua", "lua@", "@5.4.6"} {
+			_, err := ParseSpec(arg)
+			assert.Equal(t, InvalidSpecError{Arg: arg}, err)
+		}
+	})
+}
+
+func TestPrepare(t *testing.T) {
+	t.Run("installs missing version and returns it as installed", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		var stdout, stderr strings.Builder
+
+		toolVersions, installed, err := Prepare(conf, []Spec{{Name: testPluginName, Version: "1.0.0"}}, &stdout, &stderr)
+		assert.Nil(t, err)
+		version := toolversions.Version{Type: "version", Value: "1.0.0"}
+		assert.Equal(t, version, toolVersions[0].Version)
+		assert.Equal(t, toolVersions, installed)
+		assert.True(t, installs.IsInstalled(conf, plugin, version))
+	})
+
+	t.Run("does not return versions that were already installed", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		assert.Nil(t, installtest.InstallOneVersion(conf, plugin, "version", "1.0.0"))
+		var stdout, stderr strings.Builder
+
+		toolVersions, installed, err := Prepare(conf, []Spec{{Name: testPluginName, Version: "1.0.0"}}, &stdout, &stderr)
+		assert.Nil(t, err)
+		assert.Len(t, toolVersions, 1)
+		assert.Empty(t, installed)
+	})
+
+	t.Run("resolves latest version", func(t *testing.T) {
+		conf, _ := generateConfig(t)
+		var stdout, stderr strings.Builder
+
+		toolVersions, _, err := Prepare(conf, []Spec{{Name: testPluginName, Version: "latest"}}, &stdout, &stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, "2.0.0", toolVersions[0].Version.Value)
+	})
+
+	t.Run("returns error when plugin does not exist", func(t *testing.T) {
+		conf, _ := generateConfig(t)
+		var stdout, stderr strings.Builder
+
+		_, _, err := Prepare(conf, []Spec{{Name: "non-existent", Version: "1.0.0"}}, &stdout, &stderr)
+		assert.IsType(t, plugins.PluginMissing{}, err)
+	})
+}
+
+func TestCleanup(t *testing.T) {
+	t.Run("uninstalls versions", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		var stdout, stderr strings.Builder
+		_, installed, err := Prepare(conf, []Spec{{Name: testPluginName, Version: "1.0.0"}}, &stdout, &stderr)
+		assert.Nil(t, err)
+
+		assert.Nil(t, Cleanup(conf, installed, &stdout, &stderr))
+		assert.False(t, installs.IsInstalled(conf, plugin, toolversions.Version{Type: "version", Value: "1.0.0"}))
+	})
+}
+
+func generateConfig(t *testing.T) (config.Config, plugins.Plugin) {
+	t.Helper()
+	testDataDir := t.TempDir()
+	conf, err := config.LoadConfig()
+	assert.Nil(t, err)
+	conf.DataDir = testDataDir
+	conf.ConfigFile = "non-existent"
+
+	_, err = repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	return conf, plugins.New(conf, testPluginName)
+}
@@ -3,7 +3,11 @@
 package execenv
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"strings"
 
@@ -11,7 +15,19 @@ import (
 	"github.com/asdf-vm/asdf/internal/plugins"
 )
 
-const execEnvCallbackName = "exec-env"
+const (
+	execEnvCallbackName = "exec-env"
+	jsonFormatLine      = "# asdf-exec-env-format: json"
+	formatLineLimit     = 10
+	// dumpMarker separates the environment printed before the callback is
+	// sourced from the one printed after. It has no "=", so it can't be
+	// mistaken for a variable.
+	dumpMarker = "__ASDF_EXEC_ENV_MARKER__"
+)
+
+// Variables Bash sets on its own, which may differ between the environment
+// captured before sourcing exec-env and after without exec-env changing them.
+var shellVariables = []string{"_", "OLDPWD", "PWD", "SHLVL"}
 
 // CurrentEnv returns the current environment as a map
 func CurrentEnv() map[string]string {
@@ -27,50 +43,179 @@ func MergeEnv(map1, map2 map[string]string) map[string]string {
 	return map1
 }
 
+// Diff compares two environments and returns the variables that were added
+// or changed in after, along with the names of the variables that are missing
+// from after.
+func Diff(before, after map[string]string) (set map[string]string, unset []string) {
+	set = map[string]string{}
+
+	for key, value := range after {
+		if oldValue, ok := before[key]; !ok || oldValue != value {
+			set[key] = value
+		}
+	}
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			unset = append(unset, key)
+		}
+	}
+
+	return set, unset
+}
+
 // Generate runs exec-env callback if available and captures the environment
-// variables it sets. It then parses them and returns them as a map.
-func Generate(plugin plugins.Plugin, callbackEnv map[string]string) (env map[string]string, err error) {
-	execEnvPath, err := plugin.CallbackPath(execEnvCallbackName)
+// variables it sets. It returns callbackEnv with the variables set by the
+// callback added and those it unset removed. Anything the callback prints is
+// written to stderr.
+func Generate(plugin plugins.Plugin, callbackEnv map[string]string, stderr io.Writer) (env map[string]string, err error) {
+	set, unset, err := GenerateChanges(plugin, callbackEnv, stderr)
 	if err != nil {
 		return callbackEnv, err
 	}
 
+	env = maps.Clone(callbackEnv)
+	if env == nil {
+		env = map[string]string{}
+	}
+	for _, name := range unset {
+		delete(env, name)
+	}
+
+	return MergeEnv(env, set), nil
+}
+
+// GenerateChanges runs the exec-env callback if available and returns the
+// variables it set and the names of the variables it unset.
+//
+// By default the callback is sourced by Bash and the environment is captured
+// before and after, so any value, including ones containing newlines, is
+// preserved. Callbacks containing a "# asdf-exec-env-format: json" line are
+// executed instead, and must print a JSON object mapping variable names to
+// values, with null for variables to unset. Anything else the callback prints
+// is written to stderr.
+func GenerateChanges(plugin plugins.Plugin, callbackEnv map[string]string, stderr io.Writer) (set map[string]string, unset []string, err error) {
+	execEnvPath, err := plugin.CallbackPath(execEnvCallbackName)
+	if err != nil {
+		return map[string]string{}, []string{}, err
+	}
+
+	isJSON, err := usesJSONFormat(execEnvPath)
+	if err != nil {
+		return map[string]string{}, []string{}, err
+	}
+
+	if isJSON {
+		return generateJSON(execEnvPath, callbackEnv, stderr)
+	}
+
+	return generateSourced(execEnvPath, callbackEnv, stderr)
+}
+
+func generateSourced(execEnvPath string, callbackEnv map[string]string, stderr io.Writer) (map[string]string, []string, error) {
 	var stdout strings.Builder
 
 	// This is done to support the legacy behavior. exec-env is the only asdf
 	// callback that works by exporting environment variables. Because of this,
 	// executing the callback isn't enough. We actually need to source it (.) so
-	// the environment variables get set, and then run `env` so they get printed
-	// to STDOUT.
-	expression := execute.NewExpression(fmt.Sprintf(". \"%s\"; env", execEnvPath), []string{})
+	// the environment variables get set. The environment is printed before and
+	// after with NUL delimiters, so values may contain any character, and
+	// anything the callback prints is sent to stderr. The callback path is
+	// passed as an argument so it is never interpreted by Bash.
+	script := fmt.Sprintf(`__asdf_dump_env() {
+  local name
+  for name in $(compgen -e); do printf '%%s=%%s\0' "$name" "${!name}"; done
+  printf '%%s\0' %s
+}
+__asdf_exec_env_path=$1
+shift
+__asdf_dump_env
+. "$__asdf_exec_env_path" >&2
+__asdf_dump_env`, dumpMarker)
+
+	expression := execute.NewExpression(script, []string{execEnvPath})
 	expression.Env = callbackEnv
 	expression.Stdout = &stdout
-	err = expression.Run()
+	expression.Stderr = stderr
+	if err := expression.Run(); err != nil {
+		return map[string]string{}, []string{}, err
+	}
 
-	return envMap(stdout.String()), err
+	dumps := strings.Split(stdout.String(), dumpMarker+"\x00")
+	if len(dumps) != 3 {
+		return map[string]string{}, []string{}, fmt.Errorf("unable to capture environment of %s", execEnvPath)
+	}
+
+	before, after := nulToMap(dumps[0]), nulToMap(dumps[1])
+	for _, name := range shellVariables {
+		delete(before, name)
+		delete(after, name)
+	}
+
+	set, unset := Diff(before, after)
+	return set, unset, nil
 }
 
-func envMap(env string) map[string]string {
-	slice := map[string]string{}
+func generateJSON(execEnvPath string, callbackEnv map[string]string, stderr io.Writer) (map[string]string, []string, error) {
+	var stdout strings.Builder
+
+	cmd := execute.New(execEnvPath, []string{})
+	cmd.Env = callbackEnv
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return map[string]string{}, []string{}, err
+	}
 
-	for _, envVar := range strings.Split(env, "\n") {
-		varValue := strings.Split(envVar, "=")
-		if len(varValue) == 2 {
-			slice[varValue[0]] = varValue[1]
+	var changes map[string]*string
+	if err := json.Unmarshal([]byte(stdout.String()), &changes); err != nil {
+		return map[string]string{}, []string{}, fmt.Errorf("invalid JSON printed by %s: %w", execEnvPath, err)
+	}
+
+	set := map[string]string{}
+	unset := []string{}
+	for name, value := range changes {
+		if value == nil {
+			unset = append(unset, name)
+		} else {
+			set[name] = *value
+		}
+	}
+
+	return set, unset, nil
+}
+
+// usesJSONFormat reports whether the callback opted into the JSON protocol
+// with a format line near the top of the file.
+func usesJSONFormat(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for i := 0; i < formatLineLimit && scanner.Scan(); i++ {
+		if strings.TrimSpace(scanner.Text()) == jsonFormatLine {
+			return true, nil
 		}
 	}
 
-	return slice
+	return false, scanner.Err()
+}
+
+func nulToMap(env string) map[string]string {
+	return SliceToMap(strings.Split(strings.TrimSuffix(env, "\x00"), "\x00"))
 }
 
-// SliceToMap converts an env map to env slice suitable for syscall.Exec
+// SliceToMap converts an env slice in the form returned by os.Environ to a map
 func SliceToMap(env []string) map[string]string {
 	envMap := map[string]string{}
 
 	for _, envVar := range env {
-		varValue := strings.Split(envVar, "=")
-		if len(varValue) == 2 {
-			envMap[varValue[0]] = varValue[1]
+		name, value, found := strings.Cut(envVar, "=")
+		if found && name != "" {
+			envMap[name] = value
 		}
 	}
 
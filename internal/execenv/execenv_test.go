@@ -1,6 +1,7 @@
 package execenv
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/asdf-vm/asdf/internal/config"
@@ -47,6 +48,58 @@ func TestMergeEnv(t *testing.T) {
 	})
 }
 
+func TestDiff(t *testing.T) {
+	t.Run("returns added and changed variables", func(t *testing.T) {
+		before := map[string]string{"Key": "value", "Same": "same"}
+		after := map[string]string{"Key": "value2", "Same": "same", "New": "new"}
+		set, unset := Diff(before, after)
+		assert.Equal(t, map[string]string{"Key": "value2", "New": "new"}, set)
+		assert.Empty(t, unset)
+	})
+
+	t.Run("returns names of removed variables", func(t *testing.T) {
+		before := map[string]string{"Key": "value", "Removed": "value"}
+		after := map[string]string{"Key": "value"}
+		set, unset := Diff(before, after)
+		assert.Empty(t, set)
+		assert.Equal(t, []string{"Removed"}, unset)
+	})
+}
+
+func TestGenerateChanges(t *testing.T) {
+	testDataDir := t.TempDir()
+
+	t.Run("returns only variables changed by callback", func(t *testing.T) {
+		plugin := installExecEnvPlugin(t, testDataDir, "#!/usr/bin/env bash\nexport BAZ=bar FOO=foo\nunset OLD")
+		set, unset, err := GenerateChanges(plugin, map[string]string{"FOO": "foo", "OLD": "old"}, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"BAZ": "bar"}, set)
+		assert.Equal(t, []string{"OLD"}, unset)
+	})
+
+	t.Run("parses JSON printed by callback using JSON format", func(t *testing.T) {
+		script := "#!/usr/bin/env bash\n# asdf-exec-env-format: json\nprintf '{\"FOO\": \"%s=1\", \"OLD\": null}' \"$ASDF_INSTALL_VERSION\""
+		plugin := installExecEnvPlugin(t, testDataDir, script)
+		set, unset, err := GenerateChanges(plugin, map[string]string{"ASDF_INSTALL_VERSION": "1.0.0"}, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"FOO": "1.0.0=1"}, set)
+		assert.Equal(t, []string{"OLD"}, unset)
+	})
+
+	t.Run("returns error when callback using JSON format prints invalid JSON", func(t *testing.T) {
+		plugin := installExecEnvPlugin(t, testDataDir, "#!/usr/bin/env bash\n# asdf-exec-env-format: json\necho FOO=bar")
+		_, _, err := GenerateChanges(plugin, map[string]string{}, &strings.Builder{})
+		assert.ErrorContains(t, err, "invalid JSON printed by")
+	})
+}
+
+func TestSliceToMap(t *testing.T) {
+	t.Run("splits variables on first equals sign", func(t *testing.T) {
+		env := SliceToMap([]string{"FOO=bar", "JAVA_OPTS=-Dfoo=bar", "EMPTY=", "INVALID"})
+		assert.Equal(t, map[string]string{"FOO": "bar", "JAVA_OPTS": "-Dfoo=bar", "EMPTY": ""}, env)
+	})
+}
+
 func TestGenerate(t *testing.T) {
 	testDataDir := t.TempDir()
 
@@ -56,20 +109,55 @@ func TestGenerate(t *testing.T) {
 		assert.Nil(t, err)
 		plugin := plugins.New(conf, testPluginName)
 		assert.Nil(t, repotest.WritePluginCallback(plugin.Dir, "exec-env", "#!/usr/bin/env bash\nexport BAZ=bar"))
-		env, err := Generate(plugin, map[string]string{"ASDF_INSTALL_VERSION": "test"})
+		env, err := Generate(plugin, map[string]string{"ASDF_INSTALL_VERSION": "test"}, &strings.Builder{})
 		assert.Nil(t, err)
 		assert.Equal(t, "bar", env["BAZ"])
 		assert.Equal(t, "test", env["ASDF_INSTALL_VERSION"])
 	})
 
+	t.Run("preserves values containing equals signs and newlines", func(t *testing.T) {
+		plugin := installExecEnvPlugin(t, testDataDir, "#!/usr/bin/env bash\nexport JAVA_OPTS='-Dfoo=bar -Dbaz=qux'\nexport MULTI=$'line1\\nline2'")
+		env, err := Generate(plugin, map[string]string{}, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, "-Dfoo=bar -Dbaz=qux", env["JAVA_OPTS"])
+		assert.Equal(t, "line1\nline2", env["MULTI"])
+	})
+
+	t.Run("removes variables unset by callback", func(t *testing.T) {
+		plugin := installExecEnvPlugin(t, testDataDir, "#!/usr/bin/env bash\nunset FOO")
+		env, err := Generate(plugin, map[string]string{"FOO": "bar", "BAZ": "qux"}, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"BAZ": "qux"}, env)
+	})
+
+	t.Run("writes output printed by callback to stderr", func(t *testing.T) {
+		plugin := installExecEnvPlugin(t, testDataDir, "#!/usr/bin/env bash\necho FOO=wrong\nexport BAZ=bar")
+		var stderr strings.Builder
+		env, err := Generate(plugin, map[string]string{}, &stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"BAZ": "bar"}, env)
+		assert.Equal(t, "FOO=wrong\n", stderr.String())
+	})
+
 	t.Run("returns error when plugin lacks exec-env callback", func(t *testing.T) {
 		conf := config.Config{DataDir: testDataDir}
 		_, err := repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName2)
 		assert.Nil(t, err)
 		plugin := plugins.New(conf, testPluginName2)
-		env, err := Generate(plugin, map[string]string{})
+		env, err := Generate(plugin, map[string]string{}, &strings.Builder{})
 		assert.Equal(t, err.(plugins.NoCallbackError).Error(), "Plugin named ruby does not have a callback named exec-env")
 		_, found := env["FOO"]
 		assert.False(t, found)
 	})
 }
+
+func installExecEnvPlugin(t *testing.T, dataDir, script string) plugins.Plugin {
+	t.Helper()
+
+	name := strings.ToLower(strings.NewReplacer("/", "-", " ", "-").Replace(t.Name()))
+	_, err := repotest.InstallPlugin("dummy_plugin", dataDir, name)
+	assert.Nil(t, err)
+	plugin := plugins.New(config.Config{DataDir: dataDir}, name)
+	assert.Nil(t, repotest.WritePluginCallback(plugin.Dir, "exec-env", script))
+	return plugin
+}
@@ -4,13 +4,28 @@
 package execute
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
-	"strings"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
 )
 
-// Command represents a Bash command that can be executed by asdf
+// waitDelay is how long a command that timed out or was cancelled has to exit
+// after it is sent SIGTERM, before it is killed
+const waitDelay = time.Second
+
+// expressionName is the value of $0 in Bash expressions
+const expressionName = "asdf-hook"
+
+// Command represents an executable, or a Bash expression, that can be
+// executed by asdf
 type Command struct {
 	Command    string
 	Expression string
@@ -19,9 +34,22 @@ type Command struct {
 	Stdout     io.Writer
 	Stderr     io.Writer
 	Env        map[string]string
+	// Timeout is how long the command may run before it is killed. Zero means
+	// no timeout.
+	Timeout time.Duration
+}
+
+// TimeoutError is returned when a command is killed because it ran for longer
+// than its timeout
+type TimeoutError struct {
+	Timeout time.Duration
 }
 
-// New takes a string containing the path to a Bash script, and a slice of
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.Timeout)
+}
+
+// New takes the path to an executable, usually a Bash script, and a slice of
 // string arguments and returns a Command struct
 func New(command string, args []string) Command {
 	return Command{Command: command, Args: args}
@@ -33,20 +61,66 @@ func NewExpression(expression string, args []string) Command {
 	return Command{Expression: expression, Args: args}
 }
 
-// Run executes a Command with Bash and returns the error if there is one
+// Run executes a Command and returns the error if there is one
 func (c Command) Run() error {
-	var command string
+	return c.RunContext(context.Background())
+}
+
+// RunContext executes a Command. When ctx can be cancelled or the command has a
+// Timeout it runs in its own process group, which is sent SIGTERM when ctx is
+// done, when asdf is interrupted or terminated, or when the command runs for
+// longer than its Timeout. Whatever is left of the group is killed once the
+// command has exited, or after waitDelay if it doesn't. A TimeoutError is
+// returned in the timeout case and the error of ctx otherwise.
+//
+// Arguments are never interpreted by a shell. Executables are run directly
+// with Args as their arguments, and Bash expressions receive Args as the
+// positional parameters $1, $2 and so on.
+func (c Command) RunContext(ctx context.Context) error {
+	// A command that can't be cancelled stays in the process group of asdf,
+	// like any other child process, and receives the signals sent to it
+	group := c.Timeout > 0 || ctx.Done() != nil
+	if group {
+		// The command has its own process group so it doesn't receive the
+		// signals sent to asdf, they are handled here instead so nothing it
+		// started is left running.
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+	}
+
+	runCtx := ctx
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	var err error
 	if c.Expression != "" {
-		// Expressions need to be invoked inside a Bash function, so variables like
-		// $0 and $@ are available
-		command = fmt.Sprintf("fn() { %s; }; fn %s", c.Expression, formatArgString(c.Args))
+		err = c.run(runCtx, group, "bash", append([]string{"-c", c.Expression, expressionName}, c.Args...))
 	} else {
-		// Scripts can be invoked directly, with args provided
-		command = fmt.Sprintf("%s %s", c.Command, formatArgString(c.Args))
+		err = c.run(runCtx, group, c.Command, c.Args)
+		// Scripts without a shebang line were run by Bash before callbacks were
+		// executed directly, they still are
+		if errors.Is(err, syscall.ENOEXEC) {
+			err = c.run(runCtx, group, "bash", append([]string{c.Command}, c.Args...))
+		}
 	}
 
-	cmd := exec.Command("bash", "-c", command)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
 
+	if err != nil && runCtx.Err() == context.DeadlineExceeded {
+		return TimeoutError{Timeout: c.Timeout}
+	}
+
+	return err
+}
+
+func (c Command) run(ctx context.Context, group bool, name string, args []string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Env = MapToSlice(c.Env)
 	cmd.Stdin = c.Stdin
 
@@ -54,7 +128,57 @@ func (c Command) Run() error {
 	cmd.Stdout = c.Stdout
 	cmd.Stderr = c.Stderr
 
-	return cmd.Run()
+	if !group {
+		return cmd.Run()
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = waitDelay
+
+	// A process group in the background is stopped when it reads from the
+	// terminal, so the group is given the terminal asdf has while it runs
+	terminal, foreground := c.foregroundTerminal()
+	if foreground {
+		cmd.SysProcAttr.Foreground = true
+		cmd.SysProcAttr.Ctty = terminal
+		defer restoreForeground(terminal)
+	}
+
+	err := cmd.Run()
+	if ctx.Err() != nil && cmd.Process != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	return err
+}
+
+// foregroundTerminal returns the file descriptor of the command's stdin when
+// it is a terminal asdf is in the foreground of
+func (c Command) foregroundTerminal() (int, bool) {
+	file, ok := c.Stdin.(*os.File)
+	if !ok {
+		return 0, false
+	}
+
+	terminal := int(file.Fd())
+	foreground, err := unix.IoctlGetInt(terminal, unix.TIOCGPGRP)
+	if err != nil || foreground != unix.Getpgrp() {
+		return 0, false
+	}
+
+	return terminal, true
+}
+
+// restoreForeground returns the terminal to the process group of asdf. SIGTTOU
+// is ignored meanwhile as asdf is in the background until it has the terminal.
+func restoreForeground(terminal int) {
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+
+	_ = unix.IoctlSetPointerInt(terminal, unix.TIOCSPGRP, unix.Getpgrp())
 }
 
 // MapToSlice converts an env map to env slice suitable for syscall.Exec
@@ -65,11 +189,3 @@ func MapToSlice(env map[string]string) (slice []string) {
 
 	return slice
 }
-
-func formatArgString(args []string) string {
-	var newArgs []string
-	for _, str := range args {
-		newArgs = append(newArgs, fmt.Sprintf("\"%s\"", str))
-	}
-	return strings.Join(newArgs, " ")
-}
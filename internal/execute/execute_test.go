@@ -1,9 +1,15 @@
 package execute
 
 import (
+	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -25,15 +31,43 @@ func TestNewExpression(t *testing.T) {
 }
 
 func TestRun_Command(t *testing.T) {
-	t.Run("command is executed with bash", func(t *testing.T) {
-		cmd := New("echo $(type -a sh);", []string{})
+	t.Run("script without shebang is executed with bash", func(t *testing.T) {
+		cmd := New("testdata/no_shebang", []string{"test string"})
 
 		var stdout strings.Builder
 		cmd.Stdout = &stdout
 		err := cmd.Run()
 
 		assert.Nil(t, err)
-		assert.Contains(t, stdout.String(), "sh is /")
+		assert.Equal(t, "bash test string\n", stdout.String())
+	})
+
+	t.Run("args are passed to command without interpretation", func(t *testing.T) {
+		args := []string{`"quoted"`, "$HOME", "`id`", "line\nbreak", "it's", ""}
+		cmd := New("testdata/args", args)
+
+		var stdout strings.Builder
+		cmd.Stdout = &stdout
+		err := cmd.Run()
+
+		assert.Nil(t, err)
+		assert.Equal(t, args, splitArgs(stdout.String()))
+	})
+
+	t.Run("command path may contain quotes and spaces", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), `it's a "dir"`)
+		assert.Nil(t, os.Mkdir(dir, 0o777))
+		script, err := os.ReadFile("testdata/args")
+		assert.Nil(t, err)
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "args"), script, 0o777))
+		cmd := New(filepath.Join(dir, "args"), []string{"test string"})
+
+		var stdout strings.Builder
+		cmd.Stdout = &stdout
+		err = cmd.Run()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"test string"}, splitArgs(stdout.String()))
 	})
 
 	t.Run("positional arg is passed to command", func(t *testing.T) {
@@ -59,7 +93,7 @@ func TestRun_Command(t *testing.T) {
 	})
 
 	t.Run("environment variables are passed to command", func(t *testing.T) {
-		cmd := New("echo $MYVAR;", []string{})
+		cmd := New("bash", []string{"-c", "echo $MYVAR"})
 		cmd.Env = map[string]string{"MYVAR": "my var value"}
 
 		var stdout strings.Builder
@@ -71,7 +105,7 @@ func TestRun_Command(t *testing.T) {
 	})
 
 	t.Run("captures stdout and stdin", func(t *testing.T) {
-		cmd := New("echo 'a test' | tee /dev/stderr", []string{})
+		cmd := New("bash", []string{"-c", "echo 'a test' | tee /dev/stderr"})
 		cmd.Env = map[string]string{"MYVAR": "my var value"}
 
 		var stdout strings.Builder
@@ -87,7 +121,7 @@ func TestRun_Command(t *testing.T) {
 	})
 
 	t.Run("returns error when non-zero exit code", func(t *testing.T) {
-		cmd := New("exit 12", []string{})
+		cmd := New("bash", []string{"-c", "exit 12"})
 
 		var stdout strings.Builder
 		cmd.Stdout = &stdout
@@ -133,6 +167,18 @@ func TestRun_Expression(t *testing.T) {
 		assert.Equal(t, "test string another string\n", stdout.String())
 	})
 
+	t.Run("args are passed to expression without interpretation", func(t *testing.T) {
+		args := []string{`"quoted"`, "$HOME", "`id`", "line\nbreak", "it's", ""}
+		cmd := NewExpression(`printf '%s\0' "$@"`, args)
+
+		var stdout strings.Builder
+		cmd.Stdout = &stdout
+		err := cmd.Run()
+
+		assert.Nil(t, err)
+		assert.Equal(t, args, splitArgs(stdout.String()))
+	})
+
 	t.Run("environment variables are passed to expression", func(t *testing.T) {
 		cmd := NewExpression("echo $MYVAR", []string{})
 		cmd.Env = map[string]string{"MYVAR": "my var value"}
@@ -173,3 +219,114 @@ func TestRun_Expression(t *testing.T) {
 		assert.Equal(t, 12, err.(*exec.ExitError).ExitCode())
 	})
 }
+
+func TestRunContext(t *testing.T) {
+	t.Run("returns timeout error when command runs longer than timeout", func(t *testing.T) {
+		cmd := NewExpression("sleep 10", []string{})
+		cmd.Timeout = 100 * time.Millisecond
+
+		start := time.Now()
+		err := cmd.RunContext(context.Background())
+
+		assert.Equal(t, TimeoutError{Timeout: 100 * time.Millisecond}, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("returns context error when context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		cmd := NewExpression("sleep 10", []string{})
+
+		err := cmd.RunContext(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("kills processes started by the command", func(t *testing.T) {
+		cmd := NewExpression("sleep 10 & echo $!; wait", []string{})
+		cmd.Timeout = 500 * time.Millisecond
+
+		var stdout strings.Builder
+		cmd.Stdout = &stdout
+		err := cmd.RunContext(context.Background())
+		assert.IsType(t, TimeoutError{}, err)
+
+		pid, err := strconv.Atoi(strings.TrimSpace(stdout.String()))
+		assert.Nil(t, err)
+		assert.Eventually(t, func() bool {
+			return syscall.Kill(pid, 0) != nil
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("sends SIGTERM before killing command", func(t *testing.T) {
+		cmd := NewExpression("trap 'echo terminated; exit 1' TERM; sleep 10 & wait", []string{})
+		cmd.Timeout = 100 * time.Millisecond
+
+		var stdout strings.Builder
+		cmd.Stdout = &stdout
+		err := cmd.RunContext(context.Background())
+
+		assert.IsType(t, TimeoutError{}, err)
+		assert.Equal(t, "terminated\n", stdout.String())
+	})
+
+	t.Run("kills command that ignores SIGTERM", func(t *testing.T) {
+		cmd := NewExpression("trap '' TERM; sleep 10", []string{})
+		cmd.Timeout = 100 * time.Millisecond
+
+		start := time.Now()
+		err := cmd.RunContext(context.Background())
+
+		assert.IsType(t, TimeoutError{}, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("runs command in its own process group only when it can be cancelled", func(t *testing.T) {
+		cmd := NewExpression("ps -o pgid= -p $$", []string{})
+		var stdout strings.Builder
+		cmd.Stdout = &stdout
+		assert.Nil(t, cmd.RunContext(context.Background()))
+		assert.Equal(t, strconv.Itoa(syscall.Getpgrp()), strings.TrimSpace(stdout.String()))
+
+		stdout.Reset()
+		cmd.Timeout = 5 * time.Second
+		assert.Nil(t, cmd.RunContext(context.Background()))
+		assert.NotEqual(t, strconv.Itoa(syscall.Getpgrp()), strings.TrimSpace(stdout.String()))
+	})
+
+	t.Run("does not time out when command finishes in time", func(t *testing.T) {
+		cmd := NewExpression("true", []string{})
+		cmd.Timeout = 5 * time.Second
+
+		assert.Nil(t, cmd.RunContext(context.Background()))
+	})
+}
+
+func FuzzRunArgs(f *testing.F) {
+	f.Add("test string", "")
+	f.Add(`"; exit 1; "`, "$(exit 1)")
+	f.Add("`exit 1`", "it's\n")
+	f.Add("\\", "${HOME}")
+
+	f.Fuzz(func(t *testing.T, arg1, arg2 string) {
+		// NUL can't be part of an argument passed to a process
+		if strings.ContainsRune(arg1+arg2, 0) {
+			t.Skip()
+		}
+		args := []string{arg1, arg2}
+
+		for _, cmd := range []Command{New("testdata/args", args), NewExpression(`printf '%s\0' "$@"`, args)} {
+			var stdout strings.Builder
+			cmd.Stdout = &stdout
+			err := cmd.Run()
+
+			assert.Nil(t, err)
+			assert.Equal(t, args, splitArgs(stdout.String()))
+		}
+	})
+}
+
+// splitArgs splits the NUL terminated arguments printed by testdata/args
+func splitArgs(output string) []string {
+	return strings.Split(strings.TrimSuffix(output, "\x00"), "\x00")
+}
@@ -0,0 +1,125 @@
+// Package fuzzy matches mistyped names, like plugin short names, against the
+// names they were probably meant to be.
+package fuzzy
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+const (
+	exactScore     = 100
+	prefixScore    = 80
+	substringScore = 60
+	// distanceScore is the score of a name one edit away from the term, each
+	// further edit takes distancePenalty off
+	distanceScore   = 50
+	distancePenalty = 10
+	maxDistance     = 3
+)
+
+// Score returns how well candidate matches term, ignoring case. A name equal
+// to, starting with or containing term scores highest, then names a few typos
+// away from term, fewer typos first. Zero means candidate doesn't match.
+func Score(term, candidate string) int {
+	term = strings.ToLower(term)
+	candidate = strings.ToLower(candidate)
+
+	switch {
+	case term == "":
+		return 0
+	case candidate == term:
+		return exactScore
+	case strings.HasPrefix(candidate, term):
+		return prefixScore
+	case strings.Contains(candidate, term):
+		return substringScore
+	}
+
+	// Allow about one typo for every three characters typed
+	distance := Distance(term, candidate)
+	if distance > min(max(len(term)/3, 1), maxDistance) {
+		return 0
+	}
+
+	return distanceScore - (distance-1)*distancePenalty
+}
+
+// Closest returns at most limit of candidates that match term, best matches
+// first
+func Closest(term string, candidates []string, limit int) []string {
+	type match struct {
+		name  string
+		score int
+	}
+
+	matches := []match{}
+	for _, candidate := range candidates {
+		if score := Score(term, candidate); score > 0 {
+			matches = append(matches, match{name: candidate, score: score})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b match) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), strings.Compare(a.name, b.name))
+	})
+
+	names := []string{}
+	for _, match := range matches[:min(len(matches), limit)] {
+		names = append(names, match.name)
+	}
+
+	return names
+}
+
+// DidYouMean returns a suggestion like "did you mean nodejs or deno?" for the
+// names given, or an empty string when there are none
+func DidYouMean(names []string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return "did you mean " + names[0] + "?"
+	}
+
+	return "did you mean " + strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1] + "?"
+}
+
+// Distance returns the number of characters that must be inserted, deleted,
+// substituted or swapped with their neighbour to turn a into b
+func Distance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+
+	// distances[i][j] is the distance between the first i runes of source and
+	// the first j runes of target
+	distances := make([][]int, len(source)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(target)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+
+			distances[i][j] = min(
+				distances[i-1][j]+1,
+				distances[i][j-1]+1,
+				distances[i-1][j-1]+cost,
+			)
+
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				distances[i][j] = min(distances[i][j], distances[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return distances[len(source)][len(target)]
+}
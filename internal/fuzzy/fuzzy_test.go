@@ -0,0 +1,55 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{a: "nodejs", b: "nodejs", distance: 0},
+		{a: "nodej", b: "nodejs", distance: 1},
+		{a: "ndoejs", b: "nodejs", distance: 1},
+		{a: "rubby", b: "ruby", distance: 1},
+		{a: "pyhton", b: "python", distance: 1},
+		{a: "golang", b: "erlang", distance: 2},
+		{a: "", b: "lua", distance: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.distance, Distance(tt.a, tt.b))
+			assert.Equal(t, tt.distance, Distance(tt.b, tt.a))
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	assert.Equal(t, exactScore, Score("NodeJS", "nodejs"))
+	assert.Equal(t, prefixScore, Score("node", "nodejs"))
+	assert.Equal(t, substringScore, Score("js", "nodejs"))
+	assert.Equal(t, distanceScore, Score("ndoejs", "nodejs"))
+	assert.Equal(t, distanceScore-distancePenalty, Score("ndejss", "nodejs"))
+	assert.Zero(t, Score("go", "lua"))
+	assert.Zero(t, Score("", "lua"))
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"deno", "nodejs", "node-build", "ruby", "lua"}
+
+	assert.Equal(t, []string{"nodejs"}, Closest("ndoejs", candidates, 3))
+	assert.Equal(t, []string{"node-build", "nodejs"}, Closest("node", candidates, 3))
+	assert.Equal(t, []string{"node-build"}, Closest("node", candidates, 1))
+	assert.Empty(t, Closest("python", candidates, 3))
+}
+
+func TestDidYouMean(t *testing.T) {
+	assert.Equal(t, "", DidYouMean(nil))
+	assert.Equal(t, "did you mean nodejs?", DidYouMean([]string{"nodejs"}))
+	assert.Equal(t, "did you mean nodejs or deno?", DidYouMean([]string{"nodejs", "deno"}))
+	assert.Equal(t, "did you mean nodejs, deno or bun?", DidYouMean([]string{"nodejs", "deno", "bun"}))
+}
@@ -3,16 +3,25 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // DefaultRemoteName for Git repositories in asdf
 const DefaultRemoteName = "origin"
 
+// commitSHA matches full and abbreviated commit SHAs
+var commitSHA = regexp.MustCompile("^[0-9a-f]{4,40}$")
+
 // Repoer is an interface for operations that can be applied to asdf plugins.
 // Right now we only support Git, but in the future we might have other
 // mechanisms to install and upgrade plugins. asdf doesn't require a plugin
@@ -20,12 +29,41 @@ const DefaultRemoteName = "origin"
 // and upgrade plugins. If other approaches are supported this will be
 // extracted into the `plugins` module.
 type Repoer interface {
+	Branch() (string, error)
 	Clone(pluginURL, ref string) error
+	DefaultBranch() (string, error)
 	Head() (string, error)
 	RemoteURL() (string, error)
+	Status() (Status, error)
 	Update(ref string) (string, string, string, error)
 }
 
+// Status describes a Git repository compared with its remote
+type Status struct {
+	// Branch is the current branch, it is empty when HEAD is detached
+	Branch string
+	// Ahead and Behind are the number of commits the current branch has that
+	// the remote branch doesn't, and the other way round
+	Ahead  int
+	Behind int
+	// Dirty is true when the working tree has local modifications
+	Dirty bool
+}
+
+// Commit is a commit in a plugin's Git repository
+type Commit struct {
+	SHA     string
+	Subject string
+	Author  string
+	Date    time.Time
+}
+
+// IsFullSHA returns true when ref is a full, rather than abbreviated, commit
+// SHA
+func IsFullSHA(ref string) bool {
+	return len(ref) == 40 && commitSHA.MatchString(ref)
+}
+
 // Repo is a struct to contain the Git repository details
 type Repo struct {
 	Directory string
@@ -37,28 +75,36 @@ func NewRepo(directory string) Repo {
 	return Repo{Directory: directory}
 }
 
-// Clone installs a plugin via Git
+// Clone installs a plugin via Git. ref may be a branch, a tag or a commit SHA,
+// abbreviated or not.
 func (r Repo) Clone(pluginURL, ref string) error {
 	options := git.CloneOptions{
-		URL: pluginURL,
+		URL:  pluginURL,
+		Tags: git.AllTags,
 	}
 
-	// if ref is provided set it on CloneOptions
-	if ref != "" {
-		options.ReferenceName = plumbing.NewBranchReferenceName(ref)
-	}
-
-	_, err := git.PlainClone(r.Directory, false, &options)
+	repo, err := git.PlainClone(r.Directory, false, &options)
 	if err != nil {
 		return fmt.Errorf("unable to clone plugin: %w", err)
 	}
 
+	if ref != "" {
+		err = checkoutRef(repo, ref)
+		if err != nil {
+			return fmt.Errorf("unable to clone plugin: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// Head returns the current HEAD ref of the plugin's Git repository
+// Head returns the current HEAD ref of the plugin's Git repository, or an
+// empty string when the plugin is not a Git repository
 func (r Repo) Head() (string, error) {
 	repo, err := gitOpen(r.Directory)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return "", nil
+	}
 	if err != nil {
 		return "", err
 	}
@@ -71,9 +117,90 @@ func (r Repo) Head() (string, error) {
 	return ref.Hash().String(), nil
 }
 
-// RemoteURL returns the URL of the default remote for the plugin's Git repository
+// Branch returns the name of the branch checked out in the plugin's Git
+// repository, or an empty string when HEAD is detached or the plugin is not a
+// Git repository
+func (r Repo) Branch() (string, error) {
+	repo, err := gitOpen(r.Directory)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	if !ref.Name().IsBranch() {
+		return "", nil
+	}
+
+	return ref.Name().Short(), nil
+}
+
+// DefaultBranch returns the name of the branch HEAD points to in the default
+// remote, the branch a fresh clone would be on
+func (r Repo) DefaultBranch() (string, error) {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return "", err
+	}
+
+	remote, err := repo.Remote(DefaultRemoteName)
+	if err != nil {
+		return "", err
+	}
+
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			return ref.Target().Short(), nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to find default branch of remote %s", DefaultRemoteName)
+}
+
+// IsDirty returns true when the working tree of the plugin's Git repository
+// contains changes that are not committed. Plugins that aren't Git
+// repositories are never dirty.
+func (r Repo) IsDirty() (bool, error) {
+	repo, err := gitOpen(r.Directory)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+
+	return !status.IsClean(), nil
+}
+
+// RemoteURL returns the URL of the default remote for the plugin's Git
+// repository, or an empty string when the plugin is not a Git repository or
+// has no remote
 func (r Repo) RemoteURL() (string, error) {
 	repo, err := gitOpen(r.Directory)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return "", nil
+	}
 	if err != nil {
 		return "", err
 	}
@@ -83,11 +210,165 @@ func (r Repo) RemoteURL() (string, error) {
 		return "", err
 	}
 
+	if len(remotes) == 0 || len(remotes[0].Config().URLs) == 0 {
+		return "", nil
+	}
+
 	return remotes[0].Config().URLs[0], nil
 }
 
+// IsRepository returns true when the plugin's directory is a Git repository
+func (r Repo) IsRepository() bool {
+	_, err := gitOpen(r.Directory)
+	return err == nil
+}
+
+// ResolveRef returns the commit SHA ref points to in the plugin's Git
+// repository without fetching from the remote
+func (r Repo) ResolveRef(ref string) (string, error) {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return "", err
+	}
+
+	hash, _, err := resolveRef(repo, ref)
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// Checkout checks out the commit SHA in the plugin's Git repository. When
+// branch is not empty the branch is reset to the commit and checked out,
+// otherwise the commit is checked out as a detached HEAD.
+func (r Repo) Checkout(sha, branch string) error {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return err
+	}
+
+	return checkout(repo, plumbing.NewHash(sha), branch)
+}
+
+// Status fetches the branches of the remote, without changing the working
+// tree, and compares the current branch with the remote branch of the same
+// name. Ahead and Behind are zero when HEAD is detached.
+func (r Repo) Status() (status Status, err error) {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return status, err
+	}
+
+	fetchOptions := git.FetchOptions{RemoteName: DefaultRemoteName, Force: true, RefSpecs: []config.RefSpec{
+		config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", DefaultRemoteName)),
+	}}
+	err = repo.Fetch(&fetchOptions)
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return status, fmt.Errorf("unable to fetch remote: %w", err)
+	}
+
+	status.Dirty, err = r.IsDirty()
+	if err != nil {
+		return status, err
+	}
+
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return status, err
+	}
+	status.Branch = head.Name().Short()
+
+	remote, err := repo.Reference(plumbing.NewRemoteReferenceName(DefaultRemoteName, status.Branch), true)
+	if err != nil {
+		return status, fmt.Errorf("branch %s does not exist on the remote", status.Branch)
+	}
+
+	local, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return status, err
+	}
+
+	upstream, err := repo.CommitObject(remote.Hash())
+	if err != nil {
+		return status, err
+	}
+
+	ahead, err := commitsBetween(upstream, local)
+	if err != nil {
+		return status, err
+	}
+
+	behind, err := commitsBetween(local, upstream)
+	status.Ahead, status.Behind = len(ahead), len(behind)
+	return status, err
+}
+
+// Log returns the commits reachable from to but not from from, newest first,
+// like git log from..to. from and to may be HEAD, a branch, a tag or a commit
+// SHA.
+func (r Repo) Log(from, to string) ([]Commit, error) {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	fromCommit, err := resolveCommit(repo, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toCommit, err := resolveCommit(repo, to)
+	if err != nil {
+		return nil, err
+	}
+
+	between, err := commitsBetween(fromCommit, toCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []Commit{}
+	for _, commit := range between {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		commits = append(commits, Commit{
+			SHA:     commit.Hash.String(),
+			Subject: strings.TrimSpace(subject),
+			Author:  commit.Author.Name,
+			Date:    commit.Committer.When,
+		})
+	}
+
+	// Pre-order puts the commits of merged branches after their merge commit
+	// rather than by date
+	slices.SortStableFunc(commits, func(a, b Commit) int { return b.Date.Compare(a.Date) })
+
+	return commits, nil
+}
+
+// commitsBetween returns the commits reachable from to but not from from
+func commitsBetween(from, to *object.Commit) (commits []*object.Commit, err error) {
+	excluded := map[plumbing.Hash]bool{}
+	err = object.NewCommitPreorderIter(from, nil, nil).ForEach(func(commit *object.Commit) error {
+		excluded[commit.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = object.NewCommitPreorderIter(to, excluded, nil).ForEach(func(commit *object.Commit) error {
+		commits = append(commits, commit)
+		return nil
+	})
+
+	return commits, err
+}
+
 // Update updates the plugin's Git repository to the ref if provided, or the
-// latest commit on the current branch
+// latest commit on the current branch. ref may be a branch, a tag or a commit
+// SHA, abbreviated or not. Updating the current branch also moves its
+// remote-tracking branch to the fetched commit.
 func (r Repo) Update(ref string) (string, string, string, error) {
 	repo, err := gitOpen(r.Directory)
 	if err != nil {
@@ -99,50 +380,157 @@ func (r Repo) Update(ref string) (string, string, string, error) {
 		return "", "", "", err
 	}
 
-	var checkoutOptions git.CheckoutOptions
-
 	if ref == "" {
-		// If no ref is provided checkout latest commit on current branch
-		head, err := repo.Head()
-		if err != nil {
-			return "", "", "", err
-		}
+		ref, err = updateBranch(repo)
+	} else {
+		err = updateToRef(repo, ref)
+	}
+	if err != nil {
+		return "", "", "", err
+	}
 
-		if !head.Name().IsBranch() {
-			return "", "", "", fmt.Errorf("not on a branch, unable to update")
-		}
+	newHash, err := repo.ResolveRevision(plumbing.Revision("HEAD"))
+	return ref, oldHash.String(), newHash.String(), err
+}
 
-		// If on a branch checkout the latest version of it from the remote
-		branch := head.Name()
-		ref = branch.String()
-		checkoutOptions = git.CheckoutOptions{Branch: branch, Force: true}
-	} else {
-		// Checkout ref if provided
-		checkoutOptions = git.CheckoutOptions{Hash: plumbing.NewHash(ref), Force: true}
+// updateBranch checks out the latest commit of the current branch and returns
+// the name of the branch
+func updateBranch(repo *git.Repository) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("not on a branch, unable to update")
 	}
 
+	// If on a branch checkout the latest version of it from the remote. The
+	// remote-tracking branch is updated too so refs resolve to the same commit.
+	branch := head.Name()
 	fetchOptions := git.FetchOptions{RemoteName: DefaultRemoteName, Force: true, RefSpecs: []config.RefSpec{
-		config.RefSpec(ref + ":" + ref),
+		config.RefSpec(branch.String() + ":" + branch.String()),
+		config.RefSpec(fmt.Sprintf("+%s:%s", branch, plumbing.NewRemoteReferenceName(DefaultRemoteName, branch.Short()))),
 	}}
 
 	err = repo.Fetch(&fetchOptions)
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return "", err
+	}
 
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	return branch.String(), worktree.Checkout(&git.CheckoutOptions{Branch: branch, Force: true})
+}
+
+// updateToRef fetches all branches and tags and checks out ref
+func updateToRef(repo *git.Repository, ref string) error {
+	fetchOptions := git.FetchOptions{RemoteName: DefaultRemoteName, Force: true, Tags: git.AllTags, RefSpecs: []config.RefSpec{
+		config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", DefaultRemoteName)),
+	}}
+
+	err := repo.Fetch(&fetchOptions)
 	if err != nil && err != git.NoErrAlreadyUpToDate {
-		return "", "", "", err
+		return err
 	}
 
+	return checkoutRef(repo, ref)
+}
+
+// checkoutRef checks out ref. A branch is checked out as a local branch at the
+// commit of the remote branch so later updates follow it, tags and commits are
+// checked out as a detached HEAD.
+func checkoutRef(repo *git.Repository, ref string) error {
+	hash, branch, err := resolveRef(repo, ref)
+	if err != nil {
+		return err
+	}
+
+	return checkout(repo, hash, branch)
+}
+
+// checkout checks out the commit hash. When branch is set the local branch is
+// moved to the commit and checked out, otherwise HEAD is detached.
+func checkout(repo *git.Repository, hash plumbing.Hash, branch string) error {
 	worktree, err := repo.Worktree()
 	if err != nil {
-		return "", "", "", err
+		return err
+	}
+
+	if branch == "" {
+		return worktree.Checkout(&git.CheckoutOptions{Hash: hash, Force: true})
 	}
 
-	err = worktree.Checkout(&checkoutOptions)
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	err = repo.Storer.SetReference(plumbing.NewHashReference(branchRef, hash))
 	if err != nil {
-		return "", "", "", err
+		return err
 	}
 
-	newHash, err := repo.ResolveRevision(plumbing.Revision("HEAD"))
-	return ref, oldHash.String(), newHash.String(), err
+	return worktree.Checkout(&git.CheckoutOptions{Branch: branchRef, Force: true})
+}
+
+// resolveRef returns the commit ref points to, trying branches first, then
+// tags, then commit SHAs. branch is the name of the branch when ref is one.
+func resolveRef(repo *git.Repository, ref string) (hash plumbing.Hash, branch string, err error) {
+	for _, name := range []plumbing.ReferenceName{
+		plumbing.NewRemoteReferenceName(DefaultRemoteName, ref),
+		plumbing.NewBranchReferenceName(ref),
+	} {
+		reference, err := repo.Reference(name, true)
+		if err == nil {
+			return reference.Hash(), ref, nil
+		}
+	}
+
+	tag, err := repo.Tag(ref)
+	if err == nil {
+		// Annotated tags point to a tag object rather than a commit
+		tagObject, err := repo.TagObject(tag.Hash())
+		if err != nil {
+			return tag.Hash(), "", nil
+		}
+
+		commit, err := tagObject.Commit()
+		if err != nil {
+			return hash, "", err
+		}
+
+		return commit.Hash, "", nil
+	}
+
+	if commitSHA.MatchString(ref) {
+		resolved, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err == nil {
+			return *resolved, "", nil
+		}
+	}
+
+	return hash, "", fmt.Errorf("reference not found: no branch, tag or commit named %q", ref)
+}
+
+// resolveCommit returns the commit ref points to. ref may be HEAD or anything
+// resolveRef accepts.
+func resolveCommit(repo *git.Repository, ref string) (*object.Commit, error) {
+	var hash plumbing.Hash
+	if ref == "HEAD" {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, err
+		}
+		hash = head.Hash()
+	} else {
+		var err error
+		hash, _, err = resolveRef(repo, ref)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return repo.CommitObject(hash)
 }
 
 func gitOpen(directory string) (*git.Repository, error) {
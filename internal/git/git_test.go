@@ -4,13 +4,21 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/asdf-vm/asdf/internal/repotest"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestIsFullSHA(t *testing.T) {
+	assert.True(t, IsFullSHA("0123456789abcdef0123456789abcdef01234567"))
+	assert.False(t, IsFullSHA("0123456"))
+	assert.False(t, IsFullSHA("master"))
+}
+
 func TestRepoClone(t *testing.T) {
 	t.Run("when repo name is valid but URL is invalid prints an error", func(t *testing.T) {
 		repo := NewRepo(t.TempDir())
@@ -78,6 +86,84 @@ func TestRepoHead(t *testing.T) {
 	assert.NotZero(t, head)
 }
 
+func TestRepoWithoutGitRepository(t *testing.T) {
+	repo := NewRepo(t.TempDir())
+
+	head, err := repo.Head()
+	assert.Nil(t, err)
+	assert.Empty(t, head)
+
+	url, err := repo.RemoteURL()
+	assert.Nil(t, err)
+	assert.Empty(t, url)
+
+	branch, err := repo.Branch()
+	assert.Nil(t, err)
+	assert.Empty(t, branch)
+
+	dirty, err := repo.IsDirty()
+	assert.Nil(t, err)
+	assert.False(t, dirty)
+
+	assert.False(t, repo.IsRepository())
+}
+
+func TestRepoBranch(t *testing.T) {
+	repoDir := generateRepo(t)
+	directory := t.TempDir()
+
+	repo := NewRepo(directory)
+
+	err := repo.Clone(repoDir, "")
+	assert.Nil(t, err)
+
+	branch, err := repo.Branch()
+	assert.Nil(t, err)
+	assert.Equal(t, "master", branch)
+}
+
+func TestRepoDefaultBranch(t *testing.T) {
+	t.Run("returns branch HEAD of remote points to when HEAD is detached", func(t *testing.T) {
+		repoDir, repo := cloneRepo(t)
+		firstCommit := addRefs(t, repoDir)
+		assert.Nil(t, repo.Checkout(firstCommit, ""))
+
+		branch, err := repo.DefaultBranch()
+		assert.Nil(t, err)
+		assert.Equal(t, "master", branch)
+	})
+
+	t.Run("returns error when directory is not a Git repository", func(t *testing.T) {
+		_, err := NewRepo(t.TempDir()).DefaultBranch()
+		assert.ErrorContains(t, err, "repository does not exist")
+	})
+}
+
+func TestRepoIsDirty(t *testing.T) {
+	repoDir := generateRepo(t)
+	directory := t.TempDir()
+
+	repo := NewRepo(directory)
+
+	err := repo.Clone(repoDir, "")
+	assert.Nil(t, err)
+
+	t.Run("returns false when working tree is clean", func(t *testing.T) {
+		dirty, err := repo.IsDirty()
+		assert.Nil(t, err)
+		assert.False(t, dirty)
+	})
+
+	t.Run("returns true when working tree has changes", func(t *testing.T) {
+		err := os.WriteFile(filepath.Join(directory, "bin", "list-all"), []byte("echo 1.0.0"), 0o777)
+		assert.Nil(t, err)
+
+		dirty, err := repo.IsDirty()
+		assert.Nil(t, err)
+		assert.True(t, dirty)
+	})
+}
+
 func TestRepoRemoteURL(t *testing.T) {
 	repoDir := generateRepo(t)
 	directory := t.TempDir()
@@ -157,7 +243,7 @@ func TestRepoUpdate(t *testing.T) {
 		ref := "non-existant"
 		updatedToRef, _, _, err := repo.Update(ref)
 		assert.Equal(t, updatedToRef, "")
-		expectedErrMsg := "couldn't find remote ref \"non-existant\""
+		expectedErrMsg := "reference not found: no branch, tag or commit named \"non-existant\""
 		assert.ErrorContains(t, err, expectedErrMsg)
 	})
 
@@ -177,6 +263,271 @@ func TestRepoUpdate(t *testing.T) {
 		assert.Equal(t, hash, latestHash)
 		assert.Equal(t, newHash, latestHash)
 	})
+
+	t.Run("updates remote-tracking branch with current branch", func(t *testing.T) {
+		commitEmpty(t, repoDir)
+		newHash, err := getCurrentCommit(repoDir)
+		assert.Nil(t, err)
+
+		_, _, _, err = repo.Update("")
+		assert.Nil(t, err)
+
+		resolved, err := repo.ResolveRef("master")
+		assert.Nil(t, err)
+		assert.Equal(t, newHash, resolved)
+	})
+}
+
+func TestRepoCloneRef(t *testing.T) {
+	repoDir := generateRepo(t)
+	firstCommit := addRefs(t, repoDir)
+
+	tests := []struct {
+		desc       string
+		ref        string
+		wantBranch string
+	}{
+		{desc: "branch", ref: "feature", wantBranch: "feature"},
+		{desc: "lightweight tag", ref: "v1.0.0"},
+		{desc: "annotated tag", ref: "v2.0.0"},
+		{desc: "commit SHA", ref: firstCommit},
+		{desc: "short commit SHA", ref: firstCommit[:7]},
+	}
+
+	for _, tt := range tests {
+		t.Run("checks out "+tt.desc, func(t *testing.T) {
+			directory := t.TempDir()
+			repo := NewRepo(directory)
+
+			err := repo.Clone(repoDir, tt.ref)
+			assert.Nil(t, err)
+
+			head, err := repo.Head()
+			assert.Nil(t, err)
+			assert.Equal(t, firstCommit, head)
+
+			branch, err := repo.Branch()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantBranch, branch)
+		})
+	}
+}
+
+func TestRepoUpdateRef(t *testing.T) {
+	repoDir := generateRepo(t)
+	firstCommit := addRefs(t, repoDir)
+	latestCommit, err := getCurrentCommit(repoDir)
+	assert.Nil(t, err)
+
+	directory := t.TempDir()
+	repo := NewRepo(directory)
+	assert.Nil(t, repo.Clone(repoDir, ""))
+
+	t.Run("updates to tag", func(t *testing.T) {
+		updatedToRef, oldHash, newHash, err := repo.Update("v1.0.0")
+		assert.Nil(t, err)
+		assert.Equal(t, "v1.0.0", updatedToRef)
+		assert.Equal(t, latestCommit, oldHash)
+		assert.Equal(t, firstCommit, newHash)
+	})
+
+	t.Run("updates to branch", func(t *testing.T) {
+		_, _, newHash, err := repo.Update("master")
+		assert.Nil(t, err)
+		assert.Equal(t, latestCommit, newHash)
+
+		branch, err := repo.Branch()
+		assert.Nil(t, err)
+		assert.Equal(t, "master", branch)
+	})
+
+	t.Run("updates to short commit SHA", func(t *testing.T) {
+		_, _, newHash, err := repo.Update(firstCommit[:7])
+		assert.Nil(t, err)
+		assert.Equal(t, firstCommit, newHash)
+	})
+
+	t.Run("updates to tag created after clone", func(t *testing.T) {
+		source, err := git.PlainOpen(repoDir)
+		assert.Nil(t, err)
+		_, err = source.CreateTag("v3.0.0", plumbing.NewHash(latestCommit), nil)
+		assert.Nil(t, err)
+
+		_, _, newHash, err := repo.Update("v3.0.0")
+		assert.Nil(t, err)
+		assert.Equal(t, latestCommit, newHash)
+	})
+
+	t.Run("follows branch checked out by ref on later updates", func(t *testing.T) {
+		_, _, _, err := repo.Update("feature")
+		assert.Nil(t, err)
+
+		updatedToRef, _, newHash, err := repo.Update("")
+		assert.Nil(t, err)
+		assert.Equal(t, "refs/heads/feature", updatedToRef)
+		assert.Equal(t, firstCommit, newHash)
+	})
+}
+
+func TestRepoResolveRef(t *testing.T) {
+	repoDir := generateRepo(t)
+	firstCommit := addRefs(t, repoDir)
+	latestCommit, err := getCurrentCommit(repoDir)
+	assert.Nil(t, err)
+
+	directory := t.TempDir()
+	repo := NewRepo(directory)
+	assert.Nil(t, repo.Clone(repoDir, ""))
+
+	for ref, expected := range map[string]string{
+		"master":          latestCommit,
+		"v1.0.0":          firstCommit,
+		"v2.0.0":          firstCommit,
+		firstCommit[:7]:   firstCommit,
+		latestCommit[:10]: latestCommit,
+	} {
+		hash, err := repo.ResolveRef(ref)
+		assert.Nil(t, err, ref)
+		assert.Equal(t, expected, hash, ref)
+	}
+
+	_, err = repo.ResolveRef("non-existent")
+	assert.ErrorContains(t, err, "reference not found")
+}
+
+func TestRepoLog(t *testing.T) {
+	repoDir := generateRepo(t)
+	firstCommit := addRefs(t, repoDir)
+	latestCommit, err := getCurrentCommit(repoDir)
+	assert.Nil(t, err)
+
+	repo := NewRepo(repoDir)
+
+	t.Run("returns commits between refs", func(t *testing.T) {
+		commits, err := repo.Log(firstCommit[:7], "HEAD")
+		assert.Nil(t, err)
+		assert.Len(t, commits, 1)
+		assert.Equal(t, latestCommit, commits[0].SHA)
+		assert.Equal(t, "add readme", commits[0].Subject)
+		assert.NotEmpty(t, commits[0].Author)
+	})
+
+	t.Run("accepts tags and branches", func(t *testing.T) {
+		commits, err := repo.Log("v2.0.0", "master")
+		assert.Nil(t, err)
+		assert.Len(t, commits, 1)
+	})
+
+	t.Run("returns no commits when to is an ancestor of from", func(t *testing.T) {
+		commits, err := repo.Log("master", "v1.0.0")
+		assert.Nil(t, err)
+		assert.Empty(t, commits)
+	})
+
+	t.Run("returns error when ref does not exist", func(t *testing.T) {
+		_, err := repo.Log("non-existent", "HEAD")
+		assert.ErrorContains(t, err, "reference not found")
+	})
+}
+
+func TestRepoStatus(t *testing.T) {
+	t.Run("returns branch without changes when up to date", func(t *testing.T) {
+		_, repo := cloneRepo(t)
+
+		status, err := repo.Status()
+		assert.Nil(t, err)
+		assert.Equal(t, Status{Branch: "master"}, status)
+	})
+
+	t.Run("returns commits behind remote after fetching", func(t *testing.T) {
+		repoDir, repo := cloneRepo(t)
+		commitEmpty(t, repoDir)
+		commitEmpty(t, repoDir)
+		head, err := repo.Head()
+		assert.Nil(t, err)
+
+		status, err := repo.Status()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, status.Behind)
+		assert.Equal(t, 0, status.Ahead)
+
+		// Fetching must not change the working tree
+		newHead, err := repo.Head()
+		assert.Nil(t, err)
+		assert.Equal(t, head, newHead)
+	})
+
+	t.Run("returns commits ahead of remote and local modifications", func(t *testing.T) {
+		_, repo := cloneRepo(t)
+		commitEmpty(t, repo.Directory)
+		assert.Nil(t, os.WriteFile(filepath.Join(repo.Directory, "README.md"), []byte("changed"), 0o666))
+
+		status, err := repo.Status()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, status.Ahead)
+		assert.Equal(t, 0, status.Behind)
+		assert.True(t, status.Dirty)
+	})
+
+	t.Run("returns no branch when HEAD is detached", func(t *testing.T) {
+		repoDir, repo := cloneRepo(t)
+		firstCommit := addRefs(t, repoDir)
+		assert.Nil(t, repo.Checkout(firstCommit, ""))
+
+		status, err := repo.Status()
+		assert.Nil(t, err)
+		assert.Equal(t, Status{}, status)
+	})
+
+	t.Run("returns error when directory is not a Git repository", func(t *testing.T) {
+		_, err := NewRepo(t.TempDir()).Status()
+		assert.ErrorContains(t, err, "repository does not exist")
+	})
+}
+
+// cloneRepo clones a new repository and returns the path of the original
+func cloneRepo(t *testing.T) (string, Repo) {
+	t.Helper()
+	repoDir := generateRepo(t)
+	repo := NewRepo(t.TempDir())
+	assert.Nil(t, repo.Clone(repoDir, ""))
+
+	return repoDir, repo
+}
+
+func commitEmpty(t *testing.T, path string) {
+	t.Helper()
+	repo, err := git.PlainOpen(path)
+	assert.Nil(t, err)
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	_, err = worktree.Commit("empty", &git.CommitOptions{Author: signature, AllowEmptyCommits: true})
+	assert.Nil(t, err)
+}
+
+// addRefs adds a feature branch, a lightweight tag v1.0.0 and an annotated tag
+// v2.0.0 pointing to the first commit of the repository and returns the SHA
+// of that commit
+func addRefs(t *testing.T, path string) string {
+	t.Helper()
+	repo, err := git.PlainOpen(path)
+	assert.Nil(t, err)
+
+	hash, err := repo.ResolveRevision(plumbing.Revision("HEAD~"))
+	assert.Nil(t, err)
+
+	err = repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("feature"), *hash))
+	assert.Nil(t, err)
+
+	_, err = repo.CreateTag("v1.0.0", *hash, nil)
+	assert.Nil(t, err)
+
+	tagger := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	_, err = repo.CreateTag("v2.0.0", *hash, &git.CreateTagOptions{Tagger: tagger, Message: "v2.0.0"})
+	assert.Nil(t, err)
+
+	return hash.String()
 }
 
 func getCurrentCommit(path string) (string, error) {
@@ -226,3 +577,36 @@ func generateRepo(t *testing.T) string {
 	assert.Nil(t, err)
 	return path
 }
+
+func TestRepoCheckout(t *testing.T) {
+	repoDir := generateRepo(t)
+	firstCommit := addRefs(t, repoDir)
+	latestCommit, err := getCurrentCommit(repoDir)
+	assert.Nil(t, err)
+
+	directory := t.TempDir()
+	repo := NewRepo(directory)
+	assert.Nil(t, repo.Clone(repoDir, ""))
+
+	t.Run("checks out commit as detached HEAD", func(t *testing.T) {
+		assert.Nil(t, repo.Checkout(firstCommit, ""))
+
+		head, err := repo.Head()
+		assert.Nil(t, err)
+		assert.Equal(t, firstCommit, head)
+		branch, err := repo.Branch()
+		assert.Nil(t, err)
+		assert.Empty(t, branch)
+	})
+
+	t.Run("resets branch to commit", func(t *testing.T) {
+		assert.Nil(t, repo.Checkout(latestCommit, "master"))
+
+		head, err := repo.Head()
+		assert.Nil(t, err)
+		assert.Equal(t, latestCommit, head)
+		branch, err := repo.Branch()
+		assert.Nil(t, err)
+		assert.Equal(t, "master", branch)
+	})
+}
@@ -0,0 +1,215 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSignatureNamespace is the namespace Git signs commits in, a signature
+// made for another purpose must not verify a commit
+const sshSignatureNamespace = "git"
+
+// Signers are the keys commits may be signed with
+type Signers struct {
+	// GPGKeyRing is an armored OpenPGP keyring
+	GPGKeyRing string
+	// SSH are the keys of an SSH allowed signers file
+	SSH []AllowedSigner
+}
+
+// AllowedSigner is a line of an SSH allowed signers file, the format of Git's
+// gpg.ssh.allowedSignersFile
+type AllowedSigner struct {
+	Principals string
+	Key        ssh.PublicKey
+	// Namespaces are the namespaces the key may sign in, any namespace when
+	// empty
+	Namespaces []string
+}
+
+// ParseAllowedSigners parses an SSH allowed signers file. Each line holds
+// comma separated principals, optional options and a public key. Blank lines
+// and lines starting with # are ignored.
+func ParseAllowedSigners(content []byte) (signers []AllowedSigner, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		principals, rest, _ := strings.Cut(line, " ")
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(rest))
+		if err != nil {
+			return signers, fmt.Errorf("invalid allowed signer on line %d: %w", number, err)
+		}
+
+		signer := AllowedSigner{Principals: principals, Key: key}
+		for _, option := range options {
+			if value, ok := strings.CutPrefix(option, "namespaces="); ok {
+				signer.Namespaces = strings.Split(strings.Trim(value, `"`), ",")
+			}
+		}
+		signers = append(signers, signer)
+	}
+
+	return signers, scanner.Err()
+}
+
+// VerifySignature checks that the commit ref points to is signed with one of
+// signers and returns who signed it: the GPG identity or the principals of the
+// SSH key. ref may be HEAD or a branch, tag or commit SHA.
+func (r Repo) VerifySignature(ref string, signers Signers) (string, error) {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := resolveCommit(repo, ref)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case commit.PGPSignature == "":
+		return "", fmt.Errorf("commit %s is not signed", commit.Hash)
+	case strings.HasPrefix(commit.PGPSignature, "-----BEGIN SSH SIGNATURE-----"):
+		return verifySSHSignature(commit, signers.SSH)
+	default:
+		return verifyGPGSignature(commit, signers.GPGKeyRing)
+	}
+}
+
+func verifyGPGSignature(commit *object.Commit, keyRing string) (string, error) {
+	if keyRing == "" {
+		return "", fmt.Errorf("commit %s is signed with GPG but no GPG keys are allowed", commit.Hash)
+	}
+
+	entity, err := commit.Verify(keyRing)
+	if err != nil {
+		return "", fmt.Errorf("commit %s is not signed with an allowed GPG key: %w", commit.Hash, err)
+	}
+
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		return identity.Name, nil
+	}
+
+	return entity.PrimaryKey.KeyIdString(), nil
+}
+
+// sshSignature is the binary format of an SSH signature, described in
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+type sshSignature struct {
+	MagicPreamble [6]byte
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData is what an SSH signature signs
+type sshSignedData struct {
+	MagicPreamble [6]byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+func verifySSHSignature(commit *object.Commit, signers []AllowedSigner) (string, error) {
+	block, _ := pem.Decode([]byte(commit.PGPSignature))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return "", fmt.Errorf("commit %s has a malformed SSH signature", commit.Hash)
+	}
+
+	var signature sshSignature
+	err := ssh.Unmarshal(block.Bytes, &signature)
+	if err != nil || string(signature.MagicPreamble[:]) != "SSHSIG" || signature.Version != 1 {
+		return "", fmt.Errorf("commit %s has a malformed SSH signature", commit.Hash)
+	}
+
+	if signature.Namespace != sshSignatureNamespace {
+		return "", fmt.Errorf("commit %s is signed in namespace %q rather than %q", commit.Hash, signature.Namespace, sshSignatureNamespace)
+	}
+
+	key, err := ssh.ParsePublicKey(signature.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("commit %s has a malformed SSH signature: %w", commit.Hash, err)
+	}
+
+	index := slices.IndexFunc(signers, func(signer AllowedSigner) bool {
+		return bytes.Equal(signer.Key.Marshal(), key.Marshal()) &&
+			(len(signer.Namespaces) == 0 || slices.Contains(signer.Namespaces, sshSignatureNamespace))
+	})
+	if index == -1 {
+		return "", fmt.Errorf("commit %s is signed with SSH key %s which is not an allowed signer", commit.Hash, ssh.FingerprintSHA256(key))
+	}
+
+	var newHash func() hash.Hash
+	switch signature.HashAlgorithm {
+	case "sha256":
+		newHash = sha256.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return "", fmt.Errorf("commit %s is signed with unsupported hash algorithm %q", commit.Hash, signature.HashAlgorithm)
+	}
+
+	message, err := unsignedCommit(commit)
+	if err != nil {
+		return "", err
+	}
+	digest := newHash()
+	digest.Write(message)
+
+	signed := ssh.Marshal(sshSignedData{
+		MagicPreamble: signature.MagicPreamble,
+		Namespace:     signature.Namespace,
+		Reserved:      signature.Reserved,
+		HashAlgorithm: signature.HashAlgorithm,
+		Hash:          digest.Sum(nil),
+	})
+
+	var sshSig ssh.Signature
+	if err := ssh.Unmarshal(signature.Signature, &sshSig); err != nil {
+		return "", fmt.Errorf("commit %s has a malformed SSH signature: %w", commit.Hash, err)
+	}
+
+	if err := key.Verify(signed, &sshSig); err != nil {
+		return "", fmt.Errorf("commit %s has an invalid SSH signature: %w", commit.Hash, err)
+	}
+
+	return signers[index].Principals, nil
+}
+
+// unsignedCommit returns the commit object as it was before it was signed,
+// which is what its signature signs
+func unsignedCommit(commit *object.Commit) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	err := commit.EncodeWithoutSignature(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
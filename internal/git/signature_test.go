@@ -0,0 +1,210 @@
+package git
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseAllowedSigners(t *testing.T) {
+	signer := generateSSHSigner(t)
+	key := string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+	content := "# comment\n\nalice@example.com " + key + "bob@example.com,carol@example.com namespaces=\"file,git\" " + key
+
+	signers, err := ParseAllowedSigners([]byte(content))
+	assert.Nil(t, err)
+	assert.Len(t, signers, 2)
+	assert.Equal(t, "alice@example.com", signers[0].Principals)
+	assert.Equal(t, signer.PublicKey().Marshal(), signers[0].Key.Marshal())
+	assert.Empty(t, signers[0].Namespaces)
+	assert.Equal(t, "bob@example.com,carol@example.com", signers[1].Principals)
+	assert.Equal(t, []string{"file", "git"}, signers[1].Namespaces)
+
+	_, err = ParseAllowedSigners([]byte("alice@example.com not-a-key\n"))
+	assert.ErrorContains(t, err, "invalid allowed signer on line 1")
+}
+
+func TestRepoVerifySignature(t *testing.T) {
+	t.Run("returns error when commit is not signed", func(t *testing.T) {
+		repo := NewRepo(generateRepo(t))
+
+		_, err := repo.VerifySignature("HEAD", Signers{})
+		assert.ErrorContains(t, err, "is not signed")
+	})
+
+	t.Run("returns identity of allowed GPG key", func(t *testing.T) {
+		path := generateRepo(t)
+		entity := generateGPGEntity(t)
+		sha := commitSigned(t, path, &git.CommitOptions{SignKey: entity})
+
+		signer, err := NewRepo(path).VerifySignature(sha, Signers{GPGKeyRing: armoredPublicKey(t, entity)})
+		assert.Nil(t, err)
+		assert.Equal(t, "Test <test@example.com>", signer)
+	})
+
+	t.Run("returns error when GPG key is not allowed", func(t *testing.T) {
+		path := generateRepo(t)
+		sha := commitSigned(t, path, &git.CommitOptions{SignKey: generateGPGEntity(t)})
+
+		_, err := NewRepo(path).VerifySignature(sha, Signers{GPGKeyRing: armoredPublicKey(t, generateGPGEntity(t))})
+		assert.ErrorContains(t, err, "is not signed with an allowed GPG key")
+
+		_, err = NewRepo(path).VerifySignature(sha, Signers{})
+		assert.ErrorContains(t, err, "no GPG keys are allowed")
+	})
+
+	t.Run("returns principals of allowed SSH key", func(t *testing.T) {
+		path := generateRepo(t)
+		signer := generateSSHSigner(t)
+		sha := commitSSHSigned(t, path, signer, "git")
+
+		allowed := Signers{SSH: []AllowedSigner{{Principals: "test@example.com", Key: signer.PublicKey()}}}
+		principals, err := NewRepo(path).VerifySignature(sha, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "test@example.com", principals)
+	})
+
+	t.Run("returns error when SSH key is not allowed", func(t *testing.T) {
+		path := generateRepo(t)
+		signer := generateSSHSigner(t)
+		sha := commitSSHSigned(t, path, signer, "git")
+
+		allowed := Signers{SSH: []AllowedSigner{{Principals: "test@example.com", Key: generateSSHSigner(t).PublicKey()}}}
+		_, err := NewRepo(path).VerifySignature(sha, allowed)
+		assert.ErrorContains(t, err, "which is not an allowed signer")
+
+		// The key is only allowed to sign files
+		allowed = Signers{SSH: []AllowedSigner{{Principals: "test@example.com", Key: signer.PublicKey(), Namespaces: []string{"file"}}}}
+		_, err = NewRepo(path).VerifySignature(sha, allowed)
+		assert.ErrorContains(t, err, "which is not an allowed signer")
+	})
+
+	t.Run("returns error when SSH signature is for another namespace", func(t *testing.T) {
+		path := generateRepo(t)
+		signer := generateSSHSigner(t)
+		sha := commitSSHSigned(t, path, signer, "file")
+
+		allowed := Signers{SSH: []AllowedSigner{{Principals: "test@example.com", Key: signer.PublicKey()}}}
+		_, err := NewRepo(path).VerifySignature(sha, allowed)
+		assert.ErrorContains(t, err, `is signed in namespace "file"`)
+	})
+
+	t.Run("returns error when SSH signed commit was modified", func(t *testing.T) {
+		path := generateRepo(t)
+		signer := generateSSHSigner(t)
+		repo, err := git.PlainOpen(path)
+		assert.Nil(t, err)
+		commit, err := repo.CommitObject(plumbing.NewHash(commitSSHSigned(t, path, signer, "git")))
+		assert.Nil(t, err)
+
+		commit.Message = "tampered"
+		sha := storeCommit(t, repo, commit)
+
+		allowed := Signers{SSH: []AllowedSigner{{Principals: "test@example.com", Key: signer.PublicKey()}}}
+		_, err = NewRepo(path).VerifySignature(sha, allowed)
+		assert.ErrorContains(t, err, "has an invalid SSH signature")
+	})
+}
+
+func generateGPGEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Test", "", "test@example.com", nil)
+	assert.Nil(t, err)
+	return entity
+}
+
+func armoredPublicKey(t *testing.T, entity *openpgp.Entity) string {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer, err := armor.Encode(&buffer, openpgp.PublicKeyType, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, entity.Serialize(writer))
+	assert.Nil(t, writer.Close())
+	return buffer.String()
+}
+
+func generateSSHSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	assert.Nil(t, err)
+	return signer
+}
+
+// commitSigned commits to the repository at path with options and returns the
+// SHA of the commit
+func commitSigned(t *testing.T, path string, options *git.CommitOptions) string {
+	t.Helper()
+	repo, err := git.PlainOpen(path)
+	assert.Nil(t, err)
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+	options.Author = &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	options.AllowEmptyCommits = true
+	hash, err := worktree.Commit("signed", options)
+	assert.Nil(t, err)
+	return hash.String()
+}
+
+// commitSSHSigned adds a commit on top of HEAD signed by signer in namespace,
+// like git commit -S with gpg.format set to ssh, and returns its SHA
+func commitSSHSigned(t *testing.T, path string, signer ssh.Signer, namespace string) string {
+	t.Helper()
+	repo, err := git.PlainOpen(path)
+	assert.Nil(t, err)
+	head, err := repo.Head()
+	assert.Nil(t, err)
+	parent, err := repo.CommitObject(head.Hash())
+	assert.Nil(t, err)
+
+	author := object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	commit := &object.Commit{
+		Author:       author,
+		Committer:    author,
+		Message:      "signed",
+		TreeHash:     parent.TreeHash,
+		ParentHashes: []plumbing.Hash{parent.Hash},
+	}
+
+	message, err := unsignedCommit(commit)
+	assert.Nil(t, err)
+	digest := sha512.Sum512(message)
+	magic := [6]byte{'S', 'S', 'H', 'S', 'I', 'G'}
+	signed := ssh.Marshal(sshSignedData{MagicPreamble: magic, Namespace: namespace, HashAlgorithm: "sha512", Hash: digest[:]})
+	signature, err := signer.Sign(rand.Reader, signed)
+	assert.Nil(t, err)
+
+	blob := ssh.Marshal(sshSignature{
+		MagicPreamble: magic,
+		Version:       1,
+		PublicKey:     signer.PublicKey().Marshal(),
+		Namespace:     namespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(signature),
+	})
+	commit.PGPSignature = string(pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob}))
+
+	return storeCommit(t, repo, commit)
+}
+
+func storeCommit(t *testing.T, repo *git.Repository, commit *object.Commit) string {
+	t.Helper()
+	encoded := repo.Storer.NewEncodedObject()
+	assert.Nil(t, commit.Encode(encoded))
+	hash, err := repo.Storer.SetEncodedObject(encoded)
+	assert.Nil(t, err)
+	return hash.String()
+}
@@ -90,8 +90,9 @@ func WriteToolVersionHelp(conf config.Config, toolName, toolVersion string, writ
 	return writePluginHelp(conf, toolName, toolVersion, writer, errWriter)
 }
 
-func writePluginHelp(conf config.Config, toolName, toolVersion string, writer io.Writer, errWriter io.Writer) error {
-	plugin := plugins.New(conf, toolName)
+// CallbackEnv returns the environment the help callbacks of plugin are run
+// with, for toolVersion when it is not empty
+func CallbackEnv(plugin plugins.Plugin, toolVersion string) map[string]string {
 	env := map[string]string{
 		"ASDF_INSTALL_PATH": plugin.Dir,
 	}
@@ -102,12 +103,19 @@ func writePluginHelp(conf config.Config, toolName, toolVersion string, writer io
 		env["ASDF_INSTALL_TYPE"] = version.Type
 	}
 
+	return env
+}
+
+func writePluginHelp(conf config.Config, toolName, toolVersion string, writer io.Writer, errWriter io.Writer) error {
+	plugin := plugins.New(conf, toolName)
+	env := CallbackEnv(plugin, toolVersion)
+
 	if err := plugin.Exists(); err != nil {
 		errWriter.Write([]byte(fmt.Sprintf("No plugin named %s\n", plugin.Name)))
 		return err
 	}
 
-	err := plugin.RunCallback("help.overview", []string{}, env, writer, errWriter)
+	err := plugin.RunCallback(conf, "help.overview", []string{}, env, writer, errWriter)
 	if _, ok := err.(plugins.NoCallbackError); ok {
 		// No such callback, print err msg
 		errWriter.Write([]byte(fmt.Sprintf("No documentation for plugin %s\n", plugin.Name)))
@@ -118,19 +126,11 @@ func writePluginHelp(conf config.Config, toolName, toolVersion string, writer io
 		return err
 	}
 
-	err = plugin.RunCallback("help.deps", []string{}, env, writer, errWriter)
-	if _, ok := err.(plugins.NoCallbackError); !ok {
-		return err
-	}
-
-	err = plugin.RunCallback("help.config", []string{}, env, writer, errWriter)
-	if _, ok := err.(plugins.NoCallbackError); !ok {
-		return err
-	}
-
-	err = plugin.RunCallback("help.links", []string{}, env, writer, errWriter)
-	if _, ok := err.(plugins.NoCallbackError); !ok {
-		return err
+	for _, callback := range []string{"help.deps", "help.config", "help.links"} {
+		err = plugin.RunCallback(conf, callback, []string{}, env, writer, errWriter)
+		if _, ok := err.(plugins.NoCallbackError); err != nil && !ok {
+			return err
+		}
 	}
 
 	return nil
@@ -145,14 +145,23 @@ func pluginExtensionCommands(plugins []plugins.Plugin) (string, error) {
 			return output.String(), err
 		}
 		if len(commands) > 0 {
+			manifest, _, err := plugin.Manifest()
+			if err != nil {
+				return output.String(), err
+			}
+
 			output.WriteString(fmt.Sprintf("PLUGIN %s\n", plugin.Name))
 			for _, command := range commands {
+				line := fmt.Sprintf("  asdf %s %s", plugin.Name, command)
 				if command == "" {
 					// must be default command
-					output.WriteString(fmt.Sprintf("  asdf %s\n", plugin.Name))
-				} else {
-					output.WriteString(fmt.Sprintf("  asdf %s %s\n", plugin.Name, command))
+					line = fmt.Sprintf("  asdf %s", plugin.Name)
+				}
+
+				if description := manifest.Commands[command]; description != "" {
+					line = fmt.Sprintf("%-39s %s", line, description)
 				}
+				output.WriteString(line + "\n")
 			}
 		}
 	}
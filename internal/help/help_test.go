@@ -60,6 +60,21 @@ func TestWriteToolHelp(t *testing.T) {
 		assert.Equal(t, stdout.String(), expected)
 	})
 
+	t.Run("when plugin implements optional help callbacks", func(t *testing.T) {
+		var stdout strings.Builder
+		var stderr strings.Builder
+		plugin := installPlugin(t, conf, "dummy_plugin", "documented")
+		for _, callback := range []string{"help.deps", "help.config", "help.links"} {
+			assert.Nil(t, repotest.WritePluginCallback(plugin.Dir, callback, "#!/usr/bin/env bash\necho "+callback))
+		}
+
+		err := WriteToolHelp(conf, plugin.Name, &stdout, &stderr)
+
+		assert.Nil(t, err)
+		assert.Empty(t, stderr.String())
+		assert.True(t, strings.HasSuffix(stdout.String(), "help.deps\nhelp.config\nhelp.links\n"))
+	})
+
 	t.Run("when plugin does not have help.overview callback", func(t *testing.T) {
 		var stdout strings.Builder
 		var stderr strings.Builder
@@ -3,6 +3,8 @@
 package hook
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"os"
 
@@ -19,6 +21,13 @@ func Run(conf config.Config, hookName string, arguments []string) error {
 // RunWithOutput gets a hook command from config and runs it with the provided
 // arguments. Output is sent to the provided io.Writers.
 func RunWithOutput(config config.Config, hookName string, arguments []string, stdOut io.Writer, stdErr io.Writer) error {
+	return RunWithOutputContext(context.Background(), config, hookName, arguments, stdOut, stdErr)
+}
+
+// RunWithOutputContext is like RunWithOutput but the hook command is killed
+// when ctx is done or when it runs for longer than the hook_timeout setting
+// allows.
+func RunWithOutputContext(ctx context.Context, config config.Config, hookName string, arguments []string, stdOut io.Writer, stdErr io.Writer) error {
 	hookCmd, err := config.GetHook(hookName)
 	if err != nil {
 		return err
@@ -33,5 +42,15 @@ func RunWithOutput(config config.Config, hookName string, arguments []string, st
 	cmd.Stdout = stdOut
 	cmd.Stderr = stdErr
 
-	return cmd.Run()
+	cmd.Timeout, err = config.HookTimeout()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RunContext(ctx)
+	if _, ok := err.(execute.TimeoutError); ok {
+		return fmt.Errorf("hook %s %w", hookName, err)
+	}
+
+	return err
 }
@@ -45,7 +45,7 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionType, v
 		return fmt.Errorf("unable to create download dir: %w", err)
 	}
 
-	err = plugin.RunCallback("download", []string{}, env, &stdOut, &stdErr)
+	err = plugin.RunCallback(conf, "download", []string{}, env, &stdOut, &stdErr)
 	if _, ok := err.(plugins.NoCallbackError); err != nil && !ok {
 		return fmt.Errorf("failed to run download callback: %w", err)
 	}
@@ -55,7 +55,7 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionType, v
 		return fmt.Errorf("unable to create install dir: %w", err)
 	}
 
-	err = plugin.RunCallback("install", []string{}, env, &stdOut, &stdErr)
+	err = plugin.RunCallback(conf, "install", []string{}, env, &stdOut, &stdErr)
 	if err != nil {
 		return fmt.Errorf("failed to run install callback: %w", err)
 	}
@@ -0,0 +1,161 @@
+package pluginindex
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/fuzzy"
+	"github.com/asdf-vm/asdf/internal/git"
+)
+
+// maxSuggestions is how many plugin names are suggested for a plugin that
+// isn't listed
+const maxSuggestions = 3
+
+// remoteSchemes are the URL schemes of remote Git repositories
+var remoteSchemes = []string{"https://", "http://", "ssh://", "git://"}
+
+// scpURL matches the scp-like syntax Git accepts for SSH URLs, like
+// git@github.com:asdf-vm/asdf-ruby.git
+var scpURL = regexp.MustCompile(`^[[:alnum:]_.-]+@[[:alnum:]_.-]+:`)
+
+// Index is a plugin index, a PluginIndex or a JSONIndex
+type Index interface {
+	Name() string
+	Get() ([]Plugin, error)
+	GetPluginSourceURL(name string) (string, error)
+	Update() error
+	Status() (IndexStatus, error)
+}
+
+// IndexStatus describes the copy of a plugin index on disk
+type IndexStatus struct {
+	Name string
+	URL  string
+	// Commit is the commit a Git index is at, it is empty for JSON indexes
+	// and indexes that were never synced
+	Commit string
+	// Pinned is the commit a Git index is pinned to, if any
+	Pinned string
+	// Updated is when the index was last synced, it is the zero time when it
+	// never was
+	Updated time.Time
+}
+
+// Indexes are plugin indexes in order of priority. A plugin listed on several
+// indexes is looked up on the first of them.
+type Indexes []Index
+
+// BuildIndexes returns the indexes sources list, in the same order. The
+// default index is kept where Build keeps it, other indexes are kept in a
+// directory of their own in dataDir.
+func BuildIndexes(dataDir string, sources []config.PluginIndex, disableUpdate bool, updateDurationMinutes int) Indexes {
+	indexes := Indexes{}
+	for _, source := range sources {
+		directory := filepath.Join(dataDir, pluginIndexesDir, source.Name)
+		if source.Name == config.DefaultPluginIndexName {
+			directory = filepath.Join(dataDir, pluginIndexDir)
+		}
+
+		if IsJSONURL(source.URL) {
+			indexes = append(indexes, NewJSON(source.Name, directory, source.URL, disableUpdate, updateDurationMinutes))
+			continue
+		}
+
+		index := New(directory, source.URL, disableUpdate, updateDurationMinutes, &git.Repo{Directory: directory})
+		index.name = source.Name
+		indexes = append(indexes, index)
+	}
+
+	return indexes
+}
+
+// Get returns the plugins of all indexes sorted by name. A plugin listed on
+// several indexes is only returned from the first of them.
+func (i Indexes) Get() (plugins []Plugin, err error) {
+	for _, index := range i {
+		indexPlugins, err := index.Get()
+		if err != nil {
+			return plugins, i.wrapError(index, err)
+		}
+
+		for _, plugin := range indexPlugins {
+			if !slices.ContainsFunc(plugins, func(other Plugin) bool { return other.Name == plugin.Name }) {
+				plugins = append(plugins, plugin)
+			}
+		}
+	}
+
+	slices.SortStableFunc(plugins, func(a, b Plugin) int { return strings.Compare(a.Name, b.Name) })
+	return plugins, nil
+}
+
+// Find returns the index named name
+func (i Indexes) Find(name string) (Index, bool) {
+	position := slices.IndexFunc(i, func(index Index) bool { return index.Name() == name })
+	if position == -1 {
+		return nil, false
+	}
+
+	return i[position], true
+}
+
+// GetPluginSourceURL looks up a plugin by name on each index in turn and
+// returns the repository URL from the first index listing it. An index that
+// can't be refreshed is an error rather than skipped, so a plugin is never
+// silently taken from an index of lower priority. When no index lists the
+// plugin the error suggests the closest names they do list.
+//
+// Plugins can also be added from directories and archives on this machine,
+// which an index must not be able to point asdf at, so the URL has to be one
+// of a remote Git repository.
+func (i Indexes) GetPluginSourceURL(name string) (string, error) {
+	names := []string{}
+	for _, index := range i {
+		plugins, err := index.Get()
+		if err != nil {
+			return "", i.wrapError(index, err)
+		}
+
+		for _, plugin := range plugins {
+			if plugin.Name == name {
+				if !isRemoteURL(plugin.URL) {
+					return "", i.wrapError(index, fmt.Errorf("plugin %s has URL %s, which is not a remote Git repository", name, plugin.URL))
+				}
+				return plugin.URL, nil
+			}
+
+			if !slices.Contains(names, plugin.Name) {
+				names = append(names, plugin.Name)
+			}
+		}
+	}
+
+	return "", PluginNotFoundError{plugin: name, suggestions: fuzzy.Closest(name, names, maxSuggestions)}
+}
+
+// isRemoteURL returns true when url is the URL of a remote Git repository
+// rather than a path on this machine
+func isRemoteURL(url string) bool {
+	for _, scheme := range remoteSchemes {
+		if strings.HasPrefix(url, scheme) {
+			return true
+		}
+	}
+	return scpURL.MatchString(url)
+}
+
+// wrapError adds the name of the index an error is from when there are
+// several indexes
+func (i Indexes) wrapError(index Index, err error) error {
+	if len(i) == 1 {
+		return err
+	}
+
+	return fmt.Errorf("plugin index %s: %w", index.Name(), err)
+}
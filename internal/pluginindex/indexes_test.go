@@ -0,0 +1,110 @@
+package pluginindex
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildIndexes(t *testing.T) {
+	dataDir := t.TempDir()
+	indexes := BuildIndexes(dataDir, []config.PluginIndex{
+		{Name: "company", URL: "https://example.com/asdf/index.json"},
+		{Name: "mirror", URL: "https://example.com/asdf-plugins.git"},
+		{Name: config.DefaultPluginIndexName, URL: mockIndexURL},
+	}, false, 10)
+
+	assert.Len(t, indexes, 3)
+	assert.IsType(t, JSONIndex{}, indexes[0])
+	assert.Equal(t, filepath.Join(dataDir, "plugin-indexes", "company"), indexes[0].(JSONIndex).directory)
+	assert.IsType(t, PluginIndex{}, indexes[1])
+	assert.Equal(t, filepath.Join(dataDir, "plugin-indexes", "mirror"), indexes[1].(PluginIndex).directory)
+	assert.Equal(t, filepath.Join(dataDir, "plugin-index"), indexes[2].(PluginIndex).directory)
+	assert.Equal(t, []string{"company", "mirror", "default"}, []string{indexes[0].Name(), indexes[1].Name(), indexes[2].Name()})
+}
+
+func TestIndexesGet(t *testing.T) {
+	server, _ := serveJSONIndex(t, http.StatusOK, `{"plugins": [
+  {"name": "elixir", "repository": "https://example.com/company-elixir.git"},
+  {"name": "lua", "repository": "https://example.com/asdf-lua.git"}
+]}`)
+	indexes := Indexes{serverJSONIndex(server, t.TempDir(), 10), mockGitIndex(t)}
+
+	plugins, err := indexes.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, []Plugin{
+		{Name: "elixir", URL: "https://example.com/company-elixir.git", Index: "company"},
+		{Name: "lua", URL: "https://example.com/asdf-lua.git", Index: "company"},
+	}, plugins)
+}
+
+func TestIndexesGetPluginSourceURL(t *testing.T) {
+	t.Run("returns URL from first index listing plugin", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusOK, jsonIndexDocument)
+		indexes := Indexes{serverJSONIndex(server, t.TempDir(), 10), mockGitIndex(t)}
+
+		url, err := indexes.GetPluginSourceURL("elixir")
+		assert.Nil(t, err)
+		assert.Equal(t, "https://example.com/asdf-elixir.git", url)
+	})
+
+	t.Run("falls back to next index when plugin is not listed", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusOK, `{"plugins": []}`)
+		indexes := Indexes{serverJSONIndex(server, t.TempDir(), 10), mockGitIndex(t)}
+
+		url, err := indexes.GetPluginSourceURL("elixir")
+		assert.Nil(t, err)
+		assert.Equal(t, elixirPluginURL, url)
+
+		_, err = indexes.GetPluginSourceURL("ruby")
+		assert.EqualError(t, err, "plugin ruby not found in repository")
+	})
+
+	t.Run("suggests closest plugin names when plugin is not listed", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusOK, jsonIndexDocument)
+		indexes := Indexes{serverJSONIndex(server, t.TempDir(), 10), mockGitIndex(t)}
+
+		_, err := indexes.GetPluginSourceURL("elixr")
+		assert.EqualError(t, err, "plugin elixr not found in repository, did you mean elixir?")
+		assert.IsType(t, PluginNotFoundError{}, err)
+	})
+
+	t.Run("returns error when plugin URL is not a remote Git repository", func(t *testing.T) {
+		for _, url := range []string{"/tmp/asdf-elixir", "file:///tmp/asdf-elixir", "../asdf-elixir.tar.gz"} {
+			server, _ := serveJSONIndex(t, http.StatusOK, `{"plugins": [{"name": "elixir", "repository": "`+url+`"}]}`)
+			indexes := Indexes{serverJSONIndex(server, t.TempDir(), 10), mockGitIndex(t)}
+
+			_, err := indexes.GetPluginSourceURL("elixir")
+			assert.EqualError(t, err, "plugin index company: plugin elixir has URL "+url+", which is not a remote Git repository")
+		}
+	})
+
+	t.Run("returns error rather than falling back when index can't be fetched", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusInternalServerError, "")
+		indexes := Indexes{serverJSONIndex(server, t.TempDir(), 10), mockGitIndex(t)}
+
+		_, err := indexes.GetPluginSourceURL("elixir")
+		assert.ErrorContains(t, err, "plugin index company: unable to initialize index")
+	})
+}
+
+func TestIsRemoteURL(t *testing.T) {
+	for _, url := range []string{"https://github.com/asdf-vm/asdf-ruby.git", "http://example.com/foo", "ssh://git@example.com/foo.git", "git://example.com/foo.git", "git@github.com:asdf-vm/asdf-ruby.git"} {
+		assert.True(t, isRemoteURL(url), url)
+	}
+
+	for _, url := range []string{"/home/user/asdf-ruby", "file:///home/user/asdf-ruby", "asdf-ruby.tar.gz", "./asdf-ruby", ""} {
+		assert.False(t, isRemoteURL(url), url)
+	}
+}
+
+func mockGitIndex(t *testing.T) PluginIndex {
+	t.Helper()
+	dir := t.TempDir()
+	index := New(dir, mockIndexURL, true, 0, &MockIndex{Directory: dir})
+	index.name = config.DefaultPluginIndexName
+	return index
+}
@@ -0,0 +1,251 @@
+package pluginindex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const jsonIndexFilename = "index.json"
+
+// fetchTimeout is how long fetching a JSON index may take
+const fetchTimeout = 30 * time.Second
+
+// maxJSONIndexSize is the largest JSON document accepted as an index, in bytes
+const maxJSONIndexSize = 10 << 20
+
+// JSONIndex is a plugin index that is a single JSON document fetched over
+// HTTPS, cached on disk like the Git repository of a PluginIndex:
+//
+//	{"plugins": [{"name": "nodejs", "repository": "https://github.com/asdf-vm/asdf-nodejs.git"}]}
+//
+// Plugins may also have a "description" and a list of "keywords".
+type JSONIndex struct {
+	name                  string
+	directory             string
+	url                   string
+	disableUpdate         bool
+	updateDurationMinutes int
+	// warnings is where a failure to update the index is reported when the
+	// copy on disk is used instead
+	warnings io.Writer
+	client   *http.Client
+}
+
+// jsonDocument is the format of a JSON index
+type jsonDocument struct {
+	Plugins []struct {
+		Name        string   `json:"name"`
+		Repository  string   `json:"repository"`
+		Description string   `json:"description"`
+		Keywords    []string `json:"keywords"`
+	} `json:"plugins"`
+}
+
+// NewJSON initializes a new JSONIndex instance with the options passed in
+func NewJSON(name, directory, url string, disableUpdate bool, updateDurationMinutes int) JSONIndex {
+	return JSONIndex{
+		name:                  name,
+		directory:             directory,
+		url:                   url,
+		disableUpdate:         disableUpdate,
+		updateDurationMinutes: updateDurationMinutes,
+		warnings:              os.Stderr,
+		client:                &http.Client{Timeout: fetchTimeout, CheckRedirect: checkRedirect},
+	}
+}
+
+// checkRedirect keeps a JSON index from redirecting to a URL that is not
+// HTTPS, with the same limit of 10 redirects as the default HTTP client
+func checkRedirect(request *http.Request, via []*http.Request) error {
+	if request.URL.Scheme != "https" {
+		return fmt.Errorf("redirected to %s, JSON plugin indexes must be served over HTTPS", request.URL)
+	}
+
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// IsJSONURL returns true when url is the URL of a JSON index rather than of a
+// Git repository
+func IsJSONURL(url string) bool {
+	path, _, _ := strings.Cut(url, "?")
+	return strings.HasSuffix(path, ".json")
+}
+
+// Name returns the name of the index in the plugin_indexes setting
+func (j JSONIndex) Name() string {
+	return j.name
+}
+
+// Get returns a slice of all available plugins, sorted by name
+func (j JSONIndex) Get() (plugins []Plugin, err error) {
+	_, err = j.Refresh()
+	if err != nil {
+		return plugins, err
+	}
+
+	document, err := j.read()
+	if err != nil {
+		return plugins, err
+	}
+
+	for _, plugin := range document.Plugins {
+		plugins = append(plugins, Plugin{
+			Name:        plugin.Name,
+			URL:         plugin.Repository,
+			Index:       j.name,
+			Description: plugin.Description,
+			Keywords:    plugin.Keywords,
+		})
+	}
+	slices.SortFunc(plugins, func(a, b Plugin) int { return strings.Compare(a.Name, b.Name) })
+
+	return plugins, nil
+}
+
+// GetPluginSourceURL looks up a plugin by name and returns the repository URL
+func (j JSONIndex) GetPluginSourceURL(name string) (string, error) {
+	plugins, err := j.Get()
+	if err != nil {
+		return "", err
+	}
+
+	index := slices.IndexFunc(plugins, func(plugin Plugin) bool { return plugin.Name == name })
+	if index == -1 {
+		return "", PluginNotFoundError{plugin: name}
+	}
+
+	return plugins[index].URL, nil
+}
+
+// Refresh fetches the JSON document if it hasn't been fetched yet or was
+// fetched longer than updateDurationMinutes ago. When it can't be fetched
+// again the copy on disk is used.
+func (j JSONIndex) Refresh() (bool, error) {
+	fetched, err := j.initialize()
+	if err != nil || fetched {
+		return fetched, err
+	}
+
+	updated, err := lastUpdated(j.directory)
+	if err == nil && !needsUpdate(updated, j.updateDurationMinutes, j.disableUpdate) {
+		return false, nil
+	}
+
+	err = j.update()
+	if err != nil {
+		return warnStale(j.warnings, j.name, err)
+	}
+
+	return true, nil
+}
+
+// Update fetches the JSON document now, however recently it was last fetched
+func (j JSONIndex) Update() error {
+	fetched, err := j.initialize()
+	if err != nil || fetched {
+		return err
+	}
+
+	return j.update()
+}
+
+// Status returns the state of the copy of the JSON document on disk
+func (j JSONIndex) Status() (IndexStatus, error) {
+	return IndexStatus{Name: j.name, URL: j.url, Updated: lastSynced(j.directory)}, nil
+}
+
+// initialize fetches the JSON document when there is no copy of it on disk
+// yet and returns true when it did
+func (j JSONIndex) initialize() (bool, error) {
+	err := os.MkdirAll(j.directory, os.ModePerm)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(filepath.Join(j.directory, jsonIndexFilename))
+	if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+
+	err = j.fetch()
+	if err != nil {
+		return false, fmt.Errorf("unable to initialize index: %w", err)
+	}
+
+	return touchFS(j.directory)
+}
+
+func (j JSONIndex) update() error {
+	err := j.fetch()
+	if err != nil {
+		return fmt.Errorf("unable to update plugin index: %w", err)
+	}
+
+	_, err = touchFS(j.directory)
+	return err
+}
+
+// fetch downloads the JSON document and replaces the cached copy with it once
+// it is known to be valid. Whoever serves the document decides which
+// repositories short names install plugins from, so it is only fetched over
+// HTTPS.
+func (j JSONIndex) fetch() error {
+	if !strings.HasPrefix(strings.ToLower(j.url), "https://") {
+		return fmt.Errorf("%s is not an HTTPS URL, JSON plugin indexes must be served over HTTPS", j.url)
+	}
+
+	response, err := j.client.Get(j.url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s returned %s", j.url, response.Status)
+	}
+
+	contents, err := io.ReadAll(io.LimitReader(response.Body, maxJSONIndexSize+1))
+	if err != nil {
+		return err
+	}
+
+	if len(contents) > maxJSONIndexSize {
+		return fmt.Errorf("%s is larger than %d MiB", j.url, maxJSONIndexSize>>20)
+	}
+
+	var document jsonDocument
+	err = json.Unmarshal(contents, &document)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid plugin index: %w", j.url, err)
+	}
+
+	tempFile := filepath.Join(j.directory, jsonIndexFilename+".tmp")
+	err = os.WriteFile(tempFile, contents, 0o666)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile, filepath.Join(j.directory, jsonIndexFilename))
+}
+
+func (j JSONIndex) read() (document jsonDocument, err error) {
+	contents, err := os.ReadFile(filepath.Join(j.directory, jsonIndexFilename))
+	if err != nil {
+		return document, err
+	}
+
+	err = json.Unmarshal(contents, &document)
+	return document, err
+}
@@ -0,0 +1,196 @@
+package pluginindex
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const jsonIndexDocument = `{"plugins": [
+  {"name": "lua", "repository": "https://example.com/asdf-lua.git"},
+  {"name": "elixir", "repository": "https://example.com/asdf-elixir.git"}
+]}`
+
+func TestIsJSONURL(t *testing.T) {
+	assert.True(t, IsJSONURL("https://example.com/asdf/index.json"))
+	assert.True(t, IsJSONURL("https://example.com/asdf/index.json?token=abc"))
+	assert.False(t, IsJSONURL("https://github.com/asdf-vm/asdf-plugins.git"))
+	assert.False(t, IsJSONURL("/home/user/asdf-plugins"))
+}
+
+func TestJSONIndexGet(t *testing.T) {
+	t.Run("returns plugins sorted by name with index name", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusOK, jsonIndexDocument)
+		index := serverJSONIndex(server, t.TempDir(), 10)
+
+		plugins, err := index.Get()
+		assert.Nil(t, err)
+		assert.Equal(t, []Plugin{
+			{Name: "elixir", URL: "https://example.com/asdf-elixir.git", Index: "company"},
+			{Name: "lua", URL: "https://example.com/asdf-lua.git", Index: "company"},
+		}, plugins)
+	})
+
+	t.Run("returns description and keywords when index provides them", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusOK, `{"plugins": [
+  {"name": "lua", "repository": "https://example.com/asdf-lua.git", "description": "Lua language", "keywords": ["scripting"]}
+]}`)
+		index := serverJSONIndex(server, t.TempDir(), 10)
+
+		plugins, err := index.Get()
+		assert.Nil(t, err)
+		assert.Equal(t, []Plugin{
+			{Name: "lua", URL: "https://example.com/asdf-lua.git", Index: "company", Description: "Lua language", Keywords: []string{"scripting"}},
+		}, plugins)
+	})
+
+	t.Run("returns error when index can't be fetched", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusNotFound, "")
+		index := serverJSONIndex(server, t.TempDir(), 10)
+
+		_, err := index.Get()
+		assert.ErrorContains(t, err, "unable to initialize index: fetching "+server.URL+"/index.json returned 404 Not Found")
+	})
+
+	t.Run("returns error when document is not valid JSON", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusOK, "plugins = lua")
+		index := serverJSONIndex(server, t.TempDir(), 10)
+
+		_, err := index.Get()
+		assert.ErrorContains(t, err, "is not a valid plugin index")
+	})
+}
+
+func TestJSONIndexFetch(t *testing.T) {
+	t.Run("refuses URL that is not HTTPS", func(t *testing.T) {
+		index := NewJSON("company", t.TempDir(), "http://example.com/index.json", false, 10)
+
+		_, err := index.Get()
+		assert.ErrorContains(t, err, "http://example.com/index.json is not an HTTPS URL")
+	})
+
+	t.Run("refuses redirect to URL that is not HTTPS", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.RedirectHandler("http://example.com/index.json", http.StatusFound))
+		t.Cleanup(server.Close)
+		index := serverJSONIndex(server, t.TempDir(), 10)
+
+		_, err := index.Get()
+		assert.ErrorContains(t, err, "redirected to http://example.com/index.json")
+	})
+
+	t.Run("refuses document larger than limit", func(t *testing.T) {
+		server, _ := serveJSONIndex(t, http.StatusOK, `{"plugins": [], "padding": "`+strings.Repeat("a", maxJSONIndexSize)+`"}`)
+		index := serverJSONIndex(server, t.TempDir(), 10)
+
+		_, err := index.Get()
+		assert.ErrorContains(t, err, "is larger than 10 MiB")
+	})
+}
+
+func TestJSONIndexGetPluginSourceURL(t *testing.T) {
+	server, _ := serveJSONIndex(t, http.StatusOK, jsonIndexDocument)
+	index := serverJSONIndex(server, t.TempDir(), 10)
+
+	url, err := index.GetPluginSourceURL("lua")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/asdf-lua.git", url)
+
+	_, err = index.GetPluginSourceURL("ruby")
+	assert.IsType(t, PluginNotFoundError{}, err)
+	assert.EqualError(t, err, "plugin ruby not found in repository")
+}
+
+func TestJSONIndexRefresh(t *testing.T) {
+	t.Run("does not fetch index again when time has not elapsed", func(t *testing.T) {
+		server, requests := serveJSONIndex(t, http.StatusOK, jsonIndexDocument)
+		index := serverJSONIndex(server, t.TempDir(), 10)
+
+		updated, err := index.Refresh()
+		assert.Nil(t, err)
+		assert.True(t, updated)
+
+		updated, err = index.Refresh()
+		assert.Nil(t, err)
+		assert.False(t, updated)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("fetches index again when time has elapsed", func(t *testing.T) {
+		server, requests := serveJSONIndex(t, http.StatusOK, jsonIndexDocument)
+		index := serverJSONIndex(server, t.TempDir(), 0)
+
+		_, err := index.Refresh()
+		assert.Nil(t, err)
+		updated, err := index.Refresh()
+		assert.Nil(t, err)
+		assert.True(t, updated)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("keeps cached index when update fails", func(t *testing.T) {
+		dir := t.TempDir()
+		server, _ := serveJSONIndex(t, http.StatusOK, jsonIndexDocument)
+		_, err := serverJSONIndex(server, dir, 0).Refresh()
+		assert.Nil(t, err)
+
+		broken, _ := serveJSONIndex(t, http.StatusInternalServerError, "")
+		index := serverJSONIndex(broken, dir, 0)
+		var warnings strings.Builder
+		index.warnings = &warnings
+		updated, err := index.Refresh()
+		assert.Nil(t, err)
+		assert.False(t, updated)
+		assert.Contains(t, warnings.String(), "warning: using plugin index company as last synced: unable to update plugin index")
+
+		document, err := index.read()
+		assert.Nil(t, err)
+		assert.Len(t, document.Plugins, 2)
+
+		err = index.Update()
+		assert.ErrorContains(t, err, "unable to update plugin index")
+	})
+}
+
+// serveJSONIndex serves body with status over HTTPS and returns the server and
+// the number of requests it received
+func serveJSONIndex(t *testing.T, status int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+// serverJSONIndex returns a JSONIndex named company for the document served
+// by server, with a client that trusts the certificate of server
+func serverJSONIndex(server *httptest.Server, directory string, updateDurationMinutes int) JSONIndex {
+	index := NewJSON("company", directory, server.URL+"/index.json", false, updateDurationMinutes)
+	index.client = server.Client()
+	index.client.CheckRedirect = checkRedirect
+	return index
+}
+
+func TestJSONIndexStatus(t *testing.T) {
+	server, _ := serveJSONIndex(t, http.StatusOK, jsonIndexDocument)
+	index := serverJSONIndex(server, t.TempDir(), 10)
+
+	status, err := index.Status()
+	assert.Nil(t, err)
+	assert.Equal(t, IndexStatus{Name: "company", URL: server.URL + "/index.json"}, status)
+
+	assert.Nil(t, index.Update())
+	status, err = index.Status()
+	assert.Nil(t, err)
+	assert.Empty(t, status.Commit)
+	assert.False(t, status.Updated.IsZero())
+}
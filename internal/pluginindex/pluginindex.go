@@ -3,41 +3,75 @@
 package pluginindex
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/fuzzy"
 	"github.com/asdf-vm/asdf/internal/git"
 	"gopkg.in/ini.v1"
 )
 
 const (
 	pluginIndexDir      = "plugin-index"
+	pluginIndexesDir    = "plugin-indexes"
 	repoUpdatedFilename = "repo-updated"
+	repoPinnedFilename  = "repo-pinned"
 )
 
+// PluginNotFoundError is returned when a plugin is not listed on a plugin
+// index
+type PluginNotFoundError struct {
+	plugin string
+	// suggestions are the names of listed plugins closest to plugin
+	suggestions []string
+}
+
+func (e PluginNotFoundError) Error() string {
+	if len(e.suggestions) > 0 {
+		return fmt.Sprintf("plugin %s not found in repository, %s", e.plugin, fuzzy.DidYouMean(e.suggestions))
+	}
+
+	return fmt.Sprintf("plugin %s not found in repository", e.plugin)
+}
+
 // PluginIndex is a struct representing the user's preferences for plugin index
 // and the plugin index on disk.
 type PluginIndex struct {
+	name                  string
 	repo                  git.Repoer
 	directory             string
 	url                   string
 	disableUpdate         bool
 	updateDurationMinutes int
+	// warnings is where a failure to update the index is reported when the
+	// copy on disk is used instead
+	warnings io.Writer
 }
 
 // Plugin represents a plugin listed on a plugin index.
 type Plugin struct {
 	Name string
 	URL  string
+	// Index is the name of the index the plugin is listed on
+	Index string
+	// Description and Keywords are only set when the index provides them
+	Description string
+	Keywords    []string
 }
 
 // Build returns a complete PluginIndex struct with default values set
 func Build(dataDir string, URL string, disableUpdate bool, updateDurationMinutes int) PluginIndex {
 	directory := filepath.Join(dataDir, pluginIndexDir)
-	return New(directory, URL, disableUpdate, updateDurationMinutes, &git.Repo{Directory: directory})
+	index := New(directory, URL, disableUpdate, updateDurationMinutes, &git.Repo{Directory: directory})
+	index.name = config.DefaultPluginIndexName
+	return index
 }
 
 // New initializes a new PluginIndex instance with the options passed in.
@@ -48,9 +82,15 @@ func New(directory, url string, disableUpdate bool, updateDurationMinutes int, r
 		url:                   url,
 		disableUpdate:         disableUpdate,
 		updateDurationMinutes: updateDurationMinutes,
+		warnings:              os.Stderr,
 	}
 }
 
+// Name returns the name of the index in the plugin_indexes setting
+func (p PluginIndex) Name() string {
+	return p.name
+}
+
 // Get returns a slice of all available plugins
 func (p PluginIndex) Get() (plugins []Plugin, err error) {
 	_, err = p.Refresh()
@@ -58,13 +98,54 @@ func (p PluginIndex) Get() (plugins []Plugin, err error) {
 		return plugins, err
 	}
 
-	return getPlugins(p.directory)
+	plugins, err = getPlugins(p.directory)
+	for i := range plugins {
+		plugins[i].Index = p.name
+	}
+
+	return plugins, err
 }
 
 // Refresh may update the plugin repo if it hasn't been updated in longer
 // than updateDurationMinutes. If the plugin repo needs to be updated the
-// repo will be invoked to perform the actual Git pull.
+// repo will be invoked to perform the actual Git pull. A pinned index is never
+// updated, and an index that fails to update is used as it is on disk.
 func (p PluginIndex) Refresh() (bool, error) {
+	cloned, err := p.initialize()
+	if err != nil || cloned {
+		return cloned, err
+	}
+
+	// directory must not be empty, repo must be present, maybe update
+	_, pinned, err := readPin(p.directory)
+	if err != nil || pinned {
+		return false, err
+	}
+
+	updated, err := lastUpdated(p.directory)
+	if err != nil {
+		return p.doUpdate()
+	}
+
+	if needsUpdate(updated, p.updateDurationMinutes, p.disableUpdate) {
+		return p.doUpdate()
+	}
+
+	return false, nil
+}
+
+// needsUpdate returns true when an index last updated updated nanoseconds ago
+// is due to be updated
+func needsUpdate(updated int64, updateDurationMinutes int, disableUpdate bool) bool {
+	// Convert minutes to nanoseconds
+	updateDurationNs := int64(updateDurationMinutes) * (6e10)
+
+	return updated > updateDurationNs && !disableUpdate
+}
+
+// initialize clones the plugin repo when there is no copy of it on disk yet
+// and returns true when it did
+func (p PluginIndex) initialize() (bool, error) {
 	err := os.MkdirAll(p.directory, os.ModePerm)
 	if err != nil {
 		return false, err
@@ -75,42 +156,162 @@ func (p PluginIndex) Refresh() (bool, error) {
 		return false, err
 	}
 
-	if len(files) == 0 {
-		// directory empty, clone down repo
-		err := p.repo.Clone(p.url, "")
+	if len(files) > 0 {
+		return false, nil
+	}
+
+	// directory empty, clone down repo
+	err = p.repo.Clone(p.url, "")
+	if err != nil {
+		return false, fmt.Errorf("unable to initialize index: %w", err)
+	}
+
+	return touchFS(p.directory)
+}
+
+func (p PluginIndex) doUpdate() (bool, error) {
+	err := p.update()
+	if err != nil {
+		return warnStale(p.warnings, p.name, err)
+	}
+
+	return true, nil
+}
+
+// Update updates the plugin repo now, however recently it was last updated.
+// A pinned index is kept at the commit it is pinned to.
+func (p PluginIndex) Update() error {
+	cloned, err := p.initialize()
+	if err != nil || cloned {
+		return err
+	}
+
+	return p.update()
+}
+
+func (p PluginIndex) update() error {
+	current, pinned, err := readPin(p.directory)
+	if err != nil {
+		return err
+	}
+
+	// pass in empty string, unless pinned, as we want the repo to figure out
+	// what the latest commit is
+	_, _, _, err = p.repo.Update(current.SHA)
+	if err != nil {
+		return fmt.Errorf("unable to update plugin index: %w", err)
+	}
+
+	// Checking out the commit removes files Git doesn't track, the pin among
+	// them
+	if pinned {
+		err = writePin(p.directory, current)
 		if err != nil {
-			return false, fmt.Errorf("unable to initialize index: %w", err)
+			return err
 		}
+	}
 
-		return touchFS(p.directory)
+	// Touch update file
+	_, err = touchFS(p.directory)
+	return err
+}
+
+// Status returns the state of the copy of the plugin repo on disk
+func (p PluginIndex) Status() (IndexStatus, error) {
+	status := IndexStatus{Name: p.name, URL: p.url, Updated: lastSynced(p.directory)}
+
+	pinned, _, err := readPin(p.directory)
+	if err != nil {
+		return status, err
 	}
+	status.Pinned = pinned.SHA
 
-	// directory must not be empty, repo must be present, maybe update
-	updated, err := lastUpdated(p.directory)
+	status.Commit, err = p.repo.Head()
+	return status, err
+}
+
+// Pin checks out the commit ref points to and keeps the plugin repo at it,
+// so short names resolve to the same plugins until the index is unpinned. It
+// returns the SHA of the commit.
+func (p PluginIndex) Pin(ref string) (string, error) {
+	_, err := p.initialize()
 	if err != nil {
-		return p.doUpdate()
+		return "", err
 	}
 
-	// Convert minutes to nanoseconds
-	updateDurationNs := int64(p.updateDurationMinutes) * (6e10)
+	current, pinned, err := readPin(p.directory)
+	if err != nil {
+		return "", err
+	}
 
-	if updated > updateDurationNs && !p.disableUpdate {
-		return p.doUpdate()
+	// Keep the branch the index was on before it was first pinned to return
+	// to it when unpinned
+	branch := current.Branch
+	if !pinned {
+		branch, err = p.repo.Branch()
+		if err != nil {
+			return "", err
+		}
 	}
 
-	return false, nil
+	// With HEAD detached there is no branch to return to, so return to the
+	// branch a fresh clone of the index would be on
+	if branch == "" {
+		branch, err = p.repo.DefaultBranch()
+		if err != nil {
+			return "", fmt.Errorf("unable to find branch to return to when plugin index is unpinned: %w", err)
+		}
+	}
+
+	_, _, sha, err := p.repo.Update(ref)
+	if err != nil {
+		return "", fmt.Errorf("unable to pin plugin index to %s: %w", ref, err)
+	}
+
+	err = writePin(p.directory, pin{SHA: sha, Branch: branch})
+	if err != nil {
+		return "", err
+	}
+
+	_, err = touchFS(p.directory)
+	return sha, err
 }
 
-func (p PluginIndex) doUpdate() (bool, error) {
-	// pass in empty string as we want the repo to figure out what the latest
-	// commit is
-	_, _, _, err := p.repo.Update("")
+// Unpin returns the plugin repo to the latest commit of the branch it was on
+// before it was pinned, or of the default branch of its remote when it wasn't
+// on a branch
+func (p PluginIndex) Unpin() error {
+	current, pinned, err := readPin(p.directory)
 	if err != nil {
-		return false, fmt.Errorf("unable to update plugin index: %w", err)
+		return err
+	}
+	if !pinned {
+		return fmt.Errorf("plugin index %s is not pinned", p.name)
 	}
 
-	// Touch update file
-	return touchFS(p.directory)
+	// Pins written while HEAD was detached have no branch, Update would then
+	// fail as there is no branch to update
+	branch := current.Branch
+	if branch == "" {
+		branch, err = p.repo.DefaultBranch()
+		if err != nil {
+			return fmt.Errorf("unable to find branch to return plugin index to: %w", err)
+		}
+	}
+
+	_, _, _, err = p.repo.Update(branch)
+	if err != nil {
+		return fmt.Errorf("unable to unpin plugin index: %w", err)
+	}
+
+	// Checking out the branch may have removed the pin already
+	err = os.Remove(filepath.Join(p.directory, repoPinnedFilename))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	_, err = touchFS(p.directory)
+	return err
 }
 
 // GetPluginSourceURL looks up a plugin by name and returns the repository URL
@@ -121,12 +322,12 @@ func (p PluginIndex) GetPluginSourceURL(name string) (string, error) {
 		return "", err
 	}
 
-	url, err := readPlugin(p.directory, name)
+	plugin, err := readPlugin(p.directory, name)
 	if err != nil {
 		return "", err
 	}
 
-	return url, nil
+	return plugin.URL, nil
 }
 
 func touchFS(directory string) (bool, error) {
@@ -137,9 +338,35 @@ func touchFS(directory string) (bool, error) {
 	}
 
 	file.Close()
+
+	// Opening the file doesn't change its modification time when it exists
+	now := time.Now()
+	err = os.Chtimes(filename, now, now)
+	if err != nil {
+		return false, fmt.Errorf("unable to update plugin index touch file: %w", err)
+	}
+
 	return true, nil
 }
 
+// warnStale reports err, a failure to update an index, to warnings and
+// returns without error so the copy of the index on disk is used instead
+func warnStale(warnings io.Writer, name string, err error) (bool, error) {
+	fmt.Fprintf(warnings, "warning: using plugin index %s as last synced: %s\n", name, err)
+	return false, nil
+}
+
+// lastSynced returns when the index in dir was last synced, or the zero time
+// when it never was
+func lastSynced(dir string) time.Time {
+	info, err := os.Stat(filepath.Join(dir, repoUpdatedFilename))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
 func lastUpdated(dir string) (int64, error) {
 	info, err := os.Stat(filepath.Join(dir, repoUpdatedFilename))
 	if err != nil {
@@ -151,15 +378,28 @@ func lastUpdated(dir string) (int64, error) {
 	return updated, nil
 }
 
-func readPlugin(dir, name string) (string, error) {
+func readPlugin(dir, name string) (Plugin, error) {
 	filename := filepath.Join(dir, "plugins", name)
 
 	pluginInfo, err := ini.Load(filename)
 	if err != nil {
-		return "", fmt.Errorf("plugin %s not found in repository", name)
+		return Plugin{}, PluginNotFoundError{plugin: name}
+	}
+
+	section := pluginInfo.Section("")
+	plugin := Plugin{
+		Name:        name,
+		URL:         section.Key("repository").String(),
+		Description: section.Key("description").String(),
+	}
+
+	for _, keyword := range strings.Split(section.Key("keywords").String(), ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			plugin.Keywords = append(plugin.Keywords, keyword)
+		}
 	}
 
-	return pluginInfo.Section("").Key("repository").String(), nil
+	return plugin, nil
 }
 
 func getPlugins(dir string) (plugins []Plugin, err error) {
@@ -170,14 +410,43 @@ func getPlugins(dir string) (plugins []Plugin, err error) {
 
 	for _, file := range files {
 		if !file.IsDir() {
-			url, err := readPlugin(dir, file.Name())
+			plugin, err := readPlugin(dir, file.Name())
 			if err != nil {
 				return plugins, err
 			}
 
-			plugins = append(plugins, Plugin{Name: file.Name(), URL: url})
+			plugins = append(plugins, plugin)
 		}
 	}
 
 	return plugins, err
 }
+
+// pin is the commit a plugin repo is pinned to, and the branch it was on
+// before it was pinned
+type pin struct {
+	SHA    string
+	Branch string
+}
+
+func readPin(dir string) (pin, bool, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, repoPinnedFilename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return pin{}, false, nil
+	}
+	if err != nil {
+		return pin{}, false, fmt.Errorf("unable to read plugin index pin: %w", err)
+	}
+
+	sha, branch, _ := strings.Cut(strings.TrimSpace(string(contents)), " ")
+	if sha == "" {
+		return pin{}, false, fmt.Errorf("plugin index pin %s is empty", filepath.Join(dir, repoPinnedFilename))
+	}
+
+	return pin{SHA: sha, Branch: branch}, true, nil
+}
+
+func writePin(dir string, pinned pin) error {
+	contents := strings.TrimSpace(pinned.SHA+" "+pinned.Branch) + "\n"
+	return os.WriteFile(filepath.Join(dir, repoPinnedFilename), []byte(contents), 0o666)
+}
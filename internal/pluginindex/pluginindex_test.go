@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/asdf-vm/asdf/internal/git"
 	"github.com/asdf-vm/asdf/internal/repotest"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,8 +31,11 @@ type MockIndex struct {
 
 // Only defined so MockIndex complies with git.Repoer interface. These are not
 // used by pluginindex package code
-func (m *MockIndex) Head() (string, error)      { return "", nil }
-func (m *MockIndex) RemoteURL() (string, error) { return "", nil }
+func (m *MockIndex) Branch() (string, error)        { return "", nil }
+func (m *MockIndex) DefaultBranch() (string, error) { return "", nil }
+func (m *MockIndex) Head() (string, error)          { return "", nil }
+func (m *MockIndex) RemoteURL() (string, error)     { return "", nil }
+func (m *MockIndex) Status() (git.Status, error)    { return git.Status{}, nil }
 
 func (m *MockIndex) Clone(URL, _ string) error {
 	m.URL = URL
@@ -91,6 +97,20 @@ func TestGet(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, plugins, []Plugin{{Name: "elixir", URL: "https://github.com/asdf-vm/asdf-elixir.git"}})
 	})
+
+	t.Run("returns description and keywords when plugin file has them", func(t *testing.T) {
+		dir := t.TempDir()
+		pluginIndex := New(dir, mockIndexURL, true, 0, &MockIndex{Directory: dir})
+		_, err := pluginIndex.Refresh()
+		assert.Nil(t, err)
+
+		contents := "repository = " + fooPluginURL + "\ndescription = Foo language\nkeywords = bar, baz\n"
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "plugins", "foo"), []byte(contents), 0o666))
+
+		plugins, err := pluginIndex.Get()
+		assert.Nil(t, err)
+		assert.Contains(t, plugins, Plugin{Name: "foo", URL: fooPluginURL, Description: "Foo language", Keywords: []string{"bar", "baz"}})
+	})
 }
 
 func TestGetPluginSourceURL(t *testing.T) {
@@ -137,20 +157,22 @@ func TestGetPluginSourceURL(t *testing.T) {
 		assert.Equal(t, url, "")
 	})
 
-	t.Run("returns an error when plugin index cannot be updated", func(t *testing.T) {
+	t.Run("uses copy on disk with a warning when plugin index cannot be updated", func(t *testing.T) {
 		dir := t.TempDir()
 
-		// create plain text file so it appears plugin index already exists on disk
-		file, err := os.OpenFile(filepath.Join(dir, "test"), os.O_RDONLY|os.O_CREATE, 0o666)
-		assert.Nil(t, err)
-		file.Close()
+		// write a plugin file so it appears plugin index already exists on disk
+		assert.Nil(t, writeMockPluginFile(dir, "lua", fooPluginURL))
 		repo := MockIndex{Directory: dir, URL: badIndexURL}
 
 		pluginIndex := New(dir, badIndexURL, false, 10, &repo)
+		pluginIndex.name = "default"
+		var warnings strings.Builder
+		pluginIndex.warnings = &warnings
 
 		url, err := pluginIndex.GetPluginSourceURL("lua")
-		assert.EqualError(t, err, "unable to update plugin index: unable to clone: repository not found")
-		assert.Equal(t, url, "")
+		assert.Nil(t, err)
+		assert.Equal(t, url, fooPluginURL)
+		assert.Equal(t, "warning: using plugin index default as last synced: unable to update plugin index: unable to clone: repository not found\n", warnings.String())
 	})
 
 	t.Run("returns error when given non-existent plugin index", func(t *testing.T) {
@@ -233,3 +255,177 @@ func TestRefresh(t *testing.T) {
 		assert.False(t, updated)
 	})
 }
+
+func TestUpdate(t *testing.T) {
+	t.Run("updates index even when time has not elapsed", func(t *testing.T) {
+		indexDir, repoPath := generateGitIndex(t)
+		pluginIndex := New(indexDir, repoPath, false, 10, &git.Repo{Directory: indexDir})
+		_, err := pluginIndex.Refresh()
+		assert.Nil(t, err)
+
+		commitPluginFile(t, repoPath, "lua", "http://example.com/lua")
+		updated, err := pluginIndex.Refresh()
+		assert.Nil(t, err)
+		assert.False(t, updated)
+
+		assert.Nil(t, pluginIndex.Update())
+		url, err := pluginIndex.GetPluginSourceURL("lua")
+		assert.Nil(t, err)
+		assert.Equal(t, "http://example.com/lua", url)
+	})
+
+	t.Run("returns error rather than using copy on disk", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Nil(t, writeMockPluginFile(dir, "lua", fooPluginURL))
+		pluginIndex := New(dir, badIndexURL, false, 10, &MockIndex{Directory: dir, URL: badIndexURL})
+
+		err := pluginIndex.Update()
+		assert.EqualError(t, err, "unable to update plugin index: unable to clone: repository not found")
+	})
+}
+
+func TestStatus(t *testing.T) {
+	indexDir, repoPath := generateGitIndex(t)
+	pluginIndex := New(indexDir, repoPath, false, 10, &git.Repo{Directory: indexDir})
+	pluginIndex.name = "default"
+
+	status, err := pluginIndex.Status()
+	assert.Nil(t, err)
+	assert.Equal(t, IndexStatus{Name: "default", URL: repoPath}, status)
+
+	_, err = pluginIndex.Refresh()
+	assert.Nil(t, err)
+	status, err = pluginIndex.Status()
+	assert.Nil(t, err)
+	assert.Equal(t, repoHead(t, repoPath), status.Commit)
+	assert.Empty(t, status.Pinned)
+	assert.WithinDuration(t, time.Now(), status.Updated, time.Minute)
+}
+
+func TestPin(t *testing.T) {
+	t.Run("keeps index at pinned commit until unpinned", func(t *testing.T) {
+		indexDir, repoPath := generateGitIndex(t)
+		pluginIndex := New(indexDir, repoPath, false, 0, &git.Repo{Directory: indexDir})
+		pinned := repoHead(t, repoPath)
+
+		sha, err := pluginIndex.Pin(pinned[:7])
+		assert.Nil(t, err)
+		assert.Equal(t, pinned, sha)
+
+		commitPluginFile(t, repoPath, "lua", "http://example.com/lua")
+		updated, err := pluginIndex.Refresh()
+		assert.Nil(t, err)
+		assert.False(t, updated)
+		assert.Nil(t, pluginIndex.Update())
+
+		status, err := pluginIndex.Status()
+		assert.Nil(t, err)
+		assert.Equal(t, pinned, status.Commit)
+		assert.Equal(t, pinned, status.Pinned)
+		_, err = pluginIndex.GetPluginSourceURL("lua")
+		assert.IsType(t, PluginNotFoundError{}, err)
+
+		assert.Nil(t, pluginIndex.Unpin())
+		status, err = pluginIndex.Status()
+		assert.Nil(t, err)
+		assert.Equal(t, repoHead(t, repoPath), status.Commit)
+		assert.Empty(t, status.Pinned)
+
+		branch, err := git.NewRepo(indexDir).Branch()
+		assert.Nil(t, err)
+		assert.NotEmpty(t, branch)
+	})
+
+	t.Run("returns to default branch when pinned from detached HEAD", func(t *testing.T) {
+		indexDir, repoPath := generateGitIndex(t)
+		pluginIndex := New(indexDir, repoPath, false, 0, &git.Repo{Directory: indexDir})
+		first := repoHead(t, repoPath)
+		commitPluginFile(t, repoPath, "lua", "http://example.com/lua")
+		_, err := pluginIndex.Refresh()
+		assert.Nil(t, err)
+		assert.Nil(t, git.NewRepo(indexDir).Checkout(first, ""))
+
+		_, err = pluginIndex.Pin(first)
+		assert.Nil(t, err)
+		contents, err := os.ReadFile(filepath.Join(indexDir, repoPinnedFilename))
+		assert.Nil(t, err)
+		assert.Equal(t, first+" master\n", string(contents))
+
+		assert.Nil(t, pluginIndex.Unpin())
+		branch, err := git.NewRepo(indexDir).Branch()
+		assert.Nil(t, err)
+		assert.Equal(t, "master", branch)
+		assert.Equal(t, repoHead(t, repoPath), repoHead(t, indexDir))
+	})
+
+	t.Run("returns to default branch when pin has no branch", func(t *testing.T) {
+		indexDir, repoPath := generateGitIndex(t)
+		pluginIndex := New(indexDir, repoPath, false, 0, &git.Repo{Directory: indexDir})
+		first := repoHead(t, repoPath)
+		_, err := pluginIndex.Pin(first)
+		assert.Nil(t, err)
+		assert.Nil(t, git.NewRepo(indexDir).Checkout(first, ""))
+		assert.Nil(t, writePin(indexDir, pin{SHA: first}))
+
+		assert.Nil(t, pluginIndex.Unpin())
+		branch, err := git.NewRepo(indexDir).Branch()
+		assert.Nil(t, err)
+		assert.Equal(t, "master", branch)
+	})
+
+	t.Run("returns error when commit doesn't exist", func(t *testing.T) {
+		indexDir, repoPath := generateGitIndex(t)
+		pluginIndex := New(indexDir, repoPath, false, 0, &git.Repo{Directory: indexDir})
+
+		_, err := pluginIndex.Pin("0000000")
+		assert.ErrorContains(t, err, "unable to pin plugin index to 0000000")
+
+		status, err := pluginIndex.Status()
+		assert.Nil(t, err)
+		assert.Empty(t, status.Pinned)
+	})
+
+	t.Run("returns error when unpinning index that is not pinned", func(t *testing.T) {
+		indexDir, repoPath := generateGitIndex(t)
+		pluginIndex := New(indexDir, repoPath, false, 0, &git.Repo{Directory: indexDir})
+		pluginIndex.name = "default"
+
+		assert.EqualError(t, pluginIndex.Unpin(), "plugin index default is not pinned")
+	})
+}
+
+// generateGitIndex returns an empty directory for an index and the path of a
+// Git repository for it to clone
+func generateGitIndex(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	indexDir := filepath.Join(dir, "index")
+	assert.Nil(t, os.Mkdir(indexDir, 0o777))
+
+	repoPath, err := repotest.GeneratePluginIndex(dir)
+	assert.Nil(t, err)
+
+	return indexDir, repoPath
+}
+
+func commitPluginFile(t *testing.T, repoPath, pluginName, pluginURL string) {
+	t.Helper()
+	assert.Nil(t, writeMockPluginFile(repoPath, pluginName, pluginURL))
+
+	repo, err := gogit.PlainOpen(repoPath)
+	assert.Nil(t, err)
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+	_, err = worktree.Add(filepath.Join("plugins", pluginName))
+	assert.Nil(t, err)
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	_, err = worktree.Commit("add "+pluginName, &gogit.CommitOptions{Author: signature})
+	assert.Nil(t, err)
+}
+
+func repoHead(t *testing.T, repoPath string) string {
+	t.Helper()
+	head, err := git.NewRepo(repoPath).Head()
+	assert.Nil(t, err)
+	return head
+}
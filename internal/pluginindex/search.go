@@ -0,0 +1,67 @@
+package pluginindex
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/fuzzy"
+)
+
+const (
+	keywordScore     = 30
+	keywordPrefix    = 25
+	descriptionScore = 20
+)
+
+// Search returns the plugins matching term, best matches first. Plugins are
+// ranked by how close their name is to term, and otherwise by whether term is
+// one of their keywords or appears in their description.
+func Search(plugins []Plugin, term string) []Plugin {
+	type match struct {
+		plugin Plugin
+		score  int
+	}
+
+	matches := []match{}
+	for _, plugin := range plugins {
+		if score := searchScore(plugin, term); score > 0 {
+			matches = append(matches, match{plugin: plugin, score: score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), strings.Compare(a.plugin.Name, b.plugin.Name))
+	})
+
+	results := []Plugin{}
+	for _, match := range matches {
+		results = append(results, match.plugin)
+	}
+
+	return results
+}
+
+func searchScore(plugin Plugin, term string) int {
+	term = strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return 0
+	}
+
+	score := fuzzy.Score(term, plugin.Name)
+
+	for _, keyword := range plugin.Keywords {
+		keyword = strings.ToLower(keyword)
+		if keyword == term {
+			score = max(score, keywordScore)
+		} else if strings.HasPrefix(keyword, term) {
+			score = max(score, keywordPrefix)
+		}
+	}
+
+	if strings.Contains(strings.ToLower(plugin.Description), term) {
+		score = max(score, descriptionScore)
+	}
+
+	return score
+}
@@ -0,0 +1,42 @@
+package pluginindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	plugins := []Plugin{
+		{Name: "deno", Description: "Deno, a JavaScript runtime", Keywords: []string{"javascript", "typescript"}},
+		{Name: "nodejs", Description: "Node.js JavaScript runtime", Keywords: []string{"javascript", "js"}},
+		{Name: "node-build"},
+		{Name: "ruby", Description: "Ruby language"},
+	}
+
+	names := func(plugins []Plugin) (names []string) {
+		for _, plugin := range plugins {
+			names = append(names, plugin.Name)
+		}
+		return names
+	}
+
+	t.Run("ranks names starting with term first", func(t *testing.T) {
+		assert.Equal(t, []string{"node-build", "nodejs"}, names(Search(plugins, "node")))
+	})
+
+	t.Run("matches mistyped names", func(t *testing.T) {
+		assert.Equal(t, []string{"nodejs"}, names(Search(plugins, "ndoejs")))
+	})
+
+	t.Run("matches keywords and descriptions after names", func(t *testing.T) {
+		assert.Equal(t, []string{"deno"}, names(Search(plugins, "typescript")))
+		assert.Equal(t, []string{"deno", "nodejs"}, names(Search(plugins, "JavaScript")))
+		assert.Equal(t, []string{"ruby"}, names(Search(plugins, "language")))
+	})
+
+	t.Run("returns nothing when no plugin matches", func(t *testing.T) {
+		assert.Empty(t, Search(plugins, "python"))
+		assert.Empty(t, Search(plugins, " "))
+	})
+}
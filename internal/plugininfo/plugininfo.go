@@ -0,0 +1,317 @@
+// Package plugininfo gathers everything asdf knows about one installed plugin
+// for the asdf plugin info command
+package plugininfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+)
+
+// Info describes an installed plugin. The Git fields are empty when the plugin
+// directory is not a Git repository.
+type Info struct {
+	Name string `json:"name"`
+	Dir  string `json:"dir"`
+	// LinkedTo is the directory a plugin added with asdf plugin link links to
+	LinkedTo string `json:"linked_to,omitempty"`
+	URL      string `json:"url"`
+	Ref      string `json:"ref"`
+	Branch   string `json:"branch"`
+	Dirty    bool   `json:"dirty"`
+	// UpdatedAt is when the plugin was added or last updated
+	UpdatedAt         time.Time         `json:"updated_at"`
+	Callbacks         []string          `json:"callbacks"`
+	MissingCallbacks  []string          `json:"missing_callbacks"`
+	ExtensionCommands []string          `json:"extension_commands"`
+	LegacyFilenames   []string          `json:"legacy_filenames"`
+	Versions          []Version         `json:"versions"`
+	Manifest          *plugins.Manifest `json:"manifest,omitempty"`
+}
+
+// Version is an installed version of the plugin's tool
+type Version struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	// Size is the disk usage of the installation in bytes
+	Size int64 `json:"size"`
+}
+
+// Build gathers the Info of an installed plugin
+func Build(conf config.Config, plugin plugins.Plugin) (info Info, err error) {
+	if err := plugin.Exists(); err != nil {
+		return info, err
+	}
+
+	info = Info{Name: plugin.Name, Dir: plugin.Dir}
+
+	if plugin.IsLinked() {
+		info.LinkedTo, err = os.Readlink(plugin.Dir)
+		if err != nil {
+			return info, err
+		}
+	}
+
+	err = addGitInfo(&info, git.NewRepo(plugin.Dir))
+	if err != nil {
+		return info, err
+	}
+
+	info.UpdatedAt, err = updatedAt(plugin.Dir)
+	if err != nil {
+		return info, err
+	}
+
+	info.Callbacks, info.MissingCallbacks = callbacks(plugin)
+
+	info.ExtensionCommands, err = plugin.GetExtensionCommands()
+	if err != nil {
+		return info, err
+	}
+
+	info.LegacyFilenames, err = plugin.LegacyFilenames(conf)
+	if err != nil {
+		return info, err
+	}
+
+	info.Versions, err = installedVersions(conf, plugin)
+	if err != nil {
+		return info, err
+	}
+
+	manifest, found, err := plugin.Manifest()
+	if err != nil {
+		return info, err
+	}
+	if found {
+		info.Manifest = &manifest
+	}
+
+	return info, nil
+}
+
+// Write writes info in the human readable format of asdf plugin info
+func Write(out io.Writer, info Info) {
+	w := tabwriter.NewWriter(out, 20, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "name\t%s\n", info.Name)
+	fmt.Fprintf(w, "dir\t%s\n", info.Dir)
+	writeField(w, "linked to", info.LinkedTo)
+	writeField(w, "url", info.URL)
+	writeField(w, "ref", info.Ref)
+	writeField(w, "branch", info.Branch)
+	if info.Ref != "" {
+		fmt.Fprintf(w, "dirty\t%t\n", info.Dirty)
+	}
+	fmt.Fprintf(w, "updated\t%s\n", info.UpdatedAt.Format(time.DateTime))
+	writeField(w, "callbacks", strings.Join(info.Callbacks, ", "))
+	writeField(w, "missing callbacks", strings.Join(info.MissingCallbacks, ", "))
+	writeField(w, "extension commands", strings.Join(extensionCommandLines(info.Name, info.ExtensionCommands), ", "))
+	writeField(w, "legacy filenames", strings.Join(info.LegacyFilenames, ", "))
+	w.Flush()
+
+	if len(info.Versions) > 0 {
+		fmt.Fprintln(out, "versions")
+		for _, version := range info.Versions {
+			fmt.Fprintf(w, "  %s\t%s\n", version.Version, formatSize(version.Size))
+		}
+		w.Flush()
+	}
+
+	if info.Manifest != nil {
+		fmt.Fprintf(out, "\n%s\n", plugins.ManifestFilename)
+		WriteManifest(out, info.Name, *info.Manifest)
+	}
+}
+
+// WriteJSON writes info as a JSON object
+func WriteJSON(out io.Writer, info Info) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(info)
+}
+
+// WriteManifest writes the fields set in manifest in the human readable format
+// of asdf plugin info
+func WriteManifest(out io.Writer, pluginName string, manifest plugins.Manifest) {
+	w := tabwriter.NewWriter(out, 20, 0, 1, ' ', 0)
+	writeField(w, "name", manifest.Name)
+	writeField(w, "description", manifest.Description)
+	writeField(w, "homepage", manifest.Homepage)
+	writeField(w, "license", manifest.License)
+	writeField(w, "min asdf version", manifest.MinAsdfVersion)
+	writeField(w, "os", strings.Join(manifest.OS, ", "))
+	writeField(w, "arch", strings.Join(manifest.Arch, ", "))
+	writeField(w, "legacy filenames", strings.Join(manifest.LegacyFilenames, ", "))
+	writeField(w, "bin paths", strings.Join(manifest.BinPaths, ", "))
+	writeField(w, "dependencies", strings.Join(manifest.Dependencies, ", "))
+	w.Flush()
+
+	if len(manifest.Commands) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out, "commands")
+	for _, command := range slices.Sorted(maps.Keys(manifest.Commands)) {
+		fmt.Fprintf(w, "  %s\t%s\n", extensionCommandLine(pluginName, command), manifest.Commands[command])
+	}
+	w.Flush()
+}
+
+func writeField(w io.Writer, label, value string) {
+	if value != "" {
+		fmt.Fprintf(w, "%s\t%s\n", label, value)
+	}
+}
+
+func addGitInfo(info *Info, repo git.Repo) error {
+	var err error
+	info.Ref, err = repo.Head()
+	if err != nil {
+		return err
+	}
+
+	info.URL, err = repo.RemoteURL()
+	if err != nil {
+		return err
+	}
+
+	info.Branch, err = repo.Branch()
+	if err != nil {
+		return err
+	}
+
+	info.Dirty, err = repo.IsDirty()
+	return err
+}
+
+// updatedAt returns the modification time of the Git HEAD file or of the
+// branch it points to, whichever is later. HEAD is written when the plugin is
+// cloned or checked out at a different ref, but a fast-forward only moves the
+// branch. Plugins that aren't Git repositories use the plugin directory.
+func updatedAt(dir string) (time.Time, error) {
+	head := filepath.Join(dir, ".git", "HEAD")
+	stat, err := os.Stat(head)
+	if err != nil {
+		stat, err = os.Stat(dir)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return stat.ModTime(), nil
+	}
+
+	modTime := stat.ModTime()
+	contents, err := os.ReadFile(head)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// A packed branch ref has no file of its own, so only HEAD is used
+	if ref, ok := strings.CutPrefix(strings.TrimSpace(string(contents)), "ref: "); ok {
+		if stat, err := os.Stat(filepath.Join(dir, ".git", filepath.FromSlash(ref))); err == nil && stat.ModTime().After(modTime) {
+			modTime = stat.ModTime()
+		}
+	}
+
+	return modTime, nil
+}
+
+func callbacks(plugin plugins.Plugin) (present, missing []string) {
+	present, missing = []string{}, []string{}
+	for _, callback := range plugins.Callbacks {
+		if _, err := plugin.CallbackPath(callback); err == nil {
+			present = append(present, callback)
+		} else {
+			missing = append(missing, callback)
+		}
+	}
+
+	return present, missing
+}
+
+func installedVersions(conf config.Config, plugin plugins.Plugin) ([]Version, error) {
+	names, err := installs.Installed(conf, plugin)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []Version{}
+	for _, name := range names {
+		path := filepath.Join(data.InstallDirectory(conf.DataDir, plugin.Name), name)
+		size, err := diskUsage(path)
+		if err != nil {
+			return versions, err
+		}
+
+		versions = append(versions, Version{Version: name, Path: path, Size: size})
+	}
+
+	return versions, nil
+}
+
+func diskUsage(dir string) (size int64, err error) {
+	err = filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.Type().IsRegular() {
+			fileInfo, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += fileInfo.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		value /= unit
+		if value < unit || suffix == "GiB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+
+	return ""
+}
+
+func extensionCommandLines(pluginName string, commands []string) (lines []string) {
+	for _, command := range commands {
+		lines = append(lines, extensionCommandLine(pluginName, command))
+	}
+
+	return lines
+}
+
+func extensionCommandLine(pluginName, command string) string {
+	if command == "" {
+		return fmt.Sprintf("asdf %s", pluginName)
+	}
+
+	return fmt.Sprintf("asdf %s %s", pluginName, command)
+}
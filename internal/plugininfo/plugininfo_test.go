@@ -0,0 +1,176 @@
+package plugininfo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/installtest"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginName = "lua"
+
+func TestBuild(t *testing.T) {
+	t.Run("returns error when plugin does not exist", func(t *testing.T) {
+		conf, _ := generateConfig(t)
+
+		_, err := Build(conf, plugins.New(conf, "non-existent"))
+		assert.IsType(t, plugins.PluginMissing{}, err)
+	})
+
+	t.Run("returns Git state of plugin", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+
+		info, err := Build(conf, plugin)
+		assert.Nil(t, err)
+		assert.Equal(t, testPluginName, info.Name)
+		assert.Equal(t, plugin.Dir, info.Dir)
+		assert.NotEmpty(t, info.URL)
+		assert.Len(t, info.Ref, 40)
+		assert.Equal(t, "master", info.Branch)
+		assert.False(t, info.Dirty)
+		assert.False(t, info.UpdatedAt.IsZero())
+	})
+
+	t.Run("returns time branch was updated when HEAD is unchanged", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		cloned := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		updated := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		assert.Nil(t, os.Chtimes(filepath.Join(plugin.Dir, ".git", "HEAD"), cloned, cloned))
+		assert.Nil(t, os.Chtimes(filepath.Join(plugin.Dir, ".git", "refs", "heads", "master"), updated, updated))
+
+		info, err := Build(conf, plugin)
+		assert.Nil(t, err)
+		assert.True(t, updated.Equal(info.UpdatedAt))
+	})
+
+	t.Run("returns dirty when plugin has uncommitted changes", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		assert.Nil(t, os.WriteFile(filepath.Join(plugin.Dir, "bin", "exec-env"), []byte("#!/bin/sh"), 0o777))
+
+		info, err := Build(conf, plugin)
+		assert.Nil(t, err)
+		assert.True(t, info.Dirty)
+	})
+
+	t.Run("returns present and missing callbacks", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+
+		info, err := Build(conf, plugin)
+		assert.Nil(t, err)
+		assert.Subset(t, info.Callbacks, plugins.RequiredCallbacks)
+		assert.Contains(t, info.MissingCallbacks, "exec-env")
+		assert.Len(t, append(info.Callbacks, info.MissingCallbacks...), len(plugins.Callbacks))
+	})
+
+	t.Run("returns extension commands and legacy filenames", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		assert.Nil(t, os.MkdirAll(filepath.Join(plugin.Dir, "lib", "commands"), 0o777))
+		assert.Nil(t, os.WriteFile(filepath.Join(plugin.Dir, "lib", "commands", "command-foo"), []byte(""), 0o777))
+
+		info, err := Build(conf, plugin)
+		assert.Nil(t, err)
+		assert.Contains(t, info.ExtensionCommands, "foo")
+		assert.Equal(t, []string{".dummy-version", ".dummyrc"}, info.LegacyFilenames)
+	})
+
+	t.Run("returns installed versions with disk usage", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		assert.Nil(t, installtest.InstallOneVersion(conf, plugin, "version", "1.0.0"))
+
+		info, err := Build(conf, plugin)
+		assert.Nil(t, err)
+		assert.Len(t, info.Versions, 1)
+		assert.Equal(t, "1.0.0", info.Versions[0].Version)
+		assert.Equal(t, filepath.Join(conf.DataDir, "installs", testPluginName, "1.0.0"), info.Versions[0].Path)
+		assert.Positive(t, info.Versions[0].Size)
+	})
+
+	t.Run("returns manifest when plugin has one", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		assert.Nil(t, os.WriteFile(filepath.Join(plugin.Dir, plugins.ManifestFilename), []byte(`license = "MIT"`), 0o666))
+
+		info, err := Build(conf, plugin)
+		assert.Nil(t, err)
+		assert.Equal(t, &plugins.Manifest{License: "MIT"}, info.Manifest)
+	})
+
+	t.Run("returns no Git state when plugin is not a Git repository", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		assert.Nil(t, os.RemoveAll(filepath.Join(plugin.Dir, ".git")))
+
+		info, err := Build(conf, plugin)
+		assert.Nil(t, err)
+		assert.Empty(t, info.URL)
+		assert.Empty(t, info.Ref)
+		assert.False(t, info.UpdatedAt.IsZero())
+	})
+}
+
+func TestWrite(t *testing.T) {
+	info := Info{
+		Name:              testPluginName,
+		Dir:               "/plugins/lua",
+		Callbacks:         []string{"download", "install", "list-all"},
+		MissingCallbacks:  []string{"exec-env"},
+		ExtensionCommands: []string{"", "foo"},
+		Versions:          []Version{{Version: "1.0.0", Size: 3 * 1024 * 1024}},
+		Manifest:          &plugins.Manifest{License: "MIT", Commands: map[string]string{"foo": "Run foo"}},
+	}
+
+	var out strings.Builder
+	Write(&out, info)
+
+	assert.Equal(t, `name                lua
+dir                 /plugins/lua
+updated             0001-01-01 00:00:00
+callbacks           download, install, list-all
+missing callbacks   exec-env
+extension commands  asdf lua, asdf lua foo
+versions
+  1.0.0             3.0 MiB
+
+plugin.toml
+license             MIT
+commands
+  asdf lua foo      Run foo
+`, out.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	info := Info{Name: testPluginName, Versions: []Version{{Version: "1.0.0", Size: 12}}}
+
+	var out strings.Builder
+	assert.Nil(t, WriteJSON(&out, info))
+
+	var decoded map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(out.String()), &decoded))
+	assert.Equal(t, "lua", decoded["name"])
+	assert.Equal(t, []any{map[string]any{"version": "1.0.0", "path": "", "size": float64(12)}}, decoded["versions"])
+	assert.NotContains(t, decoded, "manifest")
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatSize(512))
+	assert.Equal(t, "1.5 KiB", formatSize(1536))
+	assert.Equal(t, "2.0 GiB", formatSize(2*1024*1024*1024))
+	assert.Equal(t, "2048.0 GiB", formatSize(2*1024*1024*1024*1024))
+}
+
+func generateConfig(t *testing.T) (config.Config, plugins.Plugin) {
+	t.Helper()
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+
+	_, err := repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	return conf, plugins.New(conf, testPluginName)
+}
@@ -0,0 +1,169 @@
+package plugins
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/git"
+	cp "github.com/otiai10/copy"
+)
+
+const fileURLPrefix = "file://"
+
+// localSource returns the path of a plugin source that is a local directory or
+// archive rather than a Git URL. ok is false for Git URLs and for local Git
+// repositories, which are cloned like remote ones so they can be updated.
+func localSource(pluginURL string) (path string, ok bool) {
+	path = strings.TrimPrefix(pluginURL, fileURLPrefix)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+
+	if info.IsDir() && git.NewRepo(path).IsRepository() {
+		return "", false
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+
+	return absPath, true
+}
+
+// installLocal copies a plugin directory, or extracts a plugin archive, to dir
+func installLocal(source, dir string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dir), 0o777)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		err = cp.Copy(source, dir)
+		if err != nil {
+			return fmt.Errorf("unable to copy plugin: %w", err)
+		}
+
+		return nil
+	}
+
+	return extractArchive(source, dir)
+}
+
+// extractArchive extracts a tar archive, optionally gzip compressed, to dir.
+// When all files of the archive are in a single top level directory, as in
+// archives of Git forges, the contents of that directory are extracted.
+func extractArchive(archive, dir string) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), ".extract-")
+	if err != nil {
+		return fmt.Errorf("unable to extract plugin: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = extractTar(archive, tmpDir)
+	if err != nil {
+		return fmt.Errorf("unable to extract plugin: %w", err)
+	}
+
+	root := tmpDir
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(tmpDir, entries[0].Name())
+	}
+
+	return os.Rename(root, dir)
+}
+
+func extractTar(archive, dir string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Detect gzip compression from the magic number so archives don't need a
+	// particular extension
+	buffered := bufio.NewReader(file)
+	var reader io.Reader = buffered
+	if magic, _ := buffered.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, header.Name)
+		if target == dir {
+			continue
+		}
+		if !strings.HasPrefix(target, dir+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %s is outside of the plugin directory", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o777)
+		case tar.TypeReg:
+			err = writeFile(target, tarReader, header.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			linkTarget := filepath.Join(filepath.Dir(target), header.Linkname)
+			if filepath.IsAbs(header.Linkname) || !strings.HasPrefix(linkTarget, dir+string(os.PathSeparator)) {
+				return fmt.Errorf("archive entry %s links outside of the plugin directory", header.Name)
+			}
+			err = os.MkdirAll(filepath.Dir(target), 0o777)
+			if err == nil {
+				err = os.Symlink(header.Linkname, target)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(path string, contents io.Reader, mode os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o777)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, contents)
+	return err
+}
@@ -0,0 +1,214 @@
+package plugins
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddLocal(t *testing.T) {
+	t.Run("copies plugin directory that is not a Git repository", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		source := generateLocalPlugin(t)
+
+		err := Add(conf, testPluginName, source, "")
+		assert.Nil(t, err)
+
+		plugin := New(conf, testPluginName)
+		assert.FileExists(t, filepath.Join(plugin.Dir, "bin", "list-all"))
+		assert.False(t, plugin.IsLinked())
+		assert.DirExists(t, filepath.Join(conf.DataDir, "downloads", testPluginName))
+	})
+
+	t.Run("copies plugin directory given as file URL", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		source := generateLocalPlugin(t)
+
+		err := Add(conf, testPluginName, "file://"+source, "")
+		assert.Nil(t, err)
+		assert.FileExists(t, filepath.Join(conf.DataDir, "plugins", testPluginName, "bin", "list-all"))
+	})
+
+	t.Run("extracts gzipped tar archive with top level directory", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		archive := writeArchive(t, "plugin.tar.gz", map[string]string{
+			"asdf-lua-main/bin/list-all": "echo 1.0.0",
+			"asdf-lua-main/LICENSE":      "MIT",
+		})
+
+		err := Add(conf, testPluginName, archive, "")
+		assert.Nil(t, err)
+
+		listAll := filepath.Join(conf.DataDir, "plugins", testPluginName, "bin", "list-all")
+		assert.FileExists(t, listAll)
+		info, err := os.Stat(listAll)
+		if assert.Nil(t, err) {
+			assert.NotZero(t, info.Mode()&0o111)
+		}
+	})
+
+	t.Run("extracts tar archive without top level directory", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		archive := writeArchive(t, "plugin.tar", map[string]string{
+			"bin/list-all": "echo 1.0.0",
+			"LICENSE":      "MIT",
+		})
+
+		err := Add(conf, testPluginName, archive, "")
+		assert.Nil(t, err)
+		assert.FileExists(t, filepath.Join(conf.DataDir, "plugins", testPluginName, "LICENSE"))
+	})
+
+	t.Run("returns error and removes plugin when archive escapes plugin directory", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		archive := writeArchive(t, "plugin.tar", map[string]string{"../evil": "echo"})
+
+		err := Add(conf, testPluginName, archive, "")
+		assert.ErrorContains(t, err, "archive entry ../evil is outside of the plugin directory")
+		assert.NoDirExists(t, filepath.Join(conf.DataDir, "plugins", testPluginName))
+		assert.NoFileExists(t, filepath.Join(conf.DataDir, "plugins", "evil"))
+	})
+
+	t.Run("returns error when ref is given for local plugin", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+
+		err := Add(conf, testPluginName, generateLocalPlugin(t), "main")
+		assert.ErrorContains(t, err, "a ref can only be given for plugins added from Git repositories")
+	})
+
+	t.Run("lists plugin without Git repository", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		assert.Nil(t, Add(conf, testPluginName, generateLocalPlugin(t), ""))
+
+		plugins, err := List(conf, true, true)
+		assert.Nil(t, err)
+		assert.Len(t, plugins, 1)
+		assert.Empty(t, plugins[0].URL)
+		assert.Empty(t, plugins[0].Ref)
+	})
+
+	t.Run("returns error when updating plugin without Git repository", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		assert.Nil(t, Add(conf, testPluginName, generateLocalPlugin(t), ""))
+
+		_, err := New(conf, testPluginName).Update(conf, "", os.Stdout, os.Stderr)
+		assert.ErrorContains(t, err, "plugin lua was not added from a Git repository")
+	})
+}
+
+func TestLink(t *testing.T) {
+	t.Run("links plugin to directory", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		source := generateLocalPlugin(t)
+
+		err := Link(conf, testPluginName, source)
+		assert.Nil(t, err)
+
+		plugin := New(conf, testPluginName)
+		assert.True(t, plugin.IsLinked())
+		target, err := os.Readlink(plugin.Dir)
+		assert.Nil(t, err)
+		assert.Equal(t, source, target)
+	})
+
+	t.Run("changes to directory take effect without update", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		source := generateLocalPlugin(t)
+		assert.Nil(t, Link(conf, testPluginName, source))
+
+		assert.Nil(t, os.WriteFile(filepath.Join(source, "bin", "exec-env"), []byte("#!/bin/sh"), 0o777))
+
+		_, err := New(conf, testPluginName).CallbackPath("exec-env")
+		assert.Nil(t, err)
+	})
+
+	t.Run("lists linked plugin", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		assert.Nil(t, Link(conf, testPluginName, generateLocalPlugin(t)))
+
+		plugins, err := List(conf, true, true)
+		assert.Nil(t, err)
+		assert.Len(t, plugins, 1)
+		assert.Equal(t, testPluginName, plugins[0].Name)
+	})
+
+	t.Run("returns error when directory does not exist", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+
+		err := Link(conf, testPluginName, filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorContains(t, err, "is not a directory")
+	})
+
+	t.Run("returns error when plugin already exists", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		source := generateLocalPlugin(t)
+		assert.Nil(t, Link(conf, testPluginName, source))
+
+		err := Link(conf, testPluginName, source)
+		assert.IsType(t, PluginAlreadyExists{}, err)
+	})
+
+	t.Run("returns error when updating linked plugin", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		assert.Nil(t, Link(conf, testPluginName, generateLocalPlugin(t)))
+
+		_, err := New(conf, testPluginName).Update(conf, "", os.Stdout, os.Stderr)
+		assert.ErrorContains(t, err, "plugin lua is linked to a directory")
+	})
+
+	t.Run("remove deletes link but not directory", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		source := generateLocalPlugin(t)
+		assert.Nil(t, Link(conf, testPluginName, source))
+
+		var blackhole strings.Builder
+		assert.Nil(t, Remove(conf, testPluginName, &blackhole, &blackhole))
+		assert.NoDirExists(t, filepath.Join(conf.DataDir, "plugins", testPluginName))
+		assert.FileExists(t, filepath.Join(source, "bin", "list-all"))
+	})
+}
+
+// generateLocalPlugin returns the path of a copy of the dummy plugin that is
+// not a Git repository
+func generateLocalPlugin(t *testing.T) string {
+	t.Helper()
+	path, err := repotest.GeneratePlugin("dummy_plugin", t.TempDir(), testPluginName)
+	assert.Nil(t, err)
+	assert.Nil(t, os.RemoveAll(filepath.Join(path, ".git")))
+
+	return path
+}
+
+func writeArchive(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	var tarWriter *tar.Writer
+	if strings.HasSuffix(name, ".gz") {
+		gzipWriter := gzip.NewWriter(file)
+		defer gzipWriter.Close()
+		tarWriter = tar.NewWriter(gzipWriter)
+	} else {
+		tarWriter = tar.NewWriter(file)
+	}
+	defer tarWriter.Close()
+
+	for filename, contents := range files {
+		header := &tar.Header{Name: filename, Mode: 0o755, Size: int64(len(contents)), Typeflag: tar.TypeReg}
+		assert.Nil(t, tarWriter.WriteHeader(header))
+		_, err := tarWriter.Write([]byte(contents))
+		assert.Nil(t, err)
+	}
+
+	return path
+}
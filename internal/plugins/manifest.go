@@ -0,0 +1,144 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/go-version"
+)
+
+// ManifestFilename is the name of the optional manifest in the root directory
+// of a plugin
+const ManifestFilename = "plugin.toml"
+
+// RequiredCallbacks are the callbacks every plugin must provide
+var RequiredCallbacks = []string{"download", "install", "list-all"}
+
+// Callbacks are all the callbacks asdf runs, in the order they are documented
+var Callbacks = []string{
+	"download", "install", "list-all", "latest-stable", "help.overview",
+	"help.deps", "help.config", "help.links", "list-bin-paths", "exec-env",
+	"exec-path", "uninstall", "list-legacy-filenames", "parse-legacy-file",
+	"post-plugin-add", "post-plugin-update", "pre-plugin-remove",
+}
+
+// Manifest is the metadata a plugin declares in its plugin.toml file. Every
+// field is optional. Fields that are set take precedence over the callbacks
+// asdf would otherwise run to discover the same information.
+type Manifest struct {
+	Name        string `toml:"name" json:"name,omitempty"`
+	Description string `toml:"description" json:"description,omitempty"`
+	Homepage    string `toml:"homepage" json:"homepage,omitempty"`
+	License     string `toml:"license" json:"license,omitempty"`
+	// MinAsdfVersion is the oldest asdf version the plugin works with
+	MinAsdfVersion string `toml:"min_asdf_version" json:"min_asdf_version,omitempty"`
+	// OS and Arch list the platforms the tool can be installed on, using the
+	// names of uname or Go. An empty list means every platform is supported.
+	OS   []string `toml:"os" json:"os,omitempty"`
+	Arch []string `toml:"arch" json:"arch,omitempty"`
+	// LegacyFilenames replaces the list-legacy-filenames callback
+	LegacyFilenames []string `toml:"legacy_filenames" json:"legacy_filenames,omitempty"`
+	// BinPaths replaces the list-bin-paths callback
+	BinPaths []string `toml:"bin_paths" json:"bin_paths,omitempty"`
+	// Dependencies are the names of other plugins the tool needs
+	Dependencies []string `toml:"dependencies" json:"dependencies,omitempty"`
+	// Commands maps the names of extension commands to their description. The
+	// default command is named "".
+	Commands map[string]string `toml:"commands" json:"commands,omitempty"`
+}
+
+// UnsupportedPlatformError is returned when a plugin's manifest does not list
+// the current platform as supported
+type UnsupportedPlatformError struct {
+	plugin string
+	os     string
+	arch   string
+}
+
+func (e UnsupportedPlatformError) Error() string {
+	return fmt.Sprintf("plugin %s does not support %s/%s", e.plugin, e.os, e.arch)
+}
+
+// platformAliases maps the names uname prints to the names Go uses
+var platformAliases = map[string]string{
+	"x86_64":  "amd64",
+	"aarch64": "arm64",
+	"i386":    "386",
+	"i686":    "386",
+	"macos":   "darwin",
+}
+
+// Manifest reads the plugin's manifest. found is false when the plugin has no
+// manifest. The file is read every time the method is called so that it is
+// only read by the operations that need it.
+func (p Plugin) Manifest() (manifest Manifest, found bool, err error) {
+	path := filepath.Join(p.Dir, ManifestFilename)
+	_, err = toml.DecodeFile(path, &manifest)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, false, nil
+	}
+	if err != nil {
+		return manifest, false, fmt.Errorf("invalid %s of plugin %s: %w", ManifestFilename, p.Name, err)
+	}
+
+	return manifest, true, nil
+}
+
+// CheckPlatform returns an UnsupportedPlatformError when the plugin's manifest
+// restricts the platforms the tool can be installed on and goos/goarch is not
+// one of them
+func (p Plugin) CheckPlatform(goos, goarch string) error {
+	manifest, found, err := p.Manifest()
+	if err != nil || !found {
+		return err
+	}
+
+	if !manifest.Supports(goos, goarch) {
+		return UnsupportedPlatformError{plugin: p.Name, os: goos, arch: goarch}
+	}
+
+	return nil
+}
+
+// Supports returns true when the manifest does not exclude the platform
+func (m Manifest) Supports(goos, goarch string) bool {
+	return platformListed(m.OS, goos) && platformListed(m.Arch, goarch)
+}
+
+// SupportsAsdfVersion returns false when the manifest requires a newer asdf
+// version than asdfVersion. Development builds, whose version cannot be
+// parsed, support every plugin.
+func (m Manifest) SupportsAsdfVersion(asdfVersion string) bool {
+	if m.MinAsdfVersion == "" {
+		return true
+	}
+
+	current, err := version.NewVersion(asdfVersion)
+	if err != nil {
+		return true
+	}
+
+	minimum, err := version.NewVersion(m.MinAsdfVersion)
+	if err != nil {
+		return true
+	}
+
+	return !current.LessThan(minimum)
+}
+
+func platformListed(list []string, name string) bool {
+	if len(list) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(list, func(listed string) bool {
+		if alias, ok := platformAliases[listed]; ok {
+			listed = alias
+		}
+		return listed == name
+	})
+}
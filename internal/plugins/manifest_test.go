@@ -0,0 +1,121 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testManifest = `name = "lua"
+description = "Lua programming language"
+homepage = "https://www.lua.org"
+license = "MIT"
+min_asdf_version = "0.16.0"
+os = ["linux", "macos"]
+arch = ["x86_64", "arm64"]
+legacy_filenames = [".lua-version"]
+bin_paths = ["bin", "luarocks/bin"]
+dependencies = ["python"]
+
+[commands]
+"" = "Show lua help"
+foo = "Run foo"
+`
+
+func TestManifest(t *testing.T) {
+	t.Run("returns not found when plugin has no manifest", func(t *testing.T) {
+		plugin := generateManifestPlugin(t, "")
+
+		_, found, err := plugin.Manifest()
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("returns parsed manifest", func(t *testing.T) {
+		plugin := generateManifestPlugin(t, testManifest)
+
+		manifest, found, err := plugin.Manifest()
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, Manifest{
+			Name:            "lua",
+			Description:     "Lua programming language",
+			Homepage:        "https://www.lua.org",
+			License:         "MIT",
+			MinAsdfVersion:  "0.16.0",
+			OS:              []string{"linux", "macos"},
+			Arch:            []string{"x86_64", "arm64"},
+			LegacyFilenames: []string{".lua-version"},
+			BinPaths:        []string{"bin", "luarocks/bin"},
+			Dependencies:    []string{"python"},
+			Commands:        map[string]string{"": "Show lua help", "foo": "Run foo"},
+		}, manifest)
+	})
+
+	t.Run("returns error when manifest is invalid", func(t *testing.T) {
+		plugin := generateManifestPlugin(t, "name = ")
+
+		_, _, err := plugin.Manifest()
+		assert.ErrorContains(t, err, "invalid plugin.toml of plugin lua")
+	})
+}
+
+func TestLegacyFilenamesFromManifest(t *testing.T) {
+	plugin := generateManifestPlugin(t, testManifest)
+
+	filenames, err := plugin.LegacyFilenames(config.Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{".lua-version"}, filenames)
+}
+
+func TestCheckPlatform(t *testing.T) {
+	plugin := generateManifestPlugin(t, testManifest)
+
+	t.Run("returns nil for supported platform", func(t *testing.T) {
+		assert.Nil(t, plugin.CheckPlatform("darwin", "amd64"))
+	})
+
+	t.Run("returns error for unsupported os", func(t *testing.T) {
+		err := plugin.CheckPlatform("windows", "amd64")
+		assert.ErrorIs(t, err, UnsupportedPlatformError{plugin: "lua", os: "windows", arch: "amd64"})
+		assert.ErrorContains(t, err, "plugin lua does not support windows/amd64")
+	})
+
+	t.Run("returns error for unsupported arch", func(t *testing.T) {
+		assert.NotNil(t, plugin.CheckPlatform("linux", "386"))
+	})
+
+	t.Run("returns nil when plugin has no manifest", func(t *testing.T) {
+		plugin := generateManifestPlugin(t, "")
+		assert.Nil(t, plugin.CheckPlatform("windows", "386"))
+	})
+}
+
+func TestSupportsAsdfVersion(t *testing.T) {
+	manifest := Manifest{MinAsdfVersion: "0.16.0"}
+
+	assert.True(t, manifest.SupportsAsdfVersion("v0.16.0"))
+	assert.True(t, manifest.SupportsAsdfVersion("0.17.1"))
+	assert.False(t, manifest.SupportsAsdfVersion("v0.15.0"))
+	assert.True(t, manifest.SupportsAsdfVersion("v-dev"))
+	assert.True(t, Manifest{}.SupportsAsdfVersion("v0.1.0"))
+}
+
+func generateManifestPlugin(t *testing.T, manifest string) Plugin {
+	t.Helper()
+	testDataDir := t.TempDir()
+	_, err := repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+	plugin := New(config.Config{DataDir: testDataDir}, testPluginName)
+
+	if manifest != "" {
+		err = os.WriteFile(filepath.Join(plugin.Dir, ManifestFilename), []byte(manifest), 0o666)
+		assert.Nil(t, err)
+	}
+
+	return plugin
+}
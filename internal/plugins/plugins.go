@@ -2,6 +2,7 @@
 package plugins
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -93,12 +94,20 @@ func New(config config.Config, name string) Plugin {
 	return Plugin{Dir: pluginsDir, Name: name}
 }
 
-// LegacyFilenames returns a slice of filenames if the plugin contains the
-// list-legacy-filenames callback.
-func (p Plugin) LegacyFilenames() (filenames []string, err error) {
+// LegacyFilenames returns a slice of filenames if the plugin declares them in
+// its manifest or contains the list-legacy-filenames callback.
+func (p Plugin) LegacyFilenames(conf config.Config) (filenames []string, err error) {
+	manifest, found, err := p.Manifest()
+	if err != nil {
+		return []string{}, err
+	}
+	if found && manifest.LegacyFilenames != nil {
+		return manifest.LegacyFilenames, nil
+	}
+
 	var stdOut strings.Builder
 	var stdErr strings.Builder
-	err = p.RunCallback("list-legacy-filenames", []string{}, map[string]string{}, &stdOut, &stdErr)
+	err = p.RunCallback(conf, "list-legacy-filenames", []string{}, map[string]string{}, &stdOut, &stdErr)
 	if err != nil {
 		_, ok := err.(NoCallbackError)
 		if ok {
@@ -118,7 +127,7 @@ func (p Plugin) LegacyFilenames() (filenames []string, err error) {
 // script to parse it if the script is present. Otherwise just reads the file
 // directly. In either case the returned string is split on spaces and a slice
 // of versions is returned.
-func (p Plugin) ParseLegacyVersionFile(path string) (versions []string, err error) {
+func (p Plugin) ParseLegacyVersionFile(conf config.Config, path string) (versions []string, err error) {
 	parseLegacyFileName := "parse-legacy-file"
 	parseCallbackPath := filepath.Join(p.Dir, "bin", parseLegacyFileName)
 
@@ -128,7 +137,7 @@ func (p Plugin) ParseLegacyVersionFile(path string) (versions []string, err erro
 		var stdOut strings.Builder
 		var stdErr strings.Builder
 
-		err = p.RunCallback(parseLegacyFileName, []string{path}, map[string]string{}, &stdOut, &stdErr)
+		err = p.RunCallback(conf, parseLegacyFileName, []string{path}, map[string]string{}, &stdOut, &stdErr)
 		if err != nil {
 			return versions, err
 		}
@@ -164,20 +173,39 @@ func (p Plugin) Exists() error {
 	return nil
 }
 
-// RunCallback invokes a callback with the given name if it exists for the plugin
-func (p Plugin) RunCallback(name string, arguments []string, environment map[string]string, stdOut io.Writer, errOut io.Writer) error {
+// RunCallback invokes a callback with the given name if it exists for the
+// plugin. The callback is killed when it runs for longer than the
+// callback_timeout settings in conf allow.
+func (p Plugin) RunCallback(conf config.Config, name string, arguments []string, environment map[string]string, stdOut io.Writer, errOut io.Writer) error {
+	return p.RunCallbackContext(context.Background(), conf, name, arguments, environment, stdOut, errOut)
+}
+
+// RunCallbackContext invokes a callback with the given name if it exists for
+// the plugin. The callback is killed when ctx is done or when it runs for
+// longer than the callback_timeout settings in conf allow.
+func (p Plugin) RunCallbackContext(ctx context.Context, conf config.Config, name string, arguments []string, environment map[string]string, stdOut io.Writer, errOut io.Writer) error {
 	callback, err := p.CallbackPath(name)
 	if err != nil {
 		return err
 	}
 
-	cmd := execute.New(fmt.Sprintf("'%s'", callback), arguments)
+	cmd := execute.New(callback, arguments)
 	cmd.Env = environment
 
 	cmd.Stdout = stdOut
 	cmd.Stderr = errOut
 
-	return cmd.Run()
+	cmd.Timeout, err = conf.CallbackTimeout(name)
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RunContext(ctx)
+	if _, ok := err.(execute.TimeoutError); ok {
+		return fmt.Errorf("%s callback of plugin %s %w", name, p.Name, err)
+	}
+
+	return err
 }
 
 // CallbackPath returns the full file path to a callback script
@@ -237,37 +265,6 @@ func (p Plugin) ExtensionCommandPath(name string) (string, error) {
 	return path, nil
 }
 
-// Update a plugin to a specific ref, or if no ref provided update to latest
-func (p Plugin) Update(conf config.Config, ref string, out, errout io.Writer) (string, error) {
-	err := p.Exists()
-	if err != nil {
-		return "", fmt.Errorf("no such plugin: %s", p.Name)
-	}
-
-	repo := git.NewRepo(p.Dir)
-
-	hook.Run(conf, "pre_asdf_plugin_update", []string{p.Name})
-	hook.Run(conf, fmt.Sprintf("pre_asdf_plugin_update_%s", p.Name), []string{p.Name})
-
-	newRef, oldSHA, newSHA, err := repo.Update(ref)
-	if err != nil {
-		return newRef, err
-	}
-
-	env := map[string]string{
-		"ASDF_PLUGIN_PATH":     p.Dir,
-		"ASDF_PLUGIN_PREV_REF": oldSHA,
-		"ASDF_PLUGIN_POST_REF": newSHA,
-	}
-
-	err = p.RunCallback("post-plugin-update", []string{}, env, out, errout)
-
-	hook.Run(conf, "post_asdf_plugin_update", []string{p.Name})
-	hook.Run(conf, fmt.Sprintf("post_asdf_plugin_update_%s", p.Name), []string{})
-
-	return newRef, err
-}
-
 // List takes config and flags for what to return and builds a list of plugins
 // representing the currently installed plugins on the system.
 func List(config config.Config, urls, refs bool) (plugins []Plugin, err error) {
@@ -282,18 +279,19 @@ func List(config config.Config, urls, refs bool) (plugins []Plugin, err error) {
 	}
 
 	for _, file := range files {
-		if file.IsDir() {
+		// Linked plugins are symlinks to directories
+		isDir, err := directoryExists(filepath.Join(pluginsDir, file.Name()))
+		if err != nil {
+			return plugins, err
+		}
+
+		if isDir {
 			if refs || urls {
 				var url string
 				var refString string
 				location := filepath.Join(pluginsDir, file.Name())
 				repo := git.NewRepo(location)
 
-				// TODO: Improve these error messages
-				if err != nil {
-					return plugins, err
-				}
-
 				if refs {
 					refString, err = repo.Head()
 					if err != nil {
@@ -327,24 +325,14 @@ func List(config config.Config, urls, refs bool) (plugins []Plugin, err error) {
 }
 
 // Add takes plugin name and Git URL and installs the plugin if it isn't
-// already installed
+// already installed. The URL may also be the path, or file:// URL, of a local
+// plugin directory, which is copied, or of a tar archive, which is extracted.
 func Add(config config.Config, pluginName, pluginURL, ref string) error {
-	err := validatePluginName(pluginName)
+	plugin, err := newPluginToAdd(config, pluginName)
 	if err != nil {
 		return err
 	}
 
-	exists, err := PluginExists(config.DataDir, pluginName)
-	if err != nil {
-		return fmt.Errorf("unable to check if plugin already exists: %w", err)
-	}
-
-	if exists {
-		return NewPluginAlreadyExists(pluginName)
-	}
-
-	plugin := New(config, pluginName)
-
 	if pluginURL == "" {
 		// Ignore error here as the default value is fine
 		disablePluginIndex, _ := config.DisablePluginShortNameRepository()
@@ -361,9 +349,13 @@ func Add(config config.Config, pluginName, pluginURL, ref string) error {
 			lastCheckDuration = checkDuration.Every
 		}
 
-		index := pluginindex.Build(config.DataDir, config.PluginIndexURL, false, lastCheckDuration)
-		var err error
-		pluginURL, err = index.GetPluginSourceURL(pluginName)
+		sources, err := config.PluginIndexes()
+		if err != nil {
+			return err
+		}
+
+		indexes := pluginindex.BuildIndexes(config.DataDir, sources, false, lastCheckDuration)
+		pluginURL, err = indexes.GetPluginSourceURL(pluginName)
 		if err != nil {
 			return fmt.Errorf("error fetching plugin URL: %s", err)
 		}
@@ -371,12 +363,90 @@ func Add(config config.Config, pluginName, pluginURL, ref string) error {
 
 	plugin.URL = pluginURL
 
+	return add(config, plugin, func() error {
+		if source, ok := localSource(plugin.URL); ok {
+			if ref != "" {
+				return fmt.Errorf("a ref can only be given for plugins added from Git repositories")
+			}
+
+			return installLocal(source, plugin.Dir)
+		}
+
+		return git.NewRepo(plugin.Dir).Clone(plugin.URL, ref)
+	})
+}
+
+// Link adds a plugin that is a symlink to a plugin directory, usually the
+// working copy of a plugin under development. Changes to the directory take
+// effect immediately, without updating the plugin.
+func Link(config config.Config, pluginName, dir string) error {
+	plugin, err := newPluginToAdd(config, pluginName)
+	if err != nil {
+		return err
+	}
+
+	plugin.URL, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	exists, err := directoryExists(plugin.URL)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("unable to link plugin: %s is not a directory", dir)
+	}
+
+	return add(config, plugin, func() error {
+		err := os.MkdirAll(data.PluginsDirectory(config.DataDir), 0o777)
+		if err != nil {
+			return err
+		}
+
+		return os.Symlink(plugin.URL, plugin.Dir)
+	})
+}
+
+// IsLinked returns true when the plugin was added with Link
+func (p Plugin) IsLinked() bool {
+	fileInfo, err := os.Lstat(p.Dir)
+	return err == nil && fileInfo.Mode()&os.ModeSymlink != 0
+}
+
+func newPluginToAdd(config config.Config, pluginName string) (Plugin, error) {
+	err := ValidateName(pluginName)
+	if err != nil {
+		return Plugin{}, err
+	}
+
+	exists, err := PluginExists(config.DataDir, pluginName)
+	if err != nil {
+		return Plugin{}, fmt.Errorf("unable to check if plugin already exists: %w", err)
+	}
+
+	if exists {
+		return Plugin{}, NewPluginAlreadyExists(pluginName)
+	}
+
+	return New(config, pluginName), nil
+}
+
+// add runs the hooks and callbacks around install, which puts the plugin in
+// place
+func add(config config.Config, plugin Plugin, install func() error) error {
 	// Run pre hooks
 	hook.Run(config, "pre_asdf_plugin_add", []string{plugin.Name})
 	hook.Run(config, fmt.Sprintf("pre_asdf_plugin_add_%s", plugin.Name), []string{})
 
-	err = git.NewRepo(plugin.Dir).Clone(plugin.URL, ref)
+	err := install()
+	if err == nil {
+		// Verify before the post-plugin-add callback runs code from the plugin
+		err = plugin.verifyIfRequired(config)
+	}
 	if err != nil {
+		// Don't leave a partially installed or unverified plugin behind
+		os.RemoveAll(plugin.Dir)
 		return err
 	}
 
@@ -386,7 +456,7 @@ func Add(config config.Config, pluginName, pluginURL, ref string) error {
 	}
 
 	env := map[string]string{"ASDF_PLUGIN_SOURCE_URL": plugin.URL, "ASDF_PLUGIN_PATH": plugin.Dir}
-	plugin.RunCallback("post-plugin-add", []string{}, env, os.Stdout, os.Stderr)
+	plugin.RunCallback(config, "post-plugin-add", []string{}, env, os.Stdout, os.Stderr)
 
 	// Run post hooks
 	hook.Run(config, "post_asdf_plugin_add", []string{plugin.Name})
@@ -397,7 +467,7 @@ func Add(config config.Config, pluginName, pluginURL, ref string) error {
 
 // Remove uninstalls a plugin by removing it from the file system if installed
 func Remove(config config.Config, pluginName string, stdout, stderr io.Writer) error {
-	err := validatePluginName(pluginName)
+	err := ValidateName(pluginName)
 	if err != nil {
 		return err
 	}
@@ -420,7 +490,7 @@ func Remove(config config.Config, pluginName string, stdout, stderr io.Writer) e
 		"ASDF_PLUGIN_PATH":       plugin.Dir,
 		"ASDF_PLUGIN_SOURCE_URL": plugin.URL,
 	}
-	plugin.RunCallback("pre-plugin-remove", []string{}, env, stdout, stderr)
+	plugin.RunCallback(config, "pre-plugin-remove", []string{}, env, stdout, stderr)
 
 	pluginDir := data.PluginDirectory(config.DataDir, pluginName)
 	downloadDir := data.DownloadDirectory(config.DataDir, pluginName)
@@ -429,6 +499,8 @@ func Remove(config config.Config, pluginName string, stdout, stderr io.Writer) e
 	err = os.RemoveAll(downloadDir)
 	err2 := os.RemoveAll(pluginDir)
 	err3 := os.RemoveAll(installDir)
+	// The plugin has no metadata file until it is updated
+	os.Remove(data.PluginMetadataFile(config.DataDir, pluginName))
 
 	if err != nil {
 		return err
@@ -464,7 +536,8 @@ func directoryExists(dir string) (bool, error) {
 	return fileInfo.IsDir(), nil
 }
 
-func validatePluginName(name string) error {
+// ValidateName returns an error when name can't be the name of a plugin
+func ValidateName(name string) error {
 	match, err := regexp.MatchString("^[[:lower:][:digit:]_-]+$", name)
 	if err != nil {
 		return err
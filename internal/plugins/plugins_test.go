@@ -1,15 +1,19 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/asdf-vm/asdf/internal/config"
 	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/execute"
 	"github.com/asdf-vm/asdf/internal/repotest"
+	gogit "github.com/go-git/go-git/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -168,6 +172,37 @@ func TestAdd(t *testing.T) {
 		_, err = os.Stat(downloadDir)
 		assert.Nil(t, err)
 	})
+
+	t.Run("when ref is a short commit SHA checks out commit", func(t *testing.T) {
+		testDataDir := t.TempDir()
+		conf := config.Config{DataDir: testDataDir}
+
+		repoPath, err := repotest.GeneratePlugin("dummy_plugin", testDataDir, testPluginName)
+		assert.Nil(t, err)
+		source, err := gogit.PlainOpen(repoPath)
+		assert.Nil(t, err)
+		hash, err := source.ResolveRevision("HEAD~")
+		assert.Nil(t, err)
+
+		err = Add(conf, testPluginName, repoPath, hash.String()[:7])
+		assert.Nil(t, err)
+
+		plugins, err := List(conf, false, true)
+		assert.Nil(t, err)
+		assert.Equal(t, hash.String(), plugins[0].Ref)
+	})
+
+	t.Run("when ref does not exist returns error and removes plugin", func(t *testing.T) {
+		testDataDir := t.TempDir()
+		conf := config.Config{DataDir: testDataDir}
+
+		repoPath, err := repotest.GeneratePlugin("dummy_plugin", testDataDir, testPluginName)
+		assert.Nil(t, err)
+
+		err = Add(conf, testPluginName, repoPath, "non-existent")
+		assert.ErrorContains(t, err, "no branch, tag or commit named \"non-existent\"")
+		assert.NoDirExists(t, data.PluginDirectory(testDataDir, testPluginName))
+	})
 }
 
 func TestRemove(t *testing.T) {
@@ -262,7 +297,7 @@ func TestUpdate(t *testing.T) {
 			givenName:   "badplugin",
 			givenRef:    "",
 			wantSomeRef: false,
-			wantErrMsg:  "unable to open plugin Git repository: repository does not exist",
+			wantErrMsg:  "plugin badplugin was not added from a Git repository",
 		},
 		{
 			desc:        "updates plugin when plugin with name exists",
@@ -278,7 +313,7 @@ func TestUpdate(t *testing.T) {
 		t.Run(tt.desc, func(t *testing.T) {
 			var blackhole strings.Builder
 			plugin := New(conf, tt.givenName)
-			updatedToRef, err := plugin.Update(tt.givenConf, tt.givenRef, &blackhole, &blackhole)
+			result, err := plugin.Update(tt.givenConf, tt.givenRef, &blackhole, &blackhole)
 
 			if tt.wantErrMsg == "" {
 				assert.Nil(t, err)
@@ -288,9 +323,9 @@ func TestUpdate(t *testing.T) {
 			}
 
 			if tt.wantSomeRef == true {
-				assert.NotZero(t, updatedToRef)
+				assert.NotZero(t, result.Ref)
 			} else {
-				assert.Zero(t, updatedToRef)
+				assert.Zero(t, result.Ref)
 			}
 		})
 	}
@@ -365,9 +400,9 @@ func TestPluginExists(t *testing.T) {
 	})
 }
 
-func TestValidatePluginName(t *testing.T) {
+func TestValidateName(t *testing.T) {
 	t.Run("returns no error when plugin name is valid", func(t *testing.T) {
-		err := validatePluginName(testPluginName)
+		err := ValidateName(testPluginName)
 		assert.Nil(t, err)
 	})
 
@@ -375,7 +410,7 @@ func TestValidatePluginName(t *testing.T) {
 
 	for _, invalid := range invalids {
 		t.Run(invalid, func(t *testing.T) {
-			err := validatePluginName(invalid)
+			err := ValidateName(invalid)
 
 			if err == nil {
 				t.Error("Expected an error")
@@ -398,7 +433,7 @@ func TestRunCallback(t *testing.T) {
 		var stdout strings.Builder
 		var stderr strings.Builder
 
-		err = plugin.RunCallback("non-existent", []string{}, emptyEnv, &stdout, &stderr)
+		err = plugin.RunCallback(conf, "non-existent", []string{}, emptyEnv, &stdout, &stderr)
 
 		assert.Equal(t, err.(NoCallbackError).Error(), "Plugin named lua does not have a callback named non-existent")
 	})
@@ -407,7 +442,7 @@ func TestRunCallback(t *testing.T) {
 		var stdout strings.Builder
 		var stderr strings.Builder
 
-		err = plugin.RunCallback("debug", []string{"123"}, emptyEnv, &stdout, &stderr)
+		err = plugin.RunCallback(conf, "debug", []string{"123"}, emptyEnv, &stdout, &stderr)
 		assert.Nil(t, err)
 		assert.Equal(t, "123\n", stdout.String())
 		assert.Equal(t, "", stderr.String())
@@ -417,7 +452,7 @@ func TestRunCallback(t *testing.T) {
 		var stdout strings.Builder
 		var stderr strings.Builder
 
-		err = plugin.RunCallback("debug", []string{"123", "test string"}, emptyEnv, &stdout, &stderr)
+		err = plugin.RunCallback(conf, "debug", []string{"123", "test string"}, emptyEnv, &stdout, &stderr)
 		assert.Nil(t, err)
 		assert.Equal(t, "123 test string\n", stdout.String())
 		assert.Equal(t, "", stderr.String())
@@ -427,13 +462,48 @@ func TestRunCallback(t *testing.T) {
 		var stdout strings.Builder
 		var stderr strings.Builder
 
-		err = plugin.RunCallback("post-plugin-update", []string{}, map[string]string{"ASDF_PLUGIN_PREV_REF": "TEST"}, &stdout, &stderr)
+		err = plugin.RunCallback(conf, "post-plugin-update", []string{}, map[string]string{"ASDF_PLUGIN_PREV_REF": "TEST"}, &stdout, &stderr)
 		assert.Nil(t, err)
 		assert.Equal(t, "plugin updated path= old git-ref=TEST new git-ref=\n", stdout.String())
 		assert.Equal(t, "", stderr.String())
 	})
 }
 
+func TestRunCallbackContext(t *testing.T) {
+	testDataDir := t.TempDir()
+	asdfrc := filepath.Join(testDataDir, ".asdfrc")
+	err := os.WriteFile(asdfrc, []byte("callback_timeout_list_all = 100ms\n"), 0o666)
+	assert.Nil(t, err)
+	conf := config.Config{DataDir: testDataDir, ConfigFile: asdfrc}
+	_, err = repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	plugin := New(conf, testPluginName)
+	err = repotest.WritePluginCallback(plugin.Dir, "list-all", "#!/usr/bin/env bash\nsleep 10\n")
+	assert.Nil(t, err)
+
+	t.Run("returns timeout error when callback runs longer than its timeout", func(t *testing.T) {
+		var stdout strings.Builder
+		var stderr strings.Builder
+
+		err := plugin.RunCallbackContext(context.Background(), conf, "list-all", []string{}, map[string]string{}, &stdout, &stderr)
+
+		assert.ErrorIs(t, err, execute.TimeoutError{Timeout: 100 * time.Millisecond})
+		assert.ErrorContains(t, err, "list-all callback of plugin lua timed out after 100ms")
+	})
+
+	t.Run("returns context error when context is canceled", func(t *testing.T) {
+		var stdout strings.Builder
+		var stderr strings.Builder
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := plugin.RunCallbackContext(ctx, conf, "debug", []string{}, map[string]string{}, &stdout, &stderr)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
 func TestCallbackPath(t *testing.T) {
 	testDataDir := t.TempDir()
 	conf := config.Config{DataDir: testDataDir}
@@ -536,7 +606,7 @@ func TestLegacyFilenames(t *testing.T) {
 	plugin := New(conf, testPluginName)
 
 	t.Run("returns list of filenames when list-legacy-filenames callback is present", func(t *testing.T) {
-		filenames, err := plugin.LegacyFilenames()
+		filenames, err := plugin.LegacyFilenames(conf)
 		assert.Nil(t, err)
 		assert.Equal(t, filenames, []string{".dummy-version", ".dummyrc"})
 	})
@@ -547,7 +617,7 @@ func TestLegacyFilenames(t *testing.T) {
 		assert.Nil(t, err)
 		plugin := New(conf, testPluginName)
 
-		filenames, err := plugin.LegacyFilenames()
+		filenames, err := plugin.LegacyFilenames(conf)
 		assert.Nil(t, err)
 		assert.Equal(t, filenames, []string{})
 	})
@@ -572,19 +642,19 @@ func TestParseLegacyVersionFile(t *testing.T) {
 		assert.Nil(t, err)
 		plugin := New(conf, testPluginName)
 
-		versions, err := plugin.ParseLegacyVersionFile(path)
+		versions, err := plugin.ParseLegacyVersionFile(conf, path)
 		assert.Nil(t, err)
 		assert.Equal(t, versions, []string{"dummy-1.2.3"})
 	})
 
 	t.Run("returns file contents parsed by parse-legacy-file callback when it is present", func(t *testing.T) {
-		versions, err := plugin.ParseLegacyVersionFile(path)
+		versions, err := plugin.ParseLegacyVersionFile(conf, path)
 		assert.Nil(t, err)
 		assert.Equal(t, versions, []string{"1.2.3"})
 	})
 
 	t.Run("returns error when passed file that doesn't exist", func(t *testing.T) {
-		versions, err := plugin.ParseLegacyVersionFile("non-existent-file")
+		versions, err := plugin.ParseLegacyVersionFile(conf, "non-existent-file")
 		assert.Error(t, err)
 		assert.Empty(t, versions)
 	})
@@ -0,0 +1,81 @@
+package plugins
+
+import (
+	"sync"
+
+	"github.com/asdf-vm/asdf/internal/git"
+)
+
+// RemoteStatus is the state of a plugin's Git repository compared with its
+// remote
+type RemoteStatus struct {
+	Name string `json:"name"`
+	// Branch is the current branch, it is empty when HEAD is detached
+	Branch   string `json:"branch"`
+	Detached bool   `json:"detached"`
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	// Dirty is true when the plugin has local modifications
+	Dirty bool `json:"dirty"`
+	// Error is why the status couldn't be determined, for example because the
+	// remote can't be reached or the plugin is not a Git repository
+	Error string `json:"error,omitempty"`
+}
+
+// Outdated returns true when the remote has commits the plugin doesn't
+func (s RemoteStatus) Outdated() bool {
+	return s.Behind > 0
+}
+
+// CheckRemotes fetches the remote of each plugin, without updating the
+// plugins, and compares them with it, running at most jobs fetches at once.
+// newRepo returns the repository of a plugin directory, it is git.NewRepo
+// outside of tests. Statuses are in the order of plugins.
+func CheckRemotes(plugins []Plugin, newRepo func(dir string) git.Repoer, jobs int) []RemoteStatus {
+	statuses := make([]RemoteStatus, len(plugins))
+	parallel(len(plugins), jobs, func(index int) {
+		statuses[index] = checkRemote(plugins[index], newRepo(plugins[index].Dir))
+	})
+
+	return statuses
+}
+
+func checkRemote(plugin Plugin, repo git.Repoer) RemoteStatus {
+	status := RemoteStatus{Name: plugin.Name}
+
+	gitStatus, err := repo.Status()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Branch = gitStatus.Branch
+	status.Detached = gitStatus.Branch == ""
+	status.Ahead = gitStatus.Ahead
+	status.Behind = gitStatus.Behind
+	status.Dirty = gitStatus.Dirty
+	return status
+}
+
+// parallel calls f with every index from 0 to count - 1, running at most jobs
+// calls at once
+func parallel(count, jobs int, f func(index int)) {
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(max(jobs, 1), count) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				f(index)
+			}
+		}()
+	}
+
+	for index := range count {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,70 @@
+package plugins
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockRepo struct {
+	status git.Status
+	err    error
+}
+
+// Only defined so MockRepo complies with git.Repoer interface. These are not
+// used by CheckRemotes
+func (m MockRepo) Branch() (string, error)                         { return "", nil }
+func (m MockRepo) Clone(_, _ string) error                         { return nil }
+func (m MockRepo) DefaultBranch() (string, error)                  { return "", nil }
+func (m MockRepo) Head() (string, error)                           { return "", nil }
+func (m MockRepo) RemoteURL() (string, error)                      { return "", nil }
+func (m MockRepo) Update(_ string) (string, string, string, error) { return "", "", "", nil }
+
+func (m MockRepo) Status() (git.Status, error) { return m.status, m.err }
+
+func TestCheckRemotes(t *testing.T) {
+	repos := map[string]MockRepo{
+		"/plugins/current":  {status: git.Status{Branch: "main"}},
+		"/plugins/behind":   {status: git.Status{Branch: "main", Behind: 3, Ahead: 1, Dirty: true}},
+		"/plugins/detached": {status: git.Status{}},
+		"/plugins/offline":  {err: errors.New("unable to fetch remote")},
+	}
+	plugins := []Plugin{
+		{Name: "current", Dir: "/plugins/current"},
+		{Name: "behind", Dir: "/plugins/behind"},
+		{Name: "detached", Dir: "/plugins/detached"},
+		{Name: "offline", Dir: "/plugins/offline"},
+	}
+
+	statuses := CheckRemotes(plugins, func(dir string) git.Repoer { return repos[dir] }, 2)
+
+	assert.Equal(t, []RemoteStatus{
+		{Name: "current", Branch: "main"},
+		{Name: "behind", Branch: "main", Ahead: 1, Behind: 3, Dirty: true},
+		{Name: "detached", Detached: true},
+		{Name: "offline", Error: "unable to fetch remote"},
+	}, statuses)
+	assert.False(t, statuses[0].Outdated())
+	assert.True(t, statuses[1].Outdated())
+}
+
+func TestParallel(t *testing.T) {
+	var running, maxRunning, calls atomic.Int32
+	parallel(20, 3, func(_ int) {
+		current := running.Add(1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		calls.Add(1)
+		running.Add(-1)
+	})
+
+	assert.Equal(t, int32(20), calls.Load())
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+}
@@ -0,0 +1,300 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/hook"
+)
+
+// NotUpdatableError is returned when updating a plugin that is linked to a
+// directory or was not added from a Git repository
+type NotUpdatableError struct {
+	plugin string
+	linked bool
+}
+
+func (e NotUpdatableError) Error() string {
+	if e.linked {
+		return fmt.Sprintf("plugin %s is linked to a directory, update its working copy instead", e.plugin)
+	}
+
+	return fmt.Sprintf("plugin %s was not added from a Git repository, remove and add it again to update it", e.plugin)
+}
+
+// UpdateResult is the outcome of updating a plugin
+type UpdateResult struct {
+	Name string
+	// Ref is the ref the plugin was updated to
+	Ref    string
+	OldSHA string
+	NewSHA string
+	// Output is what the hooks and callbacks printed when the plugin was
+	// updated by UpdateAll
+	Output string
+	Err    error
+}
+
+// metadata is what asdf records about a plugin in the data directory
+type metadata struct {
+	// PreviousRef is the commit the plugin was at before it was last updated
+	PreviousRef string `json:"previous_ref"`
+	// PreviousBranch is the branch the plugin was on before it was last
+	// updated, it is empty when HEAD was detached
+	PreviousBranch string `json:"previous_branch,omitempty"`
+}
+
+// Update a plugin to a specific ref, or if no ref provided update to latest.
+// When the plugin moves to a different commit the one it was at is recorded so
+// the update can be undone with Rollback. When the new commit fails
+// verification or the post-plugin-update callback fails the plugin is returned
+// to the commit it was at and nothing is recorded. The result holds the ref the
+// plugin was updated to and the commits it was at before and after.
+func (p Plugin) Update(conf config.Config, ref string, out, errout io.Writer) (UpdateResult, error) {
+	result := p.update(conf, ref, out, errout)
+	return result, result.Err
+}
+
+// UpdateAll updates all installed plugins to the latest commit on their
+// current branch, running at most jobs updates at once. The output of each
+// update is collected in its result rather than interleaved. Results are in
+// the order of List.
+func UpdateAll(conf config.Config, jobs int) ([]UpdateResult, error) {
+	installed, err := List(conf, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]UpdateResult, len(installed))
+	parallel(len(installed), jobs, func(index int) {
+		var output bytes.Buffer
+		results[index] = installed[index].update(conf, "", &output, &output)
+		results[index].Output = output.String()
+	})
+
+	return results, nil
+}
+
+// Rollback returns the plugin to the commit it was at before it was last
+// updated or rolled back, so rolling back twice undoes the first rollback.
+// It returns the commit the plugin is now at.
+func (p Plugin) Rollback(conf config.Config, out, errout io.Writer) (string, error) {
+	repo, err := p.updatableRepo()
+	if err != nil {
+		return "", err
+	}
+
+	previous, err := readMetadata(conf, p.Name)
+	if errors.Is(err, os.ErrNotExist) || previous.PreviousRef == "" {
+		return "", fmt.Errorf("plugin %s has no previous ref to roll back to, it has not been updated", p.Name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	current, err := currentRef(repo)
+	if err != nil {
+		return "", err
+	}
+
+	err = repo.Checkout(previous.PreviousRef, previous.PreviousBranch)
+	if err != nil {
+		return "", fmt.Errorf("unable to roll back plugin %s: %w", p.Name, err)
+	}
+
+	err = p.verifyIfRequired(conf)
+	if err != nil {
+		rollbackErr := repo.Checkout(current.PreviousRef, current.PreviousBranch)
+		if rollbackErr != nil {
+			return "", fmt.Errorf("%w, unable to return plugin %s to %s: %w", err, p.Name, current.PreviousRef, rollbackErr)
+		}
+
+		return "", fmt.Errorf("%w, left plugin %s at %s", err, p.Name, current.PreviousRef)
+	}
+
+	err = writeMetadata(conf, p.Name, current)
+	if err != nil {
+		return "", err
+	}
+
+	return previous.PreviousRef, p.runPostUpdate(conf, current.PreviousRef, previous.PreviousRef, out, errout)
+}
+
+// Log returns the commits of the plugin's Git repository between from and to,
+// newest first. from defaults to the commit the plugin was at before its last
+// update and to defaults to the commit it is at now.
+func (p Plugin) Log(conf config.Config, from, to string) ([]git.Commit, error) {
+	err := p.Exists()
+	if err != nil {
+		return nil, err
+	}
+
+	repo := git.NewRepo(p.Dir)
+	if !repo.IsRepository() {
+		return nil, fmt.Errorf("plugin %s is not a Git repository, it has no log", p.Name)
+	}
+
+	if from == "" {
+		previous, err := readMetadata(conf, p.Name)
+		if errors.Is(err, os.ErrNotExist) || previous.PreviousRef == "" {
+			return nil, fmt.Errorf("plugin %s has not been updated, give the ref to show the log from", p.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		from = previous.PreviousRef
+	}
+
+	if to == "" {
+		to = "HEAD"
+	}
+
+	return repo.Log(from, to)
+}
+
+func (p Plugin) update(conf config.Config, ref string, out, errout io.Writer) (result UpdateResult) {
+	result.Name = p.Name
+
+	repo, err := p.updatableRepo()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	previous, err := currentRef(repo)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	hook.RunWithOutput(conf, "pre_asdf_plugin_update", []string{p.Name}, out, errout)
+	hook.RunWithOutput(conf, fmt.Sprintf("pre_asdf_plugin_update_%s", p.Name), []string{p.Name}, out, errout)
+
+	result.Ref, result.OldSHA, result.NewSHA, err = repo.Update(ref)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	err = p.verifyIfRequired(conf)
+	if err == nil {
+		err = p.runPostUpdate(conf, result.OldSHA, result.NewSHA, out, errout)
+	}
+	if err != nil {
+		// Don't leave the plugin at a commit that failed verification or its
+		// own callback failed for
+		rollbackErr := repo.Checkout(previous.PreviousRef, previous.PreviousBranch)
+		if rollbackErr != nil {
+			result.Err = fmt.Errorf("%w, unable to roll back plugin %s: %w", err, p.Name, rollbackErr)
+			return result
+		}
+
+		result.NewSHA = result.OldSHA
+		result.Err = fmt.Errorf("%w, rolled back plugin %s to %s", err, p.Name, result.OldSHA)
+		return result
+	}
+
+	// A no-op update keeps the ref recorded by the last update that changed
+	// the plugin, so Rollback still undoes that update
+	if result.OldSHA != result.NewSHA {
+		err = writeMetadata(conf, p.Name, previous)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	hook.RunWithOutput(conf, "post_asdf_plugin_update", []string{p.Name}, out, errout)
+	hook.RunWithOutput(conf, fmt.Sprintf("post_asdf_plugin_update_%s", p.Name), []string{}, out, errout)
+
+	return result
+}
+
+// updatableRepo returns the Git repository of the plugin, or an error when the
+// plugin can't be updated
+func (p Plugin) updatableRepo() (git.Repo, error) {
+	repo := git.NewRepo(p.Dir)
+
+	err := p.Exists()
+	if err != nil {
+		return repo, fmt.Errorf("no such plugin: %s", p.Name)
+	}
+
+	if p.IsLinked() {
+		return repo, NotUpdatableError{plugin: p.Name, linked: true}
+	}
+
+	if !repo.IsRepository() {
+		return repo, NotUpdatableError{plugin: p.Name}
+	}
+
+	return repo, nil
+}
+
+// runPostUpdate runs the post-plugin-update callback if the plugin has one
+func (p Plugin) runPostUpdate(conf config.Config, oldSHA, newSHA string, out, errout io.Writer) error {
+	env := map[string]string{
+		"ASDF_PLUGIN_PATH":     p.Dir,
+		"ASDF_PLUGIN_PREV_REF": oldSHA,
+		"ASDF_PLUGIN_POST_REF": newSHA,
+	}
+
+	err := p.RunCallback(conf, "post-plugin-update", []string{}, env, out, errout)
+	if _, ok := err.(NoCallbackError); ok {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("post-plugin-update callback of plugin %s failed: %w", p.Name, err)
+	}
+
+	return nil
+}
+
+// currentRef returns the commit and branch the plugin is at, to be recorded as
+// its previous ref once it has moved
+func currentRef(repo git.Repo) (current metadata, err error) {
+	current.PreviousRef, err = repo.Head()
+	if err != nil {
+		return current, err
+	}
+
+	current.PreviousBranch, err = repo.Branch()
+	if err != nil {
+		return current, err
+	}
+
+	return current, nil
+}
+
+func readMetadata(conf config.Config, pluginName string) (pluginMetadata metadata, err error) {
+	contents, err := os.ReadFile(data.PluginMetadataFile(conf.DataDir, pluginName))
+	if err != nil {
+		return pluginMetadata, err
+	}
+
+	err = json.Unmarshal(contents, &pluginMetadata)
+	return pluginMetadata, err
+}
+
+func writeMetadata(conf config.Config, pluginName string, pluginMetadata metadata) error {
+	path := data.PluginMetadataFile(conf.DataDir, pluginName)
+	err := os.MkdirAll(filepath.Dir(path), 0o777)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(pluginMetadata)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, contents, 0o666)
+}
@@ -0,0 +1,217 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateRollbackOnCallbackFailure(t *testing.T) {
+	conf, repoPath := generateUpdatablePlugin(t)
+	plugin := New(conf, testPluginName)
+	before := pluginHead(t, plugin)
+	commitCallback(t, repoPath, "post-plugin-update", "#!/usr/bin/env bash\necho broken >&2\nexit 1\n")
+
+	var output strings.Builder
+	_, err := plugin.Update(conf, "", &output, &output)
+	assert.ErrorContains(t, err, "post-plugin-update callback of plugin lua failed")
+	assert.ErrorContains(t, err, "rolled back plugin lua to "+before)
+	assert.Contains(t, output.String(), "broken")
+	assert.Equal(t, before, pluginHead(t, plugin))
+
+	branch, err := git.NewRepo(plugin.Dir).Branch()
+	assert.Nil(t, err)
+	assert.Equal(t, "master", branch)
+	assert.NoFileExists(t, data.PluginMetadataFile(conf.DataDir, testPluginName))
+}
+
+func TestRollback(t *testing.T) {
+	t.Run("returns plugin to ref before last update", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		plugin := New(conf, testPluginName)
+		before := pluginHead(t, plugin)
+		commitCallback(t, repoPath, "exec-env", "#!/usr/bin/env bash\n")
+
+		var blackhole strings.Builder
+		_, err := plugin.Update(conf, "", &blackhole, &blackhole)
+		assert.Nil(t, err)
+		after := pluginHead(t, plugin)
+		assert.NotEqual(t, before, after)
+
+		ref, err := plugin.Rollback(conf, &blackhole, &blackhole)
+		assert.Nil(t, err)
+		assert.Equal(t, before, ref)
+		assert.Equal(t, before, pluginHead(t, plugin))
+
+		// Rolling back again undoes the rollback
+		ref, err = plugin.Rollback(conf, &blackhole, &blackhole)
+		assert.Nil(t, err)
+		assert.Equal(t, after, ref)
+		assert.Equal(t, after, pluginHead(t, plugin))
+	})
+
+	t.Run("returns plugin to ref before last update that changed it", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		plugin := New(conf, testPluginName)
+		before := pluginHead(t, plugin)
+		commitCallback(t, repoPath, "exec-env", "#!/usr/bin/env bash\n")
+
+		var blackhole strings.Builder
+		result, err := plugin.Update(conf, "", &blackhole, &blackhole)
+		assert.Nil(t, err)
+		assert.Equal(t, before, result.OldSHA)
+		assert.Equal(t, pluginHead(t, plugin), result.NewSHA)
+
+		result, err = plugin.Update(conf, "", &blackhole, &blackhole)
+		assert.Nil(t, err)
+		assert.Equal(t, result.OldSHA, result.NewSHA)
+
+		ref, err := plugin.Rollback(conf, &blackhole, &blackhole)
+		assert.Nil(t, err)
+		assert.Equal(t, before, ref)
+		assert.Equal(t, before, pluginHead(t, plugin))
+	})
+
+	t.Run("returns error when plugin has not been updated", func(t *testing.T) {
+		conf, _ := generateUpdatablePlugin(t)
+
+		var blackhole strings.Builder
+		_, err := New(conf, testPluginName).Rollback(conf, &blackhole, &blackhole)
+		assert.ErrorContains(t, err, "plugin lua has no previous ref to roll back to")
+	})
+
+	t.Run("remove deletes recorded ref", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		commitCallback(t, repoPath, "exec-env", "#!/usr/bin/env bash\n")
+
+		var blackhole strings.Builder
+		_, err := New(conf, testPluginName).Update(conf, "", &blackhole, &blackhole)
+		assert.Nil(t, err)
+		assert.FileExists(t, data.PluginMetadataFile(conf.DataDir, testPluginName))
+
+		assert.Nil(t, Remove(conf, testPluginName, &blackhole, &blackhole))
+		assert.NoFileExists(t, data.PluginMetadataFile(conf.DataDir, testPluginName))
+	})
+}
+
+func TestUpdateAll(t *testing.T) {
+	conf, repoPath := generateUpdatablePlugin(t)
+	before := pluginHead(t, New(conf, testPluginName))
+	commitCallback(t, repoPath, "exec-env", "#!/usr/bin/env bash\n")
+	assert.Nil(t, Add(conf, "other", repoPath, ""))
+	assert.Nil(t, Link(conf, "linked", generateLocalPlugin(t)))
+
+	results, err := UpdateAll(conf, 2)
+	assert.Nil(t, err)
+	assert.Len(t, results, 3)
+
+	assert.Equal(t, "linked", results[0].Name)
+	assert.IsType(t, NotUpdatableError{}, results[0].Err)
+
+	assert.Equal(t, testPluginName, results[1].Name)
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, before, results[1].OldSHA)
+	assert.NotEqual(t, before, results[1].NewSHA)
+	assert.Contains(t, results[1].Output, "plugin updated")
+
+	assert.Equal(t, "other", results[2].Name)
+	assert.Nil(t, results[2].Err)
+	assert.Equal(t, results[2].OldSHA, results[2].NewSHA)
+}
+
+func TestLog(t *testing.T) {
+	t.Run("returns commits since last update", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		plugin := New(conf, testPluginName)
+		commitCallback(t, repoPath, "exec-env", "#!/usr/bin/env bash\n")
+
+		var blackhole strings.Builder
+		_, err := plugin.Update(conf, "", &blackhole, &blackhole)
+		assert.Nil(t, err)
+
+		commits, err := plugin.Log(conf, "", "")
+		assert.Nil(t, err)
+		assert.Len(t, commits, 1)
+		assert.Equal(t, "add exec-env", commits[0].Subject)
+		assert.Equal(t, pluginHead(t, plugin), commits[0].SHA)
+	})
+
+	t.Run("returns commits between given refs", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		plugin := New(conf, testPluginName)
+		head := pluginHead(t, plugin)
+		commitCallback(t, repoPath, "exec-env", "#!/usr/bin/env bash\n")
+
+		var blackhole strings.Builder
+		_, err := plugin.Update(conf, "", &blackhole, &blackhole)
+		assert.Nil(t, err)
+
+		commits, err := plugin.Log(conf, head[:7], "master")
+		assert.Nil(t, err)
+		assert.Len(t, commits, 1)
+
+		commits, err = plugin.Log(conf, "master", head)
+		assert.Nil(t, err)
+		assert.Empty(t, commits)
+	})
+
+	t.Run("returns error when plugin has not been updated and no ref is given", func(t *testing.T) {
+		conf, _ := generateUpdatablePlugin(t)
+
+		_, err := New(conf, testPluginName).Log(conf, "", "")
+		assert.ErrorContains(t, err, "plugin lua has not been updated")
+	})
+
+	t.Run("returns error when plugin is not a Git repository", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		assert.Nil(t, Add(conf, testPluginName, generateLocalPlugin(t), ""))
+
+		_, err := New(conf, testPluginName).Log(conf, "", "")
+		assert.ErrorContains(t, err, "plugin lua is not a Git repository")
+	})
+}
+
+// generateUpdatablePlugin adds the dummy plugin from a Git repository and
+// returns the path of the repository
+func generateUpdatablePlugin(t *testing.T) (config.Config, string) {
+	t.Helper()
+	conf := config.Config{DataDir: t.TempDir()}
+	repoPath, err := repotest.GeneratePlugin("dummy_plugin", t.TempDir(), testPluginName)
+	assert.Nil(t, err)
+	assert.Nil(t, Add(conf, testPluginName, repoPath, ""))
+
+	return conf, repoPath
+}
+
+// commitCallback commits a callback to the plugin repository at repoPath
+func commitCallback(t *testing.T, repoPath, callbackName, script string) {
+	t.Helper()
+	assert.Nil(t, os.WriteFile(filepath.Join(repoPath, "bin", callbackName), []byte(script), 0o777))
+
+	repo, err := gogit.PlainOpen(repoPath)
+	assert.Nil(t, err)
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+	_, err = worktree.Add(filepath.Join("bin", callbackName))
+	assert.Nil(t, err)
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	_, err = worktree.Commit("add "+callbackName, &gogit.CommitOptions{Author: signature})
+	assert.Nil(t, err)
+}
+
+func pluginHead(t *testing.T, plugin Plugin) string {
+	t.Helper()
+	head, err := git.NewRepo(plugin.Dir).Head()
+	assert.Nil(t, err)
+	return head
+}
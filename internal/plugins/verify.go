@@ -0,0 +1,170 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/pluginversions"
+	"github.com/asdf-vm/asdf/internal/trust"
+)
+
+// Verification is what the commit of a plugin was verified against
+type Verification struct {
+	Name string
+	SHA  string
+	// Signer is who signed the commit, when plugins must be signed
+	Signer string
+	// Lockfile is the .plugin-versions file pinning the commit, when plugins
+	// must be pinned
+	Lockfile string
+}
+
+// Verify checks the commit the plugin is at against the plugin_verification
+// setting. Pinned plugins must be at the commit SHA the .plugin-versions file
+// closest to the current directory pins them to, signed plugins must be at a
+// commit signed with a key from plugin_gpg_keyring or plugin_allowed_signers.
+func (p Plugin) Verify(conf config.Config) (Verification, error) {
+	mode, err := conf.PluginVerification()
+	if err != nil {
+		return Verification{Name: p.Name}, err
+	}
+	if mode == "" {
+		return Verification{Name: p.Name}, fmt.Errorf("plugin_verification is not set, set it to pinned or signed in your asdfrc to verify plugins")
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return Verification{Name: p.Name}, err
+	}
+
+	return p.verify(conf, mode, dir)
+}
+
+// verifyIfRequired verifies the plugin when the asdfrc requires plugins to be
+// verified
+func (p Plugin) verifyIfRequired(conf config.Config) error {
+	mode, err := conf.PluginVerification()
+	if err != nil || mode == "" {
+		return err
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	_, err = p.verify(conf, mode, dir)
+	return err
+}
+
+// verify checks the plugin is pinned, by the .plugin-versions file closest to
+// dir, or signed depending on mode
+func (p Plugin) verify(conf config.Config, mode, dir string) (verification Verification, err error) {
+	verification.Name = p.Name
+
+	err = p.Exists()
+	if err != nil {
+		return verification, err
+	}
+
+	repo := git.NewRepo(p.Dir)
+	if !repo.IsRepository() {
+		return verification, fmt.Errorf("plugin %s is not a Git repository, it can't be verified", p.Name)
+	}
+
+	verification.SHA, err = repo.Head()
+	if err != nil {
+		return verification, err
+	}
+
+	if mode == "pinned" {
+		verification.Lockfile, err = p.verifyPinned(conf, verification.SHA, dir)
+		return verification, err
+	}
+
+	signers, err := loadSigners(conf)
+	if err != nil {
+		return verification, err
+	}
+
+	verification.Signer, err = repo.VerifySignature(verification.SHA, signers)
+	if err != nil {
+		return verification, fmt.Errorf("plugin %s failed verification: %w", p.Name, err)
+	}
+
+	return verification, nil
+}
+
+// verifyPinned checks sha is the commit the plugin is pinned to and returns
+// the path of the .plugin-versions file that pins it
+func (p Plugin) verifyPinned(conf config.Config, sha, dir string) (string, error) {
+	path, found := pluginversions.Find(dir)
+	if !found {
+		return "", fmt.Errorf("plugin %s is not pinned, no %s file found in %s or its parent directories", p.Name, pluginversions.Filename, dir)
+	}
+
+	// An untrusted file could pin any commit, so trust is required whatever
+	// the asdfrc says
+	if err := trust.Require(conf, path); err != nil {
+		return path, err
+	}
+
+	entries, err := pluginversions.Read(path)
+	if err != nil {
+		return path, err
+	}
+
+	index := slices.IndexFunc(entries, func(entry pluginversions.Entry) bool { return entry.Name == p.Name })
+	if index == -1 {
+		return path, fmt.Errorf("plugin %s is not pinned in %s", p.Name, path)
+	}
+
+	pinned := entries[index].Ref
+	if !git.IsFullSHA(pinned) {
+		return path, fmt.Errorf("plugin %s is pinned to %q in %s, which is not a full commit SHA, run asdf plugin lock %s to pin it", p.Name, pinned, path, p.Name)
+	}
+
+	if pinned != sha {
+		return path, fmt.Errorf("plugin %s is at %s but %s pins %s", p.Name, sha, path, pinned)
+	}
+
+	return path, nil
+}
+
+// loadSigners reads the keys plugin commits may be signed with from the files
+// the asdfrc names
+func loadSigners(conf config.Config) (signers git.Signers, err error) {
+	gpgKeyring, allowedSigners, err := conf.PluginSigners()
+	if err != nil {
+		return signers, err
+	}
+
+	if gpgKeyring == "" && allowedSigners == "" {
+		return signers, fmt.Errorf("plugin_verification is signed but neither plugin_gpg_keyring nor plugin_allowed_signers is set")
+	}
+
+	if gpgKeyring != "" {
+		contents, err := os.ReadFile(gpgKeyring)
+		if err != nil {
+			return signers, fmt.Errorf("unable to read plugin_gpg_keyring: %w", err)
+		}
+		signers.GPGKeyRing = string(contents)
+	}
+
+	if allowedSigners != "" {
+		contents, err := os.ReadFile(allowedSigners)
+		if err != nil {
+			return signers, fmt.Errorf("unable to read plugin_allowed_signers: %w", err)
+		}
+
+		signers.SSH, err = git.ParseAllowedSigners(contents)
+		if err != nil {
+			return signers, fmt.Errorf("unable to parse %s: %w", allowedSigners, err)
+		}
+	}
+
+	return signers, nil
+}
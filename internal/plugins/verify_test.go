@@ -0,0 +1,141 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/trust"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	t.Run("returns error when plugin_verification is not set", func(t *testing.T) {
+		conf, _ := generateUpdatablePlugin(t)
+		conf.Settings = config.Settings{Loaded: true}
+
+		_, err := New(conf, testPluginName).Verify(conf)
+		assert.ErrorContains(t, err, "plugin_verification is not set")
+	})
+
+	t.Run("returns lockfile when plugin is at pinned commit", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		plugin := New(conf, testPluginName)
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{testPluginName, repoPath, pluginHead(t, plugin)}, " "))
+
+		verification, err := plugin.verify(conf, "pinned", filepath.Dir(path))
+		assert.Nil(t, err)
+		assert.Equal(t, path, verification.Lockfile)
+		assert.Equal(t, pluginHead(t, plugin), verification.SHA)
+	})
+
+	t.Run("returns error when plugin is at another commit than pinned one", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		plugin := New(conf, testPluginName)
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{testPluginName, repoPath, firstCommit(t, repoPath)}, " "))
+
+		_, err := plugin.verify(conf, "pinned", filepath.Dir(path))
+		assert.ErrorContains(t, err, "plugin lua is at "+pluginHead(t, plugin)+" but "+path+" pins")
+	})
+
+	t.Run("returns error when plugin is pinned to abbreviated SHA or branch", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{testPluginName, repoPath, "master"}, " "))
+
+		_, err := New(conf, testPluginName).verify(conf, "pinned", filepath.Dir(path))
+		assert.ErrorContains(t, err, `pinned to "master"`)
+		assert.ErrorContains(t, err, "not a full commit SHA")
+	})
+
+	t.Run("returns error when plugin is not pinned", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{"ruby", repoPath}, " "))
+
+		_, err := New(conf, testPluginName).verify(conf, "pinned", filepath.Dir(path))
+		assert.ErrorContains(t, err, "plugin lua is not pinned in "+path)
+
+		_, err = New(conf, testPluginName).verify(conf, "pinned", t.TempDir())
+		assert.ErrorContains(t, err, "plugin lua is not pinned, no .plugin-versions file found")
+	})
+
+	t.Run("returns error when lockfile is not trusted", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		conf.Settings = config.Settings{Loaded: true, RequireTrust: true}
+		plugin := New(conf, testPluginName)
+		path := writePluginVersions(t, strings.Join([]string{testPluginName, repoPath, pluginHead(t, plugin)}, " "))
+
+		_, err := plugin.verify(conf, "pinned", filepath.Dir(path))
+		assert.IsType(t, trust.UntrustedFileError{}, err)
+	})
+
+	t.Run("returns error when lockfile is not trusted and trust is not required", func(t *testing.T) {
+		conf, repoPath := generateUpdatablePlugin(t)
+		conf.Settings = config.Settings{Loaded: true, RequireTrust: false}
+		plugin := New(conf, testPluginName)
+		path := writePluginVersions(t, strings.Join([]string{testPluginName, repoPath, pluginHead(t, plugin)}, " "))
+
+		_, err := plugin.verify(conf, "pinned", filepath.Dir(path))
+		assert.IsType(t, trust.UntrustedFileError{}, err)
+	})
+
+	t.Run("returns error when no signers are set", func(t *testing.T) {
+		conf, _ := generateUpdatablePlugin(t)
+		conf.Settings = config.Settings{Loaded: true}
+
+		_, err := New(conf, testPluginName).verify(conf, "signed", t.TempDir())
+		assert.ErrorContains(t, err, "neither plugin_gpg_keyring nor plugin_allowed_signers is set")
+	})
+
+	t.Run("returns error when commit is not signed", func(t *testing.T) {
+		conf, _ := generateUpdatablePlugin(t)
+		conf.Settings = signedSettings(t)
+
+		_, err := New(conf, testPluginName).verify(conf, "signed", t.TempDir())
+		assert.ErrorContains(t, err, "plugin lua failed verification: commit")
+		assert.ErrorContains(t, err, "is not signed")
+	})
+
+	t.Run("returns error when plugin is not a Git repository", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir()}
+		assert.Nil(t, Add(conf, testPluginName, generateLocalPlugin(t), ""))
+
+		_, err := New(conf, testPluginName).verify(conf, "signed", t.TempDir())
+		assert.ErrorContains(t, err, "plugin lua is not a Git repository, it can't be verified")
+	})
+}
+
+func TestAddRefusesUnverifiedPlugin(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir(), Settings: signedSettings(t)}
+	_, repoPath := generateVersionsConfig(t)
+
+	err := Add(conf, testPluginName, repoPath, "")
+	assert.ErrorContains(t, err, "plugin lua failed verification")
+	assert.NoDirExists(t, New(conf, testPluginName).Dir)
+}
+
+func TestUpdateRefusesUnverifiedCommit(t *testing.T) {
+	conf, repoPath := generateUpdatablePlugin(t)
+	plugin := New(conf, testPluginName)
+	before := pluginHead(t, plugin)
+	commitCallback(t, repoPath, "exec-env", "#!/usr/bin/env bash\n")
+	conf.Settings = signedSettings(t)
+
+	var blackhole strings.Builder
+	_, err := plugin.Update(conf, "", &blackhole, &blackhole)
+	assert.ErrorContains(t, err, "plugin lua failed verification")
+	assert.ErrorContains(t, err, "rolled back plugin lua to "+before)
+	assert.Equal(t, before, pluginHead(t, plugin))
+}
+
+// signedSettings returns settings requiring plugins to be signed by a key that
+// signs nothing
+func signedSettings(t *testing.T) config.Settings {
+	t.Helper()
+	allowedSigners := filepath.Join(t.TempDir(), "allowed_signers")
+	key := "test@example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIFaMAzyYZmFzdVTZnD9f2SaQNmIDUGW0HrOlzG7ZCWI4\n"
+	assert.Nil(t, os.WriteFile(allowedSigners, []byte(key), 0o666))
+
+	return config.Settings{Loaded: true, PluginVerification: "signed", PluginAllowedSigners: allowedSigners}
+}
@@ -0,0 +1,155 @@
+package plugins
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/pluginversions"
+	"github.com/asdf-vm/asdf/internal/trust"
+)
+
+// LockVersions returns .plugin-versions entries pinning the named plugins, or
+// all installed plugins when no names are given, to their Git URL and current
+// commit. Plugins that are linked or were not added from a Git repository
+// can't be pinned and are returned in skipped.
+func LockVersions(conf config.Config, names []string) (entries []pluginversions.Entry, skipped []string, err error) {
+	installed, err := List(conf, false, false)
+	if err != nil {
+		return entries, skipped, err
+	}
+
+	for _, name := range names {
+		plugin := New(conf, name)
+		if err := plugin.Exists(); err != nil {
+			return entries, skipped, err
+		}
+	}
+
+	for _, plugin := range installed {
+		if len(names) > 0 && !slices.Contains(names, plugin.Name) {
+			continue
+		}
+
+		repo := git.NewRepo(plugin.Dir)
+		url, err := repo.RemoteURL()
+		if err != nil {
+			return entries, skipped, err
+		}
+
+		if plugin.IsLinked() || url == "" {
+			skipped = append(skipped, plugin.Name)
+			continue
+		}
+
+		ref, err := repo.Head()
+		if err != nil {
+			return entries, skipped, err
+		}
+
+		entries = append(entries, pluginversions.Entry{Name: plugin.Name, URL: url, Ref: ref})
+	}
+
+	return entries, skipped, nil
+}
+
+// SyncVersions adds the plugins pinned in the .plugin-versions file at path
+// that are not installed. Installed plugins at another ref than the pinned one
+// are updated to it when update is true, and reported as outdated otherwise.
+// When names are given only the entries of those plugins are synced.
+//
+// Adding or updating a plugin runs code from its repository, so when either
+// is needed SyncVersions requires the file to be trusted, even when the asdfrc
+// doesn't require trust for version files. Nothing is changed when it isn't.
+func SyncVersions(conf config.Config, path string, names []string, update bool, stdOut, stdErr io.Writer) (results []pluginversions.Result, err error) {
+	entries, err := pluginversions.Read(path)
+	if err != nil {
+		return results, err
+	}
+
+	var planned []pluginversions.Result
+	for _, entry := range entries {
+		if len(names) > 0 && !slices.Contains(names, entry.Name) {
+			continue
+		}
+
+		result, err := planEntry(conf, entry, update)
+		if err != nil {
+			return results, fmt.Errorf("unable to sync plugin %s from %s: %w", entry.Name, path, err)
+		}
+		planned = append(planned, result)
+	}
+
+	if slices.ContainsFunc(planned, changesPlugin) {
+		if err := trust.Require(conf, path); err != nil {
+			return results, err
+		}
+	}
+
+	for _, result := range planned {
+		if err := applyResult(conf, result, stdOut, stdErr); err != nil {
+			return results, fmt.Errorf("unable to sync plugin %s from %s: %w", result.Name, path, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// planEntry returns the result of syncing the plugin of entry without adding
+// or updating it
+func planEntry(conf config.Config, entry pluginversions.Entry, update bool) (pluginversions.Result, error) {
+	plugin := New(conf, entry.Name)
+	result := pluginversions.Result{Entry: entry, Action: pluginversions.Unchanged}
+
+	err := plugin.Exists()
+	if _, ok := err.(PluginMissing); ok {
+		result.Action = pluginversions.Added
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if entry.Ref == "" || plugin.IsLinked() {
+		return result, nil
+	}
+
+	repo := git.NewRepo(plugin.Dir)
+	result.InstalledRef, err = repo.Head()
+	if err != nil || result.InstalledRef == "" {
+		return result, err
+	}
+
+	// A ref that can't be resolved may be a branch or tag that was created
+	// after the plugin was added, which an update would fetch
+	pinned, err := repo.ResolveRef(entry.Ref)
+	if err == nil && pinned == result.InstalledRef {
+		return result, nil
+	}
+
+	if !update {
+		result.Action = pluginversions.Outdated
+		return result, nil
+	}
+
+	result.Action = pluginversions.Updated
+	return result, nil
+}
+
+func changesPlugin(result pluginversions.Result) bool {
+	return result.Action == pluginversions.Added || result.Action == pluginversions.Updated
+}
+
+func applyResult(conf config.Config, result pluginversions.Result, stdOut, stdErr io.Writer) error {
+	switch result.Action {
+	case pluginversions.Added:
+		return Add(conf, result.Name, result.URL, result.Ref)
+	case pluginversions.Updated:
+		_, err := New(conf, result.Name).Update(conf, result.Ref, stdOut, stdErr)
+		return err
+	}
+	return nil
+}
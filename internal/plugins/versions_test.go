@@ -0,0 +1,173 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/pluginversions"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/asdf-vm/asdf/internal/trust"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockVersions(t *testing.T) {
+	t.Run("returns Git URL and commit of installed plugins", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		assert.Nil(t, Add(conf, testPluginName, repoPath, ""))
+		head, err := git.NewRepo(New(conf, testPluginName).Dir).Head()
+		assert.Nil(t, err)
+
+		entries, skipped, err := LockVersions(conf, nil)
+		assert.Nil(t, err)
+		assert.Empty(t, skipped)
+		assert.Equal(t, []pluginversions.Entry{{Name: testPluginName, URL: repoPath, Ref: head}}, entries)
+	})
+
+	t.Run("skips plugins that are linked", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		assert.Nil(t, Link(conf, testPluginName, repoPath))
+
+		entries, skipped, err := LockVersions(conf, nil)
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+		assert.Equal(t, []string{testPluginName}, skipped)
+	})
+
+	t.Run("returns error when named plugin is not installed", func(t *testing.T) {
+		conf, _ := generateVersionsConfig(t)
+
+		_, _, err := LockVersions(conf, []string{"non-existent"})
+		assert.IsType(t, PluginMissing{}, err)
+	})
+}
+
+func TestSyncVersions(t *testing.T) {
+	t.Run("adds missing plugin at pinned ref", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		firstCommit := firstCommit(t, repoPath)
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{testPluginName, repoPath, firstCommit}, " "))
+
+		results, err := SyncVersions(conf, path, nil, false, os.Stdout, os.Stderr)
+		assert.Nil(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, pluginversions.Added, results[0].Action)
+		assert.Equal(t, firstCommit, pluginHead(t, New(conf, testPluginName)))
+	})
+
+	t.Run("reports installed plugin at another ref as outdated", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		assert.Nil(t, Add(conf, testPluginName, repoPath, ""))
+		firstCommit := firstCommit(t, repoPath)
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{testPluginName, repoPath, firstCommit[:7]}, " "))
+
+		results, err := SyncVersions(conf, path, nil, false, os.Stdout, os.Stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, pluginversions.Outdated, results[0].Action)
+		assert.NotEqual(t, firstCommit, results[0].InstalledRef)
+	})
+
+	t.Run("updates installed plugin at another ref when update is true", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		assert.Nil(t, Add(conf, testPluginName, repoPath, ""))
+		firstCommit := firstCommit(t, repoPath)
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{testPluginName, repoPath, firstCommit}, " "))
+
+		results, err := SyncVersions(conf, path, nil, true, os.Stdout, os.Stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, pluginversions.Updated, results[0].Action)
+		assert.Equal(t, firstCommit, pluginHead(t, New(conf, testPluginName)))
+	})
+
+	t.Run("leaves installed plugin at pinned ref unchanged", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		assert.Nil(t, Add(conf, testPluginName, repoPath, "master"))
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{testPluginName, repoPath, "master"}, " "))
+
+		results, err := SyncVersions(conf, path, nil, false, os.Stdout, os.Stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, pluginversions.Unchanged, results[0].Action)
+	})
+
+	t.Run("only syncs named plugins", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		path := writeTrustedPluginVersions(t, conf, strings.Join([]string{testPluginName, repoPath}, " "))
+
+		results, err := SyncVersions(conf, path, []string{"ruby"}, false, os.Stdout, os.Stderr)
+		assert.Nil(t, err)
+		assert.Empty(t, results)
+		assert.NoDirExists(t, New(conf, testPluginName).Dir)
+	})
+
+	t.Run("returns error when file is not trusted", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		conf.Settings = config.Settings{Loaded: true, RequireTrust: true}
+		path := writePluginVersions(t, strings.Join([]string{testPluginName, repoPath}, " "))
+
+		_, err := SyncVersions(conf, path, nil, false, os.Stdout, os.Stderr)
+		assert.IsType(t, trust.UntrustedFileError{}, err)
+		assert.NoDirExists(t, New(conf, testPluginName).Dir)
+	})
+
+	t.Run("doesn't require trust when no plugin needs to be added or updated", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		assert.Nil(t, Add(conf, testPluginName, repoPath, ""))
+		firstCommit := firstCommit(t, repoPath)
+		path := writePluginVersions(t, strings.Join([]string{testPluginName, repoPath, firstCommit}, " ")+"\nruby https://example.com/asdf-ruby.git")
+
+		results, err := SyncVersions(conf, path, []string{testPluginName}, false, os.Stdout, os.Stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, pluginversions.Outdated, results[0].Action)
+	})
+
+	t.Run("returns error when file is not trusted and trust is not required", func(t *testing.T) {
+		conf, repoPath := generateVersionsConfig(t)
+		conf.Settings = config.Settings{Loaded: true, RequireTrust: false}
+		path := writePluginVersions(t, strings.Join([]string{testPluginName, repoPath}, " "))
+
+		_, err := SyncVersions(conf, path, nil, false, os.Stdout, os.Stderr)
+		assert.IsType(t, trust.UntrustedFileError{}, err)
+		assert.NoDirExists(t, New(conf, testPluginName).Dir)
+	})
+}
+
+func generateVersionsConfig(t *testing.T) (config.Config, string) {
+	t.Helper()
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+
+	repoPath, err := repotest.GeneratePlugin("dummy_plugin", t.TempDir(), testPluginName)
+	assert.Nil(t, err)
+
+	return conf, repoPath
+}
+
+func writePluginVersions(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), pluginversions.Filename)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o666))
+	return path
+}
+
+// writeTrustedPluginVersions writes a .plugin-versions file and trusts it
+func writeTrustedPluginVersions(t *testing.T, conf config.Config, content string) string {
+	t.Helper()
+	path := writePluginVersions(t, content)
+	assert.Nil(t, trust.Add(conf, path))
+	return path
+}
+
+// firstCommit returns the SHA of the commit before HEAD in the repository
+func firstCommit(t *testing.T, repoPath string) string {
+	t.Helper()
+	repo, err := gogit.PlainOpen(repoPath)
+	assert.Nil(t, err)
+	hash, err := repo.ResolveRevision(plumbing.Revision("HEAD~"))
+	assert.Nil(t, err)
+	return hash.String()
+}
@@ -0,0 +1,84 @@
+package plugintest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML, the format CI services display
+// test results in. Each check is a test case of a test suite named after the
+// plugin.
+func (r Report) WriteJUnit(w io.Writer) error {
+	className := fmt.Sprintf("asdf.plugin.test.%s", r.Plugin)
+	suite := junitTestSuite{
+		Name:     r.Plugin,
+		Tests:    len(r.Results),
+		Failures: r.Count(Failed),
+		Skipped:  r.Count(Skipped),
+		Time:     seconds(r.Duration()),
+	}
+
+	for _, result := range r.Results {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: className,
+			Time:      seconds(result.Duration),
+			SystemOut: result.Output,
+		}
+
+		switch result.Status {
+		case Failed:
+			testCase.Failure = &junitMessage{Message: result.Message}
+		case Skipped:
+			testCase.Skipped = &junitMessage{Message: result.Message}
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
@@ -0,0 +1,444 @@
+// Package plugintest checks that a plugin works, from adding it to
+// uninstalling the versions of its tool it installed, for the asdf plugin test
+// command. Every check is run and reported, a failing check only skips the
+// checks that depend on it.
+package plugintest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/execenv"
+	"github.com/asdf-vm/asdf/internal/execute"
+	"github.com/asdf-vm/asdf/internal/help"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/shims"
+	"github.com/asdf-vm/asdf/internal/toolenv"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/versions"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	// Passed checks found nothing wrong
+	Passed Status = "passed"
+	// Failed checks found a problem with the plugin
+	Failed Status = "failed"
+	// Skipped checks were not run, because there was nothing to check or a
+	// check they depend on failed
+	Skipped Status = "skipped"
+)
+
+var helpCallbacks = []string{"help.overview", "help.deps", "help.config", "help.links"}
+
+// Options describe the plugin to test and how to test it
+type Options struct {
+	Name string
+	URL  string
+	// Ref is the branch, tag or commit of the plugin to test, the default
+	// branch when empty
+	Ref string
+	// Versions are the versions of the tool to install, the first version
+	// list-all returns when empty
+	Versions []string
+	// Command is run with each installed version on PATH, to check the tool
+	// works. Nothing is run when empty.
+	Command []string
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name   string
+	Status Status
+	// Message explains why the check failed or was skipped
+	Message string
+	// Output is what the callbacks or command run by the check printed
+	Output   string
+	Duration time.Duration
+}
+
+// Report is the outcome of every check run on a plugin, in the order they
+// were run
+type Report struct {
+	Plugin  string
+	Results []Result
+}
+
+// Count returns the number of checks with status
+func (r Report) Count(status Status) (count int) {
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Duration returns how long all the checks took
+func (r Report) Duration() (duration time.Duration) {
+	for _, result := range r.Results {
+		duration += result.Duration
+	}
+	return duration
+}
+
+// skipError is returned by a check that had nothing to check
+type skipError struct {
+	reason string
+}
+
+func (e skipError) Error() string {
+	return e.reason
+}
+
+type tester struct {
+	conf    config.Config
+	plugin  plugins.Plugin
+	options Options
+	report  Report
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// Run adds the plugin under a temporary name, runs every check on it and
+// removes it again. The output of the install callbacks and of the command is
+// written to stdout and stderr as well as recorded in the report.
+func Run(conf config.Config, options Options, stdout, stderr io.Writer) Report {
+	testName := fmt.Sprintf("asdf-test-%s", options.Name)
+	t := tester{
+		conf:    conf,
+		plugin:  plugins.New(conf, testName),
+		options: options,
+		report:  Report{Plugin: options.Name},
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+
+	added := t.check("add", func(_ io.Writer) error {
+		err := plugins.Add(conf, testName, options.URL, options.Ref)
+		if err != nil {
+			return fmt.Errorf("%s was not properly installed: %w", options.Name, err)
+		}
+		return nil
+	})
+	if !added {
+		return t.report
+	}
+
+	defer t.removePlugin()
+
+	t.check("callbacks", t.checkCallbacks)
+	t.check("executable callbacks", t.checkExecutable)
+	t.check("manifest", t.checkManifest)
+	t.check("license", t.checkLicense)
+
+	var allVersions []string
+	t.check("list-all", func(output io.Writer) (err error) {
+		allVersions, err = t.listAll(output)
+		return err
+	})
+	t.check("latest-stable", t.checkLatestStable)
+	t.check("help", t.checkHelp)
+
+	toolVersions := options.Versions
+	if len(toolVersions) == 0 && len(allVersions) > 0 {
+		toolVersions = allVersions[:1]
+	}
+
+	if len(toolVersions) == 0 {
+		t.skip("install", "list-all did not return a version to install")
+	}
+
+	for _, toolVersion := range toolVersions {
+		t.checkVersion(toolversions.Parse(toolVersion))
+	}
+
+	return t.report
+}
+
+// check runs fn and records its result, it returns true when the check passed
+func (t *tester) check(name string, fn func(output io.Writer) error) bool {
+	var output strings.Builder
+	start := time.Now()
+	err := fn(&output)
+	result := Result{Name: name, Status: Passed, Output: output.String(), Duration: time.Since(start)}
+
+	if err != nil {
+		result.Status = Failed
+		if _, ok := err.(skipError); ok {
+			result.Status = Skipped
+		}
+		result.Message = err.Error()
+	}
+
+	t.report.Results = append(t.report.Results, result)
+	return result.Status == Passed
+}
+
+func (t *tester) skip(name, reason string) {
+	t.report.Results = append(t.report.Results, Result{Name: name, Status: Skipped, Message: reason})
+}
+
+func (t *tester) checkCallbacks(_ io.Writer) error {
+	if _, err := os.Stat(filepath.Join(t.plugin.Dir, "bin")); errors.Is(err, fs.ErrNotExist) {
+		return errors.New("bin/ directory does not exist")
+	}
+
+	var missing []string
+	for _, callback := range plugins.RequiredCallbacks {
+		if _, err := t.plugin.CallbackPath(callback); err != nil {
+			missing = append(missing, callback)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing callbacks %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (t *tester) checkExecutable(_ io.Writer) error {
+	files, err := os.ReadDir(filepath.Join(t.plugin.Dir, "bin"))
+	if err != nil {
+		return skipError{reason: "bin/ directory does not exist"}
+	}
+
+	var notExecutable []string
+	for _, file := range files {
+		if !slices.Contains(plugins.Callbacks, file.Name()) {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&0o111 == 0 {
+			notExecutable = append(notExecutable, file.Name())
+		}
+	}
+
+	if len(notExecutable) > 0 {
+		return fmt.Errorf("callbacks lack executable permission: %s", strings.Join(notExecutable, ", "))
+	}
+	return nil
+}
+
+func (t *tester) checkManifest(_ io.Writer) error {
+	manifest, found, err := t.plugin.Manifest()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return skipError{reason: fmt.Sprintf("no %s", plugins.ManifestFilename)}
+	}
+
+	if manifest.Name != "" && manifest.Name != t.options.Name {
+		return fmt.Errorf("%s declares name %s instead of %s", plugins.ManifestFilename, manifest.Name, t.options.Name)
+	}
+
+	for command := range manifest.Commands {
+		if _, err := t.plugin.ExtensionCommandPath(command); err != nil {
+			return fmt.Errorf("%s describes missing extension command %q", plugins.ManifestFilename, command)
+		}
+	}
+	return nil
+}
+
+func (t *tester) checkLicense(_ io.Writer) error {
+	bytes, err := os.ReadFile(filepath.Join(t.plugin.Dir, "LICENSE"))
+	if err != nil {
+		return errors.New("LICENSE file must be present in the plugin repository")
+	}
+
+	if len(bytes) == 0 {
+		return errors.New("LICENSE file in the plugin repository must not be empty")
+	}
+	return nil
+}
+
+func (t *tester) listAll(output io.Writer) ([]string, error) {
+	var stdout strings.Builder
+	err := t.plugin.RunCallback(t.conf, "list-all", []string{}, map[string]string{}, io.MultiWriter(output, &stdout), output)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list available versions: %w", err)
+	}
+
+	allVersions := strings.Fields(stdout.String())
+	if len(allVersions) < 1 {
+		return nil, errors.New("list-all did not return any version")
+	}
+	return allVersions, nil
+}
+
+func (t *tester) checkLatestStable(output io.Writer) error {
+	var stdout strings.Builder
+	err := t.plugin.RunCallback(t.conf, "latest-stable", []string{""}, map[string]string{}, io.MultiWriter(output, &stdout), output)
+	if _, ok := err.(plugins.NoCallbackError); ok {
+		return skipError{reason: "no latest-stable callback"}
+	}
+	if err != nil {
+		return fmt.Errorf("latest-stable exited with an error: %w", err)
+	}
+
+	if strings.TrimSpace(stdout.String()) == "" {
+		return errors.New("latest-stable did not return a version")
+	}
+	return nil
+}
+
+func (t *tester) checkHelp(output io.Writer) error {
+	env := help.CallbackEnv(t.plugin, "")
+	found := false
+
+	for _, callback := range helpCallbacks {
+		var stdout strings.Builder
+		err := t.plugin.RunCallback(t.conf, callback, []string{}, env, io.MultiWriter(output, &stdout), output)
+		if _, ok := err.(plugins.NoCallbackError); ok {
+			continue
+		}
+		found = true
+
+		if err != nil {
+			return fmt.Errorf("%s exited with an error: %w", callback, err)
+		}
+		if strings.TrimSpace(stdout.String()) == "" {
+			return fmt.Errorf("%s printed nothing", callback)
+		}
+	}
+
+	if !found {
+		return skipError{reason: "no help callbacks"}
+	}
+	return nil
+}
+
+// checkVersion installs version, checks it has shims and runs the command
+// with it, then uninstalls it
+func (t *tester) checkVersion(version toolversions.Version) {
+	suffix := " " + version.Value
+	installed := t.check("install"+suffix, func(output io.Writer) error {
+		err := versions.InstallOneVersion(t.conf, t.plugin, version.Value, false, io.MultiWriter(output, t.stdout), io.MultiWriter(output, t.stderr))
+		if err != nil {
+			return fmt.Errorf("install exited with an error: %w", err)
+		}
+		return nil
+	})
+
+	if !installed {
+		for _, name := range []string{"shims", "command", "uninstall"} {
+			t.skip(name+suffix, "install failed")
+		}
+		return
+	}
+
+	var shimNames []string
+	t.check("shims"+suffix, func(_ io.Writer) (err error) {
+		shimNames, err = t.checkShims(version)
+		return err
+	})
+
+	t.check("command"+suffix, func(output io.Writer) error {
+		return t.runCommand(version, output)
+	})
+
+	t.check("uninstall"+suffix, func(output io.Writer) error {
+		return t.checkUninstall(version, shimNames, output)
+	})
+}
+
+func (t *tester) checkShims(version toolversions.Version) ([]string, error) {
+	executables, err := shims.ToolExecutables(t.conf, t.plugin, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(executables) == 0 {
+		paths, _ := shims.ExecutablePaths(t.conf, t.plugin, version)
+		return nil, fmt.Errorf("no executables installed in %s", strings.Join(paths, ", "))
+	}
+
+	var names, missing []string
+	for _, executable := range executables {
+		name := filepath.Base(executable)
+		names = append(names, name)
+		if _, err := os.Stat(shims.Path(t.conf, name)); err != nil {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return names, fmt.Errorf("no shims for %s", strings.Join(missing, ", "))
+	}
+	return names, nil
+}
+
+func (t *tester) runCommand(version toolversions.Version, output io.Writer) error {
+	if len(t.options.Command) == 0 {
+		return skipError{reason: "no command given"}
+	}
+
+	env, err := toolenv.Build(t.conf, []toolenv.ToolVersion{{Plugin: t.plugin, Version: version}}, execenv.CurrentEnv(), output)
+	if err != nil {
+		return err
+	}
+
+	// A single argument may be a whole command line, as with the Bash version
+	// of asdf, otherwise the arguments are the command and its arguments
+	cmd := execute.NewExpression(`"$@"`, t.options.Command)
+	if len(t.options.Command) == 1 {
+		cmd = execute.NewExpression(t.options.Command[0], []string{})
+	}
+	cmd.Env = env
+	cmd.Stdout = io.MultiWriter(output, t.stdout)
+	cmd.Stderr = io.MultiWriter(output, t.stderr)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s exited with an error: %w", strings.Join(t.options.Command, " "), err)
+	}
+	return nil
+}
+
+func (t *tester) checkUninstall(version toolversions.Version, shimNames []string, output io.Writer) error {
+	err := versions.Uninstall(t.conf, t.plugin, version.Value, output, output)
+	if err != nil {
+		return fmt.Errorf("uninstall exited with an error: %w", err)
+	}
+
+	if _, err := os.Stat(installs.InstallPath(t.conf, t.plugin, version)); !errors.Is(err, fs.ErrNotExist) {
+		return errors.New("install directory was not removed")
+	}
+
+	return shims.RemoveVersion(t.conf, t.plugin, version, shimNames)
+}
+
+// removePlugin removes the shims of the versions that were not uninstalled,
+// then the plugin itself. Only shims of the plugin are touched, the shims of
+// other tools are left as they are.
+func (t *tester) removePlugin() {
+	installed, _ := installs.Installed(t.conf, t.plugin)
+	for _, installedVersion := range installed {
+		version := toolversions.Parse(installedVersion)
+		executables, _ := shims.ToolExecutables(t.conf, t.plugin, version)
+
+		var shimNames []string
+		for _, executable := range executables {
+			shimNames = append(shimNames, filepath.Base(executable))
+		}
+		shims.RemoveVersion(t.conf, t.plugin, version, shimNames)
+	}
+
+	var blackhole strings.Builder
+	plugins.Remove(t.conf, t.plugin.Name, &blackhole, &blackhole)
+}
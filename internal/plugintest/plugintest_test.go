@@ -0,0 +1,174 @@
+package plugintest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginName = "lua"
+
+func TestRun(t *testing.T) {
+	t.Run("passes every check of working plugin", func(t *testing.T) {
+		conf, url := generatePlugin(t)
+		var stdout strings.Builder
+
+		report := Run(conf, Options{Name: testPluginName, URL: url, Command: []string{"dummy", "--version"}}, &stdout, &stdout)
+		assert.Equal(t, 0, report.Count(Failed), results(report))
+		assert.Equal(t, []string{
+			"add", "callbacks", "executable callbacks", "manifest", "license",
+			"list-all", "latest-stable", "help", "install 1.0.0", "shims 1.0.0",
+			"command 1.0.0", "uninstall 1.0.0",
+		}, names(report))
+		assert.Contains(t, stdout.String(), "This is Dummy 1.0.0!")
+	})
+
+	t.Run("removes plugin and its shims afterwards", func(t *testing.T) {
+		conf, url := generatePlugin(t)
+
+		Run(conf, Options{Name: testPluginName, URL: url}, &strings.Builder{}, &strings.Builder{})
+		assert.NoDirExists(t, filepath.Join(conf.DataDir, "plugins", "asdf-test-"+testPluginName))
+		assert.NoFileExists(t, filepath.Join(conf.DataDir, "shims", "dummy"))
+	})
+
+	t.Run("leaves shims of other tools alone", func(t *testing.T) {
+		conf, url := generatePlugin(t)
+		shim := "#!/usr/bin/env bash\n# asdf-plugin: ruby 3.3.0\nexec asdf exec \"ruby\" \"$@\""
+		assert.Nil(t, os.MkdirAll(filepath.Join(conf.DataDir, "shims"), 0o777))
+		assert.Nil(t, os.WriteFile(filepath.Join(conf.DataDir, "shims", "ruby"), []byte(shim), 0o777))
+
+		report := Run(conf, Options{Name: testPluginName, URL: url}, &strings.Builder{}, &strings.Builder{})
+		assert.Equal(t, 0, report.Count(Failed), results(report))
+
+		content, err := os.ReadFile(filepath.Join(conf.DataDir, "shims", "ruby"))
+		assert.Nil(t, err)
+		assert.Equal(t, shim, string(content))
+	})
+
+	t.Run("installs every version given", func(t *testing.T) {
+		conf, url := generatePlugin(t)
+
+		report := Run(conf, Options{Name: testPluginName, URL: url, Versions: []string{"1.0.0", "2.0.0"}}, &strings.Builder{}, &strings.Builder{})
+		assert.Equal(t, 0, report.Count(Failed), results(report))
+		assert.Contains(t, names(report), "install 1.0.0")
+		assert.Contains(t, names(report), "uninstall 2.0.0")
+	})
+
+	t.Run("reports every failed check instead of stopping at first", func(t *testing.T) {
+		conf, url := generatePlugin(t)
+		assert.Nil(t, os.Remove(filepath.Join(url, "LICENSE")))
+		assert.Nil(t, os.Chmod(filepath.Join(url, "bin", "latest-stable"), 0o644))
+		commitAll(t, url)
+
+		report := Run(conf, Options{Name: testPluginName, URL: url, Command: []string{"false"}}, &strings.Builder{}, &strings.Builder{})
+		assert.Equal(t, Result{Name: "license", Status: Failed, Message: "LICENSE file must be present in the plugin repository"}, withoutDuration(find(report, "license")))
+		assert.Equal(t, "callbacks lack executable permission: latest-stable", find(report, "executable callbacks").Message)
+		assert.Equal(t, Failed, find(report, "command 1.0.0").Status)
+		assert.Equal(t, Passed, find(report, "uninstall 1.0.0").Status)
+		assert.Equal(t, 4, report.Count(Failed), results(report))
+	})
+
+	t.Run("skips checks of version that failed to install", func(t *testing.T) {
+		conf, url := generatePlugin(t)
+
+		report := Run(conf, Options{Name: testPluginName, URL: url, Versions: []string{"other-dummy"}}, &strings.Builder{}, &strings.Builder{})
+		install := find(report, "install other-dummy")
+		assert.Equal(t, Failed, install.Status)
+		assert.Contains(t, install.Output, "Dummy couldn't install version: other-dummy")
+		assert.Equal(t, Result{Name: "shims other-dummy", Status: Skipped, Message: "install failed"}, find(report, "shims other-dummy"))
+	})
+
+	t.Run("stops when plugin can't be added", func(t *testing.T) {
+		conf, _ := generatePlugin(t)
+
+		report := Run(conf, Options{Name: testPluginName, URL: filepath.Join(t.TempDir(), "missing")}, &strings.Builder{}, &strings.Builder{})
+		assert.Equal(t, []string{"add"}, names(report))
+		assert.Equal(t, Failed, report.Results[0].Status)
+	})
+}
+
+func TestReportWriteJUnit(t *testing.T) {
+	report := Report{Plugin: "lua", Results: []Result{
+		{Name: "license", Status: Passed, Duration: 1500 * time.Millisecond},
+		{Name: "install 1.0.0", Status: Failed, Message: "install exited with an error", Output: "\x1b[31mno such version\x1b[0m"},
+		{Name: "manifest", Status: Skipped, Message: "no plugin.toml"},
+	}}
+
+	var output strings.Builder
+	assert.Nil(t, report.WriteJUnit(&output))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="lua" tests="3" failures="1" skipped="1" time="1.500">
+    <testcase name="license" classname="asdf.plugin.test.lua" time="1.500"></testcase>
+    <testcase name="install 1.0.0" classname="asdf.plugin.test.lua" time="0.000">
+      <failure message="install exited with an error"></failure>
+      <system-out>`+"\uFFFD"+`[31mno such version`+"\uFFFD"+`[0m</system-out>
+    </testcase>
+    <testcase name="manifest" classname="asdf.plugin.test.lua" time="0.000">
+      <skipped message="no plugin.toml"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, output.String())
+}
+
+func generatePlugin(t *testing.T) (config.Config, string) {
+	t.Helper()
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+
+	url, err := repotest.GeneratePlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	return conf, url
+}
+
+func commitAll(t *testing.T, dir string) {
+	t.Helper()
+	repo, err := gogit.PlainOpen(dir)
+	assert.Nil(t, err)
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+	assert.Nil(t, worktree.AddWithOptions(&gogit.AddOptions{All: true}))
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	_, err = worktree.Commit("change", &gogit.CommitOptions{Author: signature})
+	assert.Nil(t, err)
+}
+
+func find(report Report, name string) Result {
+	for _, result := range report.Results {
+		if result.Name == name {
+			return result
+		}
+	}
+	return Result{}
+}
+
+func withoutDuration(result Result) Result {
+	result.Duration = 0
+	return result
+}
+
+func names(report Report) (names []string) {
+	for _, result := range report.Results {
+		names = append(names, result.Name)
+	}
+	return names
+}
+
+// results describes the results of report for assertion messages
+func results(report Report) string {
+	var description strings.Builder
+	for _, result := range report.Results {
+		description.WriteString(result.Name + ": " + string(result.Status) + " " + result.Message + "\n")
+	}
+	return description.String()
+}
@@ -0,0 +1,126 @@
+// Package pluginversions handles .plugin-versions files. A .plugin-versions
+// file pins the plugins a project needs to a Git URL and ref, so that asdf
+// install can add the plugins .tool-versions refers to before installing the
+// tools.
+package pluginversions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Filename is the name of the file pinning the plugins of a project
+const Filename = ".plugin-versions"
+
+const header = "# Plugins of this project, written by asdf plugin lock\n"
+
+// Entry pins a plugin to a Git URL and, optionally, a branch, tag or commit
+type Entry struct {
+	Name string
+	URL  string
+	Ref  string
+}
+
+// Action is what plugins.SyncVersions did for an entry
+type Action string
+
+const (
+	// Added means the plugin wasn't installed and SyncVersions added it
+	Added Action = "added"
+	// Updated means the plugin was at another ref and SyncVersions updated it
+	Updated Action = "updated"
+	// Outdated means the plugin is at another ref and SyncVersions left it there
+	Outdated Action = "outdated"
+	// Unchanged means the plugin is at the pinned ref, has no pinned ref, or
+	// is linked to or copied from a directory and so can't be compared
+	Unchanged Action = "unchanged"
+)
+
+// Result reports what plugins.SyncVersions did for an entry
+type Result struct {
+	Entry
+	Action Action
+	// InstalledRef is the commit the plugin was at before SyncVersions ran, it
+	// is empty for plugins SyncVersions added
+	InstalledRef string
+}
+
+// Find returns the path of the closest .plugin-versions file in dir or one of
+// its parent directories
+func Find(dir string) (path string, found bool) {
+	for {
+		path = filepath.Join(dir, Filename)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Read parses the .plugin-versions file at path. Each line holds a plugin
+// name, a Git URL and an optional ref, everything after a # is a comment.
+func Read(path string) (entries []Entry, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return entries, err
+	}
+
+	for number, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) < 2 || len(fields) > 3 {
+			return entries, fmt.Errorf("invalid line %d of %s: expected <name> <git-url> [<ref>]", number+1, path)
+		}
+
+		entry := Entry{Name: fields[0], URL: fields[1]}
+		if len(fields) == 3 {
+			entry.Ref = fields[2]
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Write writes entries to the .plugin-versions file at path, sorted by name
+func Write(path string, entries []Entry) error {
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.Name, b.Name) })
+
+	var content strings.Builder
+	content.WriteString(header)
+	for _, entry := range entries {
+		content.WriteString(strings.TrimSpace(fmt.Sprintf("%s %s %s", entry.Name, entry.URL, entry.Ref)))
+		content.WriteString("\n")
+	}
+
+	return os.WriteFile(path, []byte(content.String()), 0o666)
+}
+
+// Merge returns entries with the entries in updates replacing the entries for
+// the same plugins and the others appended
+func Merge(entries, updates []Entry) []Entry {
+	merged := slices.Clone(entries)
+	for _, update := range updates {
+		index := slices.IndexFunc(merged, func(entry Entry) bool { return entry.Name == update.Name })
+		if index == -1 {
+			merged = append(merged, update)
+		} else {
+			merged[index] = update
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,84 @@
+package pluginversions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	t.Run("returns file in parent directory", func(t *testing.T) {
+		root := t.TempDir()
+		path := filepath.Join(root, Filename)
+		assert.Nil(t, os.WriteFile(path, []byte(""), 0o666))
+		dir := filepath.Join(root, "a", "b")
+		assert.Nil(t, os.MkdirAll(dir, 0o777))
+
+		found, ok := Find(dir)
+		assert.True(t, ok)
+		assert.Equal(t, path, found)
+	})
+
+	t.Run("returns false when there is no file", func(t *testing.T) {
+		_, ok := Find(t.TempDir())
+		assert.False(t, ok)
+	})
+}
+
+func TestRead(t *testing.T) {
+	t.Run("returns entries and ignores comments and blank lines", func(t *testing.T) {
+		path := writeFile(t, "# comment\n\nlua https://github.com/Stratus3D/asdf-lua.git v1.0.0 # pinned\nruby https://github.com/asdf-vm/asdf-ruby.git\n")
+
+		entries, err := Read(path)
+		assert.Nil(t, err)
+		assert.Equal(t, []Entry{
+			{Name: "lua", URL: "https://github.com/Stratus3D/asdf-lua.git", Ref: "v1.0.0"},
+			{Name: "ruby", URL: "https://github.com/asdf-vm/asdf-ruby.git"},
+		}, entries)
+	})
+
+	t.Run("returns error when line has no URL", func(t *testing.T) {
+		path := writeFile(t, "lua https://github.com/Stratus3D/asdf-lua.git\nruby\n")
+
+		_, err := Read(path)
+		assert.ErrorContains(t, err, "invalid line 2")
+	})
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), Filename)
+	entries := []Entry{
+		{Name: "ruby", URL: "https://github.com/asdf-vm/asdf-ruby.git"},
+		{Name: "lua", URL: "https://github.com/Stratus3D/asdf-lua.git", Ref: "abc123"},
+	}
+
+	assert.Nil(t, Write(path, entries))
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, header+"lua https://github.com/Stratus3D/asdf-lua.git abc123\nruby https://github.com/asdf-vm/asdf-ruby.git\n", string(content))
+
+	read, err := Read(path)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, entries, read)
+}
+
+func TestMerge(t *testing.T) {
+	entries := []Entry{{Name: "lua", URL: "old", Ref: "1"}, {Name: "ruby", URL: "ruby"}}
+	updates := []Entry{{Name: "lua", URL: "new", Ref: "2"}, {Name: "nodejs", URL: "nodejs"}}
+
+	assert.Equal(t, []Entry{
+		{Name: "lua", URL: "new", Ref: "2"},
+		{Name: "ruby", URL: "ruby"},
+		{Name: "nodejs", URL: "nodejs"},
+	}, Merge(entries, updates))
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), Filename)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o666))
+	return path
+}
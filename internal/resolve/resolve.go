@@ -12,6 +12,7 @@ import (
 	"github.com/asdf-vm/asdf/internal/config"
 	"github.com/asdf-vm/asdf/internal/plugins"
 	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/trust"
 )
 
 // ToolVersions represents a tool along with versions specified for it
@@ -52,7 +53,10 @@ func findVersionsInDir(conf config.Config, plugin plugins.Plugin, directory stri
 	}
 
 	if legacyFiles {
-		versions, found, err := findVersionsInLegacyFile(plugin, directory)
+		versions, found, err := findVersionsInLegacyFile(conf, plugin, directory)
+		if found && err == nil {
+			err = checkTrust(conf, versions)
+		}
 
 		if found || err != nil {
 			return versions, found, err
@@ -63,17 +67,35 @@ func findVersionsInDir(conf config.Config, plugin plugins.Plugin, directory stri
 
 	if _, err = os.Stat(filepath); err == nil {
 		versions, found, err := toolversions.FindToolVersions(filepath, plugin.Name)
+		toolVersions := ToolVersions{Versions: versions, Source: conf.DefaultToolVersionsFilename, Directory: directory}
+		if found && err == nil {
+			err = checkTrust(conf, toolVersions)
+		}
+
 		if found || err != nil {
-			return ToolVersions{Versions: versions, Source: conf.DefaultToolVersionsFilename, Directory: directory}, found, err
+			return toolVersions, found, err
 		}
 	}
 
 	return versions, found, nil
 }
 
+// checkTrust returns an error if the versions contain a `path:` version and
+// the file they were read from hasn't been trusted by the user. A `path:`
+// version lets the author of the file choose which executables asdf runs.
+func checkTrust(conf config.Config, versions ToolVersions) error {
+	for _, version := range toolversions.ParseSlice(versions.Versions) {
+		if version.Type == "path" {
+			return trust.Check(conf, path.Join(versions.Directory, versions.Source))
+		}
+	}
+
+	return nil
+}
+
 // findVersionsInEnv returns the version from the environment if present
 func findVersionsInEnv(pluginName string) ([]string, string, bool) {
-	envVariableName := variableVersionName(pluginName)
+	envVariableName := VariableVersionName(pluginName)
 	versionString := os.Getenv(envVariableName)
 	if versionString == "" {
 		return []string{}, envVariableName, false
@@ -85,10 +107,10 @@ func findVersionsInEnv(pluginName string) ([]string, string, bool) {
 // the specified plugin has a list-legacy-filenames callback script. If the
 // callback script exists asdf will look for files with the given name in the
 // current and extract the version from them.
-func findVersionsInLegacyFile(plugin plugins.Plugin, directory string) (versions ToolVersions, found bool, err error) {
+func findVersionsInLegacyFile(conf config.Config, plugin plugins.Plugin, directory string) (versions ToolVersions, found bool, err error) {
 	var legacyFileNames []string
 
-	legacyFileNames, err = plugin.LegacyFilenames()
+	legacyFileNames, err = plugin.LegacyFilenames(conf)
 	if err != nil {
 		return versions, false, err
 	}
@@ -96,7 +118,7 @@ func findVersionsInLegacyFile(plugin plugins.Plugin, directory string) (versions
 	for _, filename := range legacyFileNames {
 		filepath := path.Join(directory, filename)
 		if _, err := os.Stat(filepath); err == nil {
-			versionsSlice, err := plugin.ParseLegacyVersionFile(filepath)
+			versionsSlice, err := plugin.ParseLegacyVersionFile(conf, filepath)
 
 			if len(versionsSlice) == 0 || (len(versionsSlice) == 1 && versionsSlice[0] == "") {
 				return versions, false, nil
@@ -120,6 +142,10 @@ func parseVersion(rawVersions string) []string {
 	return versions
 }
 
-func variableVersionName(toolName string) string {
-	return fmt.Sprintf("ASDF_%s_VERSION", strings.ToUpper(toolName))
+// VariableVersionName returns the name of the environment variable that
+// overrides the version of the tool. Dashes are replaced with underscores so
+// the name can be set by all shells.
+func VariableVersionName(toolName string) string {
+	name := strings.ReplaceAll(strings.ToUpper(toolName), "-", "_")
+	return fmt.Sprintf("ASDF_%s_VERSION", name)
 }
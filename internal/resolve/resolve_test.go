@@ -9,6 +9,7 @@ import (
 	"github.com/asdf-vm/asdf/internal/config"
 	"github.com/asdf-vm/asdf/internal/plugins"
 	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/asdf-vm/asdf/internal/trust"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -140,6 +141,51 @@ func TestFindVersionsInDir(t *testing.T) {
 	})
 }
 
+func TestFindVersionsInDir_Trust(t *testing.T) {
+	testDataDir := t.TempDir()
+	asdfrc := filepath.Join(t.TempDir(), ".asdfrc")
+	assert.Nil(t, os.WriteFile(asdfrc, []byte("require_trust = yes\n"), 0o666))
+	conf := config.Config{DataDir: testDataDir, DefaultToolVersionsFilename: ".tool-versions", ConfigFile: asdfrc}
+	_, err := repotest.InstallPlugin("dummy_plugin", conf.DataDir, "lua")
+	assert.Nil(t, err)
+	plugin := plugins.New(conf, "lua")
+
+	t.Run("when trust is required returns error for path version in untrusted file", func(t *testing.T) {
+		currentDir := t.TempDir()
+		versionFile := filepath.Join(currentDir, ".tool-versions")
+		assert.Nil(t, os.WriteFile(versionFile, []byte("lua path:/tmp/evil"), 0o666))
+
+		_, found, err := findVersionsInDir(conf, plugin, currentDir)
+
+		assert.True(t, found)
+		assert.Equal(t, trust.UntrustedFileError{Path: versionFile}, err)
+	})
+
+	t.Run("when trust is required returns path version from trusted file", func(t *testing.T) {
+		currentDir := t.TempDir()
+		versionFile := filepath.Join(currentDir, ".tool-versions")
+		assert.Nil(t, os.WriteFile(versionFile, []byte("lua path:/foo/bar"), 0o666))
+		assert.Nil(t, trust.Add(conf, versionFile))
+
+		toolVersion, found, err := findVersionsInDir(conf, plugin, currentDir)
+
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, toolVersion.Versions, []string{"path:/foo/bar"})
+	})
+
+	t.Run("when trust is required returns regular versions from untrusted file", func(t *testing.T) {
+		currentDir := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(currentDir, ".tool-versions"), []byte("lua 1.2.3"), 0o666))
+
+		toolVersion, found, err := findVersionsInDir(conf, plugin, currentDir)
+
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, toolVersion.Versions, []string{"1.2.3"})
+	})
+}
+
 func TestFindVersionsLegacyFiles(t *testing.T) {
 	testDataDir := t.TempDir()
 	conf := config.Config{DataDir: testDataDir}
@@ -152,14 +198,14 @@ func TestFindVersionsLegacyFiles(t *testing.T) {
 		_, err := repotest.InstallPlugin("dummy_plugin_no_download", conf.DataDir, pluginName)
 		assert.Nil(t, err)
 		plugin := plugins.New(conf, pluginName)
-		toolVersion, found, err := findVersionsInLegacyFile(plugin, t.TempDir())
+		toolVersion, found, err := findVersionsInLegacyFile(conf, plugin, t.TempDir())
 		assert.Empty(t, toolVersion.Versions)
 		assert.False(t, found)
 		assert.Nil(t, err)
 	})
 
 	t.Run("when given tool that has a list-legacy-filenames callback but file not found returns empty versions list", func(t *testing.T) {
-		toolVersion, found, err := findVersionsInLegacyFile(plugin, t.TempDir())
+		toolVersion, found, err := findVersionsInLegacyFile(conf, plugin, t.TempDir())
 		assert.Empty(t, toolVersion.Versions)
 		assert.False(t, found)
 		assert.Nil(t, err)
@@ -172,7 +218,7 @@ func TestFindVersionsLegacyFiles(t *testing.T) {
 		err = os.WriteFile(filepath.Join(currentDir, ".dummy-version"), data, 0o666)
 		assert.Nil(t, err)
 
-		toolVersion, found, err := findVersionsInLegacyFile(plugin, currentDir)
+		toolVersion, found, err := findVersionsInLegacyFile(conf, plugin, currentDir)
 		assert.Equal(t, toolVersion.Versions, []string{"1.2.3"})
 		assert.True(t, found)
 		assert.Nil(t, err)
@@ -184,7 +230,7 @@ func TestFindVersionsInEnv(t *testing.T) {
 		versions, envVariableName, found := findVersionsInEnv("non-existent")
 		assert.False(t, found)
 		assert.Empty(t, versions)
-		assert.Equal(t, envVariableName, "ASDF_NON-EXISTENT_VERSION")
+		assert.Equal(t, envVariableName, "ASDF_NON_EXISTENT_VERSION")
 	})
 
 	t.Run("when env variable is set returns version", func(t *testing.T) {
@@ -222,13 +268,13 @@ func TestVariableVersionName(t *testing.T) {
 		},
 		{
 			input:  "foo-bar",
-			output: "ASDF_FOO-BAR_VERSION",
+			output: "ASDF_FOO_BAR_VERSION",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("input: %s, output: %s", tt.input, tt.output), func(t *testing.T) {
-			assert.Equal(t, tt.output, variableVersionName(tt.input))
+			assert.Equal(t, tt.output, VariableVersionName(tt.input))
 		})
 	}
 }
@@ -0,0 +1,142 @@
+// Package scaffold writes the skeleton of a new plugin from the templates
+// embedded in it.
+//
+// Files in templates/common are written for every plugin, files in the
+// directory named after a strategy only for plugins installing tools that
+// way. Files in bin/ and scripts/ are written executable.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+const (
+	// GitHubReleases is the strategy of plugins installing the binaries
+	// attached to the GitHub releases of a tool
+	GitHubReleases = "github-releases"
+	// Source is the strategy of plugins compiling a tool from the source
+	// tarballs of its release tags
+	Source = "source"
+
+	commonDir = "common"
+)
+
+// Strategies are the ways a new plugin can install its tool
+var Strategies = []string{GitHubReleases, Source}
+
+//go:embed templates
+var templates embed.FS
+
+// repository matches the owner/name of a GitHub repository
+var repository = regexp.MustCompile(`^[[:alnum:]_.-]+/[[:alnum:]_.-]+$`)
+
+// Options are what the templates of a new plugin are filled in with
+type Options struct {
+	// Name is the name of the plugin and of the tool it installs
+	Name     string
+	Strategy string
+	// Repository is the owner/name of the GitHub repository of the tool
+	Repository string
+	// Author and Year are who the LICENSE is granted by, and since when
+	Author string
+	Year   int
+}
+
+// EnvName is the plugin name as it appears in environment variable names
+func (o Options) EnvName() string {
+	return strings.ToUpper(strings.ReplaceAll(o.Name, "-", "_"))
+}
+
+// Write writes a new plugin to dir, which must not exist or be empty, and
+// returns the paths of the files written relative to dir
+func Write(dir string, options Options) ([]string, error) {
+	if !slices.Contains(Strategies, options.Strategy) {
+		return nil, fmt.Errorf("invalid strategy %q, expected %s", options.Strategy, strings.Join(Strategies, " or "))
+	}
+
+	if !repository.MatchString(options.Repository) {
+		return nil, fmt.Errorf("invalid repository %q, expected <owner>/<name> of a GitHub repository", options.Repository)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("%s already exists and is not empty", dir)
+	}
+
+	written := []string{}
+	for _, templateDir := range []string{commonDir, options.Strategy} {
+		root := path.Join("templates", templateDir)
+		err := fs.WalkDir(templates, root, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+
+			relative := strings.TrimPrefix(name, root+"/")
+			err = writeTemplate(name, dir, relative, options)
+			if err != nil {
+				return err
+			}
+
+			written = append(written, relative)
+			return nil
+		})
+		if err != nil {
+			return written, err
+		}
+	}
+
+	slices.Sort(written)
+	return written, nil
+}
+
+// writeTemplate fills in the template name and writes it to relative in dir
+func writeTemplate(name, dir, relative string, options Options) error {
+	contents, err := fs.ReadFile(templates, name)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(contents))
+	if err != nil {
+		return fmt.Errorf("unable to parse template %s: %w", name, err)
+	}
+
+	var output bytes.Buffer
+	err = tmpl.Execute(&output, options)
+	if err != nil {
+		return fmt.Errorf("unable to fill in template %s: %w", name, err)
+	}
+
+	destination := filepath.Join(dir, filepath.FromSlash(relative))
+	err = os.MkdirAll(filepath.Dir(destination), 0o777)
+	if err != nil {
+		return err
+	}
+
+	var mode os.FileMode = 0o666
+	if isExecutable(relative) {
+		mode = 0o777
+	}
+
+	return os.WriteFile(destination, output.Bytes(), mode)
+}
+
+// isExecutable returns true for the callbacks and scripts of a plugin
+func isExecutable(relative string) bool {
+	topDir, _, found := strings.Cut(relative, "/")
+	return found && slices.Contains([]string{"bin", "scripts"}, topDir)
+}
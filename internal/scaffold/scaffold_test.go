@@ -0,0 +1,93 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	options := Options{Name: "my-tool", Strategy: GitHubReleases, Repository: "example/my-tool", Author: "Test", Year: 2024}
+
+	t.Run("writes every callback and fills in templates", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "asdf-my-tool")
+
+		files, err := Write(dir, options)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"LICENSE", "README.md", "bin/download", "bin/help.config", "bin/help.deps",
+			"bin/help.links", "bin/help.overview", "bin/install", "bin/latest-stable",
+			"bin/list-all", "bin/list-bin-paths", "lib/utils.bash", "plugin.toml",
+			"scripts/test.bash",
+		}, files)
+
+		for _, file := range files {
+			contents, err := os.ReadFile(filepath.Join(dir, file))
+			assert.Nil(t, err)
+			assert.NotContains(t, string(contents), "{{", file)
+
+			info, err := os.Stat(filepath.Join(dir, file))
+			assert.Nil(t, err)
+			executable := strings.HasPrefix(file, "bin/") || strings.HasPrefix(file, "scripts/")
+			assert.Equal(t, executable, info.Mode()&0o111 != 0, file)
+		}
+
+		utils, err := os.ReadFile(filepath.Join(dir, "lib", "utils.bash"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(utils), `GH_REPO="${ASDF_MY_TOOL_REPOSITORY:-https://github.com/example/my-tool}"`)
+
+		license, err := os.ReadFile(filepath.Join(dir, "LICENSE"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(license), "Copyright (c) 2024 Test")
+
+		var manifest struct{ Name string }
+		_, err = toml.DecodeFile(filepath.Join(dir, "plugin.toml"), &manifest)
+		assert.Nil(t, err)
+		assert.Equal(t, "my-tool", manifest.Name)
+	})
+
+	t.Run("writes download and install callbacks of strategy", func(t *testing.T) {
+		sourceOptions := options
+		sourceOptions.Strategy = Source
+		dir := t.TempDir()
+
+		_, err := Write(dir, sourceOptions)
+		assert.Nil(t, err)
+
+		download, err := os.ReadFile(filepath.Join(dir, "bin", "download"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(download), "/archive/refs/tags/v")
+
+		install, err := os.ReadFile(filepath.Join(dir, "bin", "install"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(install), "make install")
+	})
+
+	t.Run("returns error when strategy is invalid", func(t *testing.T) {
+		invalid := options
+		invalid.Strategy = "homebrew"
+
+		_, err := Write(t.TempDir(), invalid)
+		assert.EqualError(t, err, `invalid strategy "homebrew", expected github-releases or source`)
+	})
+
+	t.Run("returns error when repository is invalid", func(t *testing.T) {
+		invalid := options
+		invalid.Repository = "https://github.com/example/my-tool"
+
+		_, err := Write(t.TempDir(), invalid)
+		assert.ErrorContains(t, err, "expected <owner>/<name> of a GitHub repository")
+	})
+
+	t.Run("returns error when directory is not empty", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# my-tool\n"), 0o666))
+
+		_, err := Write(dir, options)
+		assert.EqualError(t, err, dir+" already exists and is not empty")
+	})
+}
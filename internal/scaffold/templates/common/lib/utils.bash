@@ -0,0 +1,41 @@
+#!/usr/bin/env bash
+
+set -euo pipefail
+
+TOOL_NAME="{{.Name}}"
+# The repository {{.Name}} is released from. Set ASDF_{{.EnvName}}_REPOSITORY
+# to use a mirror, or a local copy when testing the plugin.
+GH_REPO="${ASDF_{{.EnvName}}_REPOSITORY:-https://github.com/{{.Repository}}}"
+
+fail() {
+  echo -e "asdf-$TOOL_NAME: $*" >&2
+  exit 1
+}
+
+curl_opts=(-fsSL)
+
+# Authenticate with GitHub to avoid its API rate limits
+if [ -n "${GITHUB_API_TOKEN:-}" ]; then
+  curl_opts=("${curl_opts[@]}" -H "Authorization: token $GITHUB_API_TOKEN")
+fi
+
+sort_versions() {
+  sed 'h; s/[+-]/./g; s/.p\([[:digit:]]\)/.z\1/; s/$/.z/; G; s/\n/ /' |
+    LC_ALL=C sort -t. -k 1,1 -k 2,2n -k 3,3n -k 4,4n -k 5,5n | awk '{print $2}'
+}
+
+# list_all_versions prints the versions of the release tags of the repository,
+# without the v prefix
+list_all_versions() {
+  git ls-remote --tags --refs "$GH_REPO" |
+    grep -o 'refs/tags/.*' | cut -d/ -f3- |
+    sed 's/^v//'
+}
+
+# download prints the contents of url to the file path
+download() {
+  local url="$1"
+  local path="$2"
+
+  curl "${curl_opts[@]}" -o "$path" -C - "$url" || fail "Could not download $url"
+}
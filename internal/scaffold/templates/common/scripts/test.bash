@@ -0,0 +1,11 @@
+#!/usr/bin/env bash
+
+# Installs the plugin from this directory with asdf plugin test, and checks it
+# lists, downloads and installs a version of {{.Name}}. Arguments are passed on
+# to asdf plugin test, for example --asdf-tool-version 1.0.0
+
+set -euo pipefail
+
+plugin_dir="$(cd "$(dirname "$0")/.." && pwd)"
+
+asdf plugin test "$@" {{.Name}} "$plugin_dir"
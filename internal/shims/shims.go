@@ -2,6 +2,7 @@
 package shims
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -18,6 +19,7 @@ import (
 	"github.com/asdf-vm/asdf/internal/plugins"
 	"github.com/asdf-vm/asdf/internal/resolve"
 	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/trust"
 	"golang.org/x/sys/unix"
 )
 
@@ -75,6 +77,9 @@ func FindExecutable(conf config.Config, shimName, currentDirectory string) (stri
 		if plugin.Exists() == nil {
 
 			versions, found, err := resolve.Version(conf, plugin, currentDirectory)
+			if _, ok := err.(trust.UntrustedFileError); ok {
+				return "", plugins.Plugin{}, "", false, err
+			}
 			if err != nil {
 				return "", plugins.Plugin{}, "", false, nil
 			}
@@ -211,7 +216,7 @@ func getCustomExecutablePath(conf config.Config, plugin plugins.Plugin, shimName
 		return "", err
 	}
 
-	err = plugin.RunCallback("exec-path", []string{installPath, shimName, relativePath}, env, &stdOut, &stdErr)
+	err = plugin.RunCallback(conf, "exec-path", []string{installPath, shimName, relativePath}, env, &stdOut, &stdErr)
 	if err != nil {
 		return "", err
 	}
@@ -315,6 +320,45 @@ func Write(conf config.Config, plugin plugins.Plugin, version toolversions.Versi
 	return os.WriteFile(shimPath, []byte(encode(shimName, versions)), 0o777)
 }
 
+// RemoveVersion removes version of plugin from the shims named shimNames,
+// without regenerating the shims of other tools. A shim that no longer runs
+// any tool version is deleted.
+func RemoveVersion(conf config.Config, plugin plugins.Plugin, version toolversions.Version, shimNames []string) error {
+	formatted := toolversions.Format(version)
+
+	for _, shimName := range shimNames {
+		shimPath := Path(conf, shimName)
+		toolVersions, err := GetToolsAndVersionsFromShimFile(shimPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var remaining []toolversions.ToolVersions
+		for _, toolVersion := range toolVersions {
+			if toolVersion.Name == plugin.Name {
+				toolVersion.Versions = slices.DeleteFunc(slices.Clone(toolVersion.Versions), func(v string) bool { return v == formatted })
+			}
+			if len(toolVersion.Versions) > 0 {
+				remaining = append(remaining, toolVersion)
+			}
+		}
+
+		if len(remaining) == 0 {
+			err = os.Remove(shimPath)
+		} else {
+			err = os.WriteFile(shimPath, []byte(encode(shimName, remaining)), 0o777)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Path returns the path for a shim script
 func Path(conf config.Config, shimName string) string {
 	return filepath.Join(conf.DataDir, shimDirName, shimName)
@@ -359,7 +403,7 @@ func ToolExecutables(conf config.Config, plugin plugins.Plugin, version toolvers
 // ExecutablePaths returns a slice of absolute directory paths that tool
 // executables are contained in.
 func ExecutablePaths(conf config.Config, plugin plugins.Plugin, version toolversions.Version) ([]string, error) {
-	dirs, err := ExecutableDirs(plugin)
+	dirs, err := ExecutableDirs(conf, plugin)
 	if err != nil {
 		return []string{}, err
 	}
@@ -370,11 +414,19 @@ func ExecutablePaths(conf config.Config, plugin plugins.Plugin, version toolvers
 
 // ExecutableDirs returns a slice of directory names that tool executables are
 // contained in
-func ExecutableDirs(plugin plugins.Plugin) ([]string, error) {
+func ExecutableDirs(conf config.Config, plugin plugins.Plugin) ([]string, error) {
+	manifest, found, err := plugin.Manifest()
+	if err != nil {
+		return []string{}, err
+	}
+	if found && manifest.BinPaths != nil {
+		return manifest.BinPaths, nil
+	}
+
 	var stdOut strings.Builder
 	var stdErr strings.Builder
 
-	err := plugin.RunCallback("list-bin-paths", []string{}, map[string]string{}, &stdOut, &stdErr)
+	err = plugin.RunCallback(conf, "list-bin-paths", []string{}, map[string]string{}, &stdOut, &stdErr)
 	if err != nil {
 		if _, ok := err.(plugins.NoCallbackError); ok {
 			// assume all executables are located in /bin directory
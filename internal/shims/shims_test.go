@@ -14,6 +14,7 @@ import (
 	"github.com/asdf-vm/asdf/internal/plugins"
 	"github.com/asdf-vm/asdf/internal/repotest"
 	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/trust"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/sys/unix"
 )
@@ -88,6 +89,11 @@ func TestFindExecutable(t *testing.T) {
 		assert.Nil(t, os.WriteFile(toolpath, []byte(fmt.Sprintf("lua %s\n", pathVersion)), 0o666))
 		assert.Nil(t, GenerateAll(conf, &stdout, &stderr))
 
+		_, _, _, found, err := FindExecutable(conf, "dummy", currentDir)
+		assert.False(t, found)
+		assert.Equal(t, trust.UntrustedFileError{Path: toolpath}, err)
+
+		assert.Nil(t, trust.Add(conf, toolpath))
 		executable, gotPlugin, version, found, err := FindExecutable(conf, "dummy", currentDir)
 		assert.Equal(t, plugin, gotPlugin)
 		assert.Equal(t, version, pathVersion)
@@ -325,6 +331,40 @@ func TestWrite(t *testing.T) {
 	})
 }
 
+func TestRemoveVersion(t *testing.T) {
+	version := toolversions.Version{Type: "version", Value: "1.1.0"}
+	version2 := toolversions.Version{Type: "version", Value: "2.0.0"}
+	conf, plugin := generateConfig(t)
+	installVersion(t, conf, plugin, version.Value)
+	installVersion(t, conf, plugin, version2.Value)
+	executables, err := ToolExecutables(conf, plugin, version)
+	assert.Nil(t, err)
+	shimName := filepath.Base(executables[0])
+	shimPath := Path(conf, shimName)
+
+	t.Run("removes version from shim running other versions", func(t *testing.T) {
+		assert.Nil(t, Write(conf, plugin, version, executables[0]))
+		assert.Nil(t, Write(conf, plugin, version2, executables[0]))
+
+		assert.Nil(t, RemoveVersion(conf, plugin, version, []string{shimName}))
+
+		content, err := os.ReadFile(shimPath)
+		assert.Nil(t, err)
+		want := "#!/usr/bin/env bash\n# asdf-plugin: lua 2.0.0\nexec asdf exec \"dummy\" \"$@\""
+		assert.Equal(t, want, string(content))
+		os.Remove(shimPath)
+	})
+
+	t.Run("deletes shim running no other version", func(t *testing.T) {
+		assert.Nil(t, Write(conf, plugin, version, executables[0]))
+
+		assert.Nil(t, RemoveVersion(conf, plugin, version, []string{shimName, "missing"}))
+
+		_, err := os.Stat(shimPath)
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+}
+
 func TestToolExecutables(t *testing.T) {
 	version := toolversions.Version{Type: "version", Value: "1.1.0"}
 	conf, plugin := generateConfig(t)
@@ -406,7 +446,7 @@ func TestExecutableDirs(t *testing.T) {
 	installVersion(t, conf, plugin, "1.2.3")
 
 	t.Run("returns list only containing 'bin' when list-bin-paths callback missing", func(t *testing.T) {
-		executables, err := ExecutableDirs(plugin)
+		executables, err := ExecutableDirs(conf, plugin)
 		assert.Nil(t, err)
 		assert.Equal(t, executables, []string{"bin"})
 	})
@@ -416,10 +456,20 @@ func TestExecutableDirs(t *testing.T) {
 		err := os.WriteFile(filepath.Join(plugin.Dir, "bin", "list-bin-paths"), data, 0o777)
 		assert.Nil(t, err)
 
-		executables, err := ExecutableDirs(plugin)
+		executables, err := ExecutableDirs(conf, plugin)
 		assert.Nil(t, err)
 		assert.Equal(t, executables, []string{"foo", "bar"})
 	})
+
+	t.Run("returns bin paths declared in manifest", func(t *testing.T) {
+		data := []byte("bin_paths = [\"bin\", \"libexec\"]\n")
+		err := os.WriteFile(filepath.Join(plugin.Dir, plugins.ManifestFilename), data, 0o666)
+		assert.Nil(t, err)
+
+		executables, err := ExecutableDirs(conf, plugin)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"bin", "libexec"}, executables)
+	})
 }
 
 // Helper functions
@@ -0,0 +1,187 @@
+// Package toolenv computes the environment tool versions need to run: the
+// directories containing their executables, which are put on PATH, and the
+// variables set by each plugin's exec-env callback.
+package toolenv
+
+import (
+	"io"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/execenv"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/resolve"
+	"github.com/asdf-vm/asdf/internal/shims"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+)
+
+// ToolVersion is a single version of a tool selected for use in an
+// environment
+type ToolVersion struct {
+	Plugin  plugins.Plugin
+	Version toolversions.Version
+}
+
+// Build returns a copy of base with the environment needed by each tool
+// version applied. Tool versions earlier in the slice take precedence, their
+// executable directories come first on PATH and their exec-env variables
+// override those set by later tool versions. Anything the exec-env callbacks
+// print is written to stderr.
+func Build(conf config.Config, toolVersions []ToolVersion, base map[string]string, stderr io.Writer) (map[string]string, error) {
+	env := maps.Clone(base)
+	if env == nil {
+		env = map[string]string{}
+	}
+
+	for i := len(toolVersions) - 1; i >= 0; i-- {
+		var err error
+		env, err = apply(conf, toolVersions[i], env, stderr)
+		if err != nil {
+			return env, err
+		}
+	}
+
+	return env, nil
+}
+
+func apply(conf config.Config, toolVersion ToolVersion, env map[string]string, stderr io.Writer) (map[string]string, error) {
+	plugin, version := toolVersion.Plugin, toolVersion.Version
+	if version.Type == "system" {
+		return env, nil
+	}
+
+	execPaths, err := shims.ExecutablePaths(conf, plugin, version)
+	if err != nil {
+		return env, err
+	}
+
+	env["PATH"] = prependPath(execPaths, env["PATH"])
+
+	// The ASDF_INSTALL_* variables are only inputs to the callback, so only the
+	// changes the callback makes are applied to the environment.
+	callbackEnv := execenv.MergeEnv(maps.Clone(env), map[string]string{
+		"ASDF_INSTALL_TYPE":    version.Type,
+		"ASDF_INSTALL_VERSION": version.Value,
+		"ASDF_INSTALL_PATH":    installs.InstallPath(conf, plugin, version),
+	})
+
+	set, unset, err := execenv.GenerateChanges(plugin, callbackEnv, stderr)
+	if _, ok := err.(plugins.NoCallbackError); !ok && err != nil {
+		return env, err
+	}
+
+	for _, name := range unset {
+		delete(env, name)
+	}
+
+	return execenv.MergeEnv(env, set), nil
+}
+
+// ExecutablePaths returns the directories containing the executables of each
+// tool version, in the order of toolVersions. System versions have none.
+func ExecutablePaths(conf config.Config, toolVersions []ToolVersion) (paths []string, err error) {
+	for _, toolVersion := range toolVersions {
+		if toolVersion.Version.Type == "system" {
+			continue
+		}
+
+		execPaths, err := shims.ExecutablePaths(conf, toolVersion.Plugin, toolVersion.Version)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, execPaths...)
+	}
+
+	return paths, nil
+}
+
+// Resolve returns the tool version to use for every installed plugin that has
+// a version set in directory. Tools are ordered as they appear in the version
+// files, starting with the closest one. Tools with no installed version are
+// skipped.
+func Resolve(conf config.Config, directory string) ([]ToolVersion, error) {
+	allPlugins, err := plugins.List(conf, false, false)
+	if err != nil {
+		return []ToolVersion{}, err
+	}
+
+	order := toolOrder(conf, directory)
+	slices.SortStableFunc(allPlugins, func(a, b plugins.Plugin) int {
+		return orderIndex(order, a.Name) - orderIndex(order, b.Name)
+	})
+
+	var toolVersions []ToolVersion
+	for _, plugin := range allPlugins {
+		toolVersion, found, err := ResolveTool(conf, plugin, directory)
+		if err != nil {
+			return toolVersions, err
+		}
+
+		if found {
+			toolVersions = append(toolVersions, toolVersion)
+		}
+	}
+
+	return toolVersions, nil
+}
+
+// ResolveTool returns the first installed version of the tool that is set in
+// directory. The system version is always considered installed.
+func ResolveTool(conf config.Config, plugin plugins.Plugin, directory string) (ToolVersion, bool, error) {
+	versions, found, err := resolve.Version(conf, plugin, directory)
+	if err != nil || !found {
+		return ToolVersion{}, false, err
+	}
+
+	for _, version := range toolversions.ParseSlice(versions.Versions) {
+		if version.Type == "system" || installs.IsInstalled(conf, plugin, version) {
+			return ToolVersion{Plugin: plugin, Version: version}, true, nil
+		}
+	}
+
+	return ToolVersion{}, false, nil
+}
+
+// toolOrder returns the names of the tools listed in version files found in
+// directory and its parents, closest file first and in the order they are
+// listed within each file.
+func toolOrder(conf config.Config, directory string) (names []string) {
+	for {
+		filename := path.Join(directory, conf.DefaultToolVersionsFilename)
+		if _, err := os.Stat(filename); err == nil {
+			toolVersions, _ := toolversions.GetAllToolsAndVersions(filename)
+			for _, toolVersion := range toolVersions {
+				if !slices.Contains(names, toolVersion.Name) {
+					names = append(names, toolVersion.Name)
+				}
+			}
+		}
+
+		nextDir := path.Dir(directory)
+		if nextDir == directory {
+			return names
+		}
+		directory = nextDir
+	}
+}
+
+func orderIndex(order []string, name string) int {
+	index := slices.Index(order, name)
+	if index < 0 {
+		return len(order)
+	}
+	return index
+}
+
+func prependPath(paths []string, currentPath string) string {
+	if currentPath == "" {
+		return strings.Join(paths, ":")
+	}
+
+	return strings.Join(append(slices.Clone(paths), currentPath), ":")
+}
@@ -0,0 +1,185 @@
+package toolenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/installtest"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPluginName  = "lua"
+	testPluginName2 = "ruby"
+)
+
+func TestBuild(t *testing.T) {
+	conf, lua, ruby := generateConfig(t)
+	luaVersion := toolversions.Version{Type: "version", Value: "1.0.0"}
+	rubyVersion := toolversions.Version{Type: "version", Value: "2.0.0"}
+	installVersion(t, conf, lua, luaVersion.Value)
+	installVersion(t, conf, ruby, rubyVersion.Value)
+	luaBin := filepath.Join(installs.InstallPath(conf, lua, luaVersion), "bin")
+	rubyBin := filepath.Join(installs.InstallPath(conf, ruby, rubyVersion), "bin")
+
+	t.Run("prepends executable paths to PATH", func(t *testing.T) {
+		base := map[string]string{"PATH": "/usr/bin", "HOME": "/home/test"}
+		env, err := Build(conf, []ToolVersion{{Plugin: lua, Version: luaVersion}}, base, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, luaBin+":/usr/bin", env["PATH"])
+		assert.Equal(t, "/home/test", env["HOME"])
+	})
+
+	t.Run("does not modify base", func(t *testing.T) {
+		base := map[string]string{"PATH": "/usr/bin"}
+		_, err := Build(conf, []ToolVersion{{Plugin: lua, Version: luaVersion}}, base, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, "/usr/bin", base["PATH"])
+	})
+
+	t.Run("puts earlier tool versions first on PATH", func(t *testing.T) {
+		toolVersions := []ToolVersion{{Plugin: ruby, Version: rubyVersion}, {Plugin: lua, Version: luaVersion}}
+		env, err := Build(conf, toolVersions, map[string]string{"PATH": "/usr/bin"}, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, rubyBin+":"+luaBin+":/usr/bin", env["PATH"])
+	})
+
+	t.Run("does not change PATH for system version", func(t *testing.T) {
+		toolVersions := []ToolVersion{{Plugin: lua, Version: toolversions.Version{Type: "system"}}}
+		env, err := Build(conf, toolVersions, map[string]string{"PATH": "/usr/bin"}, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, "/usr/bin", env["PATH"])
+	})
+
+	t.Run("applies variables from exec-env callback", func(t *testing.T) {
+		assert.Nil(t, repotest.WritePluginCallback(lua.Dir, "exec-env", "#!/usr/bin/env bash\nexport FOO=\"lua-$ASDF_INSTALL_VERSION\""))
+		assert.Nil(t, repotest.WritePluginCallback(ruby.Dir, "exec-env", "#!/usr/bin/env bash\nexport FOO=ruby BAR=ruby"))
+		defer os.Remove(filepath.Join(lua.Dir, "bin", "exec-env"))
+		defer os.Remove(filepath.Join(ruby.Dir, "bin", "exec-env"))
+
+		toolVersions := []ToolVersion{{Plugin: lua, Version: luaVersion}, {Plugin: ruby, Version: rubyVersion}}
+		env, err := Build(conf, toolVersions, map[string]string{"PATH": "/usr/bin"}, &strings.Builder{})
+		assert.Nil(t, err)
+		assert.Equal(t, "lua-1.0.0", env["FOO"])
+		assert.Equal(t, "ruby", env["BAR"])
+		_, found := env["ASDF_INSTALL_VERSION"]
+		assert.False(t, found)
+		_, found = env["SHLVL"]
+		assert.False(t, found)
+	})
+
+	t.Run("removes variables unset by exec-env callback", func(t *testing.T) {
+		assert.Nil(t, repotest.WritePluginCallback(lua.Dir, "exec-env", "#!/usr/bin/env bash\nunset GEM_HOME"))
+		defer os.Remove(filepath.Join(lua.Dir, "bin", "exec-env"))
+
+		base := map[string]string{"PATH": "/usr/bin", "GEM_HOME": "/gems"}
+		env, err := Build(conf, []ToolVersion{{Plugin: lua, Version: luaVersion}}, base, &strings.Builder{})
+		assert.Nil(t, err)
+		_, found := env["GEM_HOME"]
+		assert.False(t, found)
+	})
+}
+
+func TestExecutablePaths(t *testing.T) {
+	conf, lua, ruby := generateConfig(t)
+	luaVersion := toolversions.Version{Type: "version", Value: "1.0.0"}
+	rubyVersion := toolversions.Version{Type: "version", Value: "2.0.0"}
+	installVersion(t, conf, lua, luaVersion.Value)
+	installVersion(t, conf, ruby, rubyVersion.Value)
+
+	t.Run("returns paths in order of tool versions and skips system versions", func(t *testing.T) {
+		toolVersions := []ToolVersion{
+			{Plugin: ruby, Version: rubyVersion},
+			{Plugin: lua, Version: toolversions.Version{Type: "system"}},
+			{Plugin: lua, Version: luaVersion},
+		}
+		paths, err := ExecutablePaths(conf, toolVersions)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(installs.InstallPath(conf, ruby, rubyVersion), "bin"),
+			filepath.Join(installs.InstallPath(conf, lua, luaVersion), "bin"),
+		}, paths)
+	})
+}
+
+func TestResolve(t *testing.T) {
+	conf, lua, ruby := generateConfig(t)
+	installVersion(t, conf, lua, "1.0.0")
+	installVersion(t, conf, ruby, "2.0.0")
+
+	t.Run("returns tools in the order they appear in the version file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeToolVersions(t, dir, "ruby 2.0.0\nlua 1.0.0\n")
+
+		toolVersions, err := Resolve(conf, dir)
+		assert.Nil(t, err)
+		assert.Equal(t, []ToolVersion{
+			{Plugin: ruby, Version: toolversions.Version{Type: "version", Value: "2.0.0"}},
+			{Plugin: lua, Version: toolversions.Version{Type: "version", Value: "1.0.0"}},
+		}, toolVersions)
+	})
+
+	t.Run("orders tools in closest version file first", func(t *testing.T) {
+		dir := t.TempDir()
+		subDir := filepath.Join(dir, "sub")
+		assert.Nil(t, os.MkdirAll(subDir, 0o777))
+		writeToolVersions(t, dir, "ruby 2.0.0\n")
+		writeToolVersions(t, subDir, "lua 1.0.0\n")
+
+		toolVersions, err := Resolve(conf, subDir)
+		assert.Nil(t, err)
+		assert.Len(t, toolVersions, 2)
+		assert.Equal(t, lua, toolVersions[0].Plugin)
+		assert.Equal(t, ruby, toolVersions[1].Plugin)
+	})
+
+	t.Run("skips tools without an installed version", func(t *testing.T) {
+		dir := t.TempDir()
+		writeToolVersions(t, dir, "ruby 9.9.9\nlua 0.0.1 1.0.0\n")
+
+		toolVersions, err := Resolve(conf, dir)
+		assert.Nil(t, err)
+		assert.Equal(t, []ToolVersion{
+			{Plugin: lua, Version: toolversions.Version{Type: "version", Value: "1.0.0"}},
+		}, toolVersions)
+	})
+}
+
+func generateConfig(t *testing.T) (config.Config, plugins.Plugin, plugins.Plugin) {
+	t.Helper()
+	testDataDir := t.TempDir()
+	conf, err := config.LoadConfig()
+	assert.Nil(t, err)
+	conf.DataDir = testDataDir
+	conf.ConfigFile = "non-existent"
+	conf.DefaultToolVersionsFilename = ".tool-versions"
+
+	return conf, installPlugin(t, conf, testPluginName), installPlugin(t, conf, testPluginName2)
+}
+
+func installPlugin(t *testing.T, conf config.Config, pluginName string) plugins.Plugin {
+	t.Helper()
+	_, err := repotest.InstallPlugin("dummy_plugin", conf.DataDir, pluginName)
+	assert.Nil(t, err)
+
+	return plugins.New(conf, pluginName)
+}
+
+func installVersion(t *testing.T, conf config.Config, plugin plugins.Plugin, version string) {
+	t.Helper()
+	err := installtest.InstallOneVersion(conf, plugin, "version", version)
+	assert.Nil(t, err)
+}
+
+func writeToolVersions(t *testing.T, dir, contents string) {
+	t.Helper()
+	path := filepath.Join(dir, ".tool-versions")
+	assert.Nil(t, os.WriteFile(path, []byte(contents), 0o666))
+}
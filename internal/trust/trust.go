@@ -0,0 +1,162 @@
+// Package trust manages the store of version files the user has explicitly
+// allowed asdf to honor. Some settings in a version file, like `path:`
+// versions, let whoever wrote the file decide which binaries asdf runs, so
+// they are only honored for trusted files. Trust is recorded against a hash of
+// the file's contents, so any change to a trusted file revokes trust until the
+// user approves it again.
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/config"
+)
+
+const trustDirName = "trust"
+
+// UntrustedFileError is returned when a version file contains settings that
+// require trust, like `path:` versions or pinned plugins, and the file has not
+// been trusted by the user.
+type UntrustedFileError struct {
+	Path string
+}
+
+func (e UntrustedFileError) Error() string {
+	return fmt.Sprintf("%s is not trusted, refusing to use it. Review the file and run `asdf trust %s` to allow it", e.Path, e.Path)
+}
+
+// Add marks the file at path as trusted in its current state.
+func Add(conf config.Config, path string) error {
+	absPath, hash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(Directory(conf), 0o777)
+	if err != nil {
+		return fmt.Errorf("unable to create trust directory: %w", err)
+	}
+
+	entry := fmt.Sprintf("%s %s\n", hash, absPath)
+	return os.WriteFile(entryPath(conf, absPath), []byte(entry), 0o666)
+}
+
+// Remove revokes trust for the file at path. Removing trust for a file that
+// isn't trusted is not an error.
+func Remove(conf config.Config, path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(entryPath(conf, absPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// IsTrusted returns true if the file at path has been trusted and hasn't
+// changed since.
+func IsTrusted(conf config.Config, path string) (bool, error) {
+	absPath, hash, err := hashFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	contents, err := os.ReadFile(entryPath(conf, absPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	trustedHash, _, _ := strings.Cut(string(contents), " ")
+	return trustedHash == hash, nil
+}
+
+// Check returns an UntrustedFileError if trust is required by the asdfrc and
+// the file at path isn't trusted.
+func Check(conf config.Config, path string) error {
+	required, err := conf.RequireTrust()
+	if err != nil {
+		return err
+	}
+
+	if !required {
+		return nil
+	}
+
+	return Require(conf, path)
+}
+
+// Require returns an UntrustedFileError if the file at path isn't trusted,
+// whatever the asdfrc says. It is used for files that make asdf run code from
+// elsewhere, like .plugin-versions files. Files directly in the home
+// directory, like the global .tool-versions file, are written by the user and
+// always trusted.
+func Require(conf config.Config, path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if conf.Home != "" && filepath.Dir(absPath) == filepath.Clean(conf.Home) {
+		return nil
+	}
+
+	trusted, err := IsTrusted(conf, path)
+	if err != nil {
+		return err
+	}
+
+	if !trusted {
+		return UntrustedFileError{Path: path}
+	}
+
+	return nil
+}
+
+// Directory returns the path to the trust store for the current configuration.
+func Directory(conf config.Config) string {
+	return filepath.Join(conf.DataDir, trustDirName)
+}
+
+// RecordPath returns the path of the file recording trust for the file at
+// path, which is changed whenever the file is trusted or untrusted.
+func RecordPath(conf config.Config, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return entryPath(conf, absPath), nil
+}
+
+func entryPath(conf config.Config, absPath string) string {
+	sum := sha256.Sum256([]byte(absPath))
+	return filepath.Join(Directory(conf), hex.EncodeToString(sum[:]))
+}
+
+func hashFile(path string) (string, string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	contents, err := os.ReadFile(absPath)
+	if err != nil {
+		return absPath, "", err
+	}
+
+	sum := sha256.Sum256(contents)
+	return absPath, hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,155 @@
+package trust
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdd(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir()}
+
+	t.Run("marks file as trusted", func(t *testing.T) {
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+
+		assert.Nil(t, Add(conf, path))
+
+		trusted, err := IsTrusted(conf, path)
+		assert.Nil(t, err)
+		assert.True(t, trusted)
+	})
+
+	t.Run("returns error when file does not exist", func(t *testing.T) {
+		err := Add(conf, filepath.Join(t.TempDir(), ".tool-versions"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestRemove(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir()}
+
+	t.Run("revokes trust for trusted file", func(t *testing.T) {
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+		assert.Nil(t, Add(conf, path))
+
+		assert.Nil(t, Remove(conf, path))
+
+		trusted, err := IsTrusted(conf, path)
+		assert.Nil(t, err)
+		assert.False(t, trusted)
+	})
+
+	t.Run("does not return error when file is not trusted", func(t *testing.T) {
+		path := writeVersionFile(t, "lua 1.0.0\n")
+		assert.Nil(t, Remove(conf, path))
+	})
+}
+
+func TestIsTrusted(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir()}
+
+	t.Run("returns false when file has never been trusted", func(t *testing.T) {
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+
+		trusted, err := IsTrusted(conf, path)
+		assert.Nil(t, err)
+		assert.False(t, trusted)
+	})
+
+	t.Run("returns false when file changed after being trusted", func(t *testing.T) {
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+		assert.Nil(t, Add(conf, path))
+
+		assert.Nil(t, os.WriteFile(path, []byte("lua path:/tmp/evil\n"), 0o666))
+
+		trusted, err := IsTrusted(conf, path)
+		assert.Nil(t, err)
+		assert.False(t, trusted)
+	})
+
+	t.Run("trust is stored per data dir", func(t *testing.T) {
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+		assert.Nil(t, Add(conf, path))
+
+		otherConf := config.Config{DataDir: t.TempDir()}
+		trusted, err := IsTrusted(otherConf, path)
+		assert.Nil(t, err)
+		assert.False(t, trusted)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("returns nil when trust is not required", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir(), ConfigFile: writeAsdfrc(t, "no")}
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+
+		assert.Nil(t, Check(conf, path))
+	})
+
+	t.Run("returns UntrustedFileError when file is not trusted", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir(), ConfigFile: "non-existent"}
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+
+		assert.Equal(t, UntrustedFileError{Path: path}, Check(conf, path))
+	})
+
+	t.Run("returns nil for file in home directory", func(t *testing.T) {
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+		conf := config.Config{DataDir: t.TempDir(), ConfigFile: "non-existent", Home: filepath.Dir(path)}
+
+		assert.Nil(t, Check(conf, path))
+	})
+
+	t.Run("returns UntrustedFileError when trust is required and file is not trusted", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir(), ConfigFile: writeAsdfrc(t, "yes")}
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+
+		err := Check(conf, path)
+		assert.Equal(t, UntrustedFileError{Path: path}, err)
+		assert.ErrorContains(t, err, "asdf trust "+path)
+	})
+
+	t.Run("returns nil when trust is required and file is trusted", func(t *testing.T) {
+		conf := config.Config{DataDir: t.TempDir(), ConfigFile: writeAsdfrc(t, "yes")}
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+		assert.Nil(t, Add(conf, path))
+
+		assert.Nil(t, Check(conf, path))
+	})
+}
+
+func TestRequire(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir(), ConfigFile: writeAsdfrc(t, "no")}
+
+	t.Run("returns UntrustedFileError when trust is not required by asdfrc", func(t *testing.T) {
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+
+		assert.Equal(t, UntrustedFileError{Path: path}, Require(conf, path))
+	})
+
+	t.Run("returns nil when file is trusted", func(t *testing.T) {
+		path := writeVersionFile(t, "lua path:/foo/bar\n")
+		assert.Nil(t, Add(conf, path))
+
+		assert.Nil(t, Require(conf, path))
+	})
+}
+
+func writeVersionFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".tool-versions")
+	assert.Nil(t, os.WriteFile(path, []byte(contents), 0o666))
+	return path
+}
+
+func writeAsdfrc(t *testing.T, requireTrust string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".asdfrc")
+	assert.Nil(t, os.WriteFile(path, []byte("require_trust = "+requireTrust+"\n"), 0o666))
+	return path
+}
@@ -3,11 +3,13 @@
 package versions
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/asdf-vm/asdf/internal/config"
@@ -114,7 +116,7 @@ func InstallVersion(conf config.Config, plugin plugins.Plugin, version toolversi
 
 	resolvedVersion := ""
 	if version.Type == latestVersion {
-		resolvedVersion, err = Latest(plugin, version.Value)
+		resolvedVersion, err = Latest(conf, plugin, version.Value)
 		if err != nil {
 			return err
 		}
@@ -125,6 +127,12 @@ func InstallVersion(conf config.Config, plugin plugins.Plugin, version toolversi
 
 // InstallOneVersion installs a specific version of a specific tool
 func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr string, keepDownload bool, stdOut io.Writer, stdErr io.Writer) error {
+	return InstallOneVersionContext(context.Background(), conf, plugin, versionStr, keepDownload, stdOut, stdErr)
+}
+
+// InstallOneVersionContext is like InstallOneVersion but the callbacks and
+// hooks it runs are killed when ctx is done
+func InstallOneVersionContext(ctx context.Context, conf config.Config, plugin plugins.Plugin, versionStr string, keepDownload bool, stdOut io.Writer, stdErr io.Writer) error {
 	err := plugin.Exists()
 	if err != nil {
 		return err
@@ -139,6 +147,12 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr str
 	if version.Type == "path" {
 		return UninstallableVersionError{versionType: "path"}
 	}
+
+	err = plugin.CheckPlatform(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
 	downloadDir := installs.DownloadPath(conf, plugin, version)
 	installDir := installs.InstallPath(conf, plugin, version)
 
@@ -161,17 +175,17 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr str
 		return fmt.Errorf("unable to create download dir: %w", err)
 	}
 
-	err = hook.RunWithOutput(conf, fmt.Sprintf("pre_asdf_download_%s", plugin.Name), []string{version.Value}, stdOut, stdErr)
+	err = hook.RunWithOutputContext(ctx, conf, fmt.Sprintf("pre_asdf_download_%s", plugin.Name), []string{version.Value}, stdOut, stdErr)
 	if err != nil {
 		return fmt.Errorf("failed to run pre-download hook: %w", err)
 	}
 
-	err = plugin.RunCallback("download", []string{}, env, stdOut, stdErr)
+	err = plugin.RunCallbackContext(ctx, conf, "download", []string{}, env, stdOut, stdErr)
 	if _, ok := err.(plugins.NoCallbackError); err != nil && !ok {
 		return fmt.Errorf("failed to run download callback: %w", err)
 	}
 
-	err = hook.RunWithOutput(conf, fmt.Sprintf("pre_asdf_install_%s", plugin.Name), []string{version.Value}, stdOut, stdErr)
+	err = hook.RunWithOutputContext(ctx, conf, fmt.Sprintf("pre_asdf_install_%s", plugin.Name), []string{version.Value}, stdOut, stdErr)
 	if err != nil {
 		return fmt.Errorf("failed to run pre-install hook: %w", err)
 	}
@@ -181,7 +195,7 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr str
 		return fmt.Errorf("unable to create install dir: %w", err)
 	}
 
-	err = plugin.RunCallback("install", []string{}, env, stdOut, stdErr)
+	err = plugin.RunCallbackContext(ctx, conf, "install", []string{}, env, stdOut, stdErr)
 	if err != nil {
 		return fmt.Errorf("failed to run install callback: %w", err)
 	}
@@ -192,7 +206,7 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr str
 		return fmt.Errorf("unable to generate shims post-install: %w", err)
 	}
 
-	err = hook.RunWithOutput(conf, fmt.Sprintf("post_asdf_install_%s", plugin.Name), []string{version.Value}, stdOut, stdErr)
+	err = hook.RunWithOutputContext(ctx, conf, fmt.Sprintf("post_asdf_install_%s", plugin.Name), []string{version.Value}, stdOut, stdErr)
 	if err != nil {
 		return fmt.Errorf("failed to run post-install hook: %w", err)
 	}
@@ -234,17 +248,23 @@ func asdfConcurrency(conf config.Config) string {
 // the version it returns. If the callback is missing it invokes the list-all
 // callback and returns the last version matching the query, if a query is
 // provided.
-func Latest(plugin plugins.Plugin, query string) (version string, err error) {
+func Latest(conf config.Config, plugin plugins.Plugin, query string) (version string, err error) {
+	return LatestContext(context.Background(), conf, plugin, query)
+}
+
+// LatestContext is like Latest but the callbacks it runs are killed when ctx
+// is done
+func LatestContext(ctx context.Context, conf config.Config, plugin plugins.Plugin, query string) (version string, err error) {
 	var stdOut strings.Builder
 	var stdErr strings.Builder
 
-	err = plugin.RunCallback("latest-stable", []string{query}, map[string]string{}, &stdOut, &stdErr)
+	err = plugin.RunCallbackContext(ctx, conf, "latest-stable", []string{query}, map[string]string{}, &stdOut, &stdErr)
 	if err != nil {
 		if _, ok := err.(plugins.NoCallbackError); !ok {
 			return version, err
 		}
 
-		allVersions, err := AllVersionsFiltered(plugin, query)
+		allVersions, err := AllVersionsFilteredContext(ctx, conf, plugin, query)
 		if err != nil {
 			return version, err
 		}
@@ -269,11 +289,17 @@ func Latest(plugin plugins.Plugin, query string) (version string, err error) {
 
 // AllVersions returns a slice of all available versions for the tool managed by
 // the given plugin by invoking the plugin's list-all callback
-func AllVersions(plugin plugins.Plugin) (versions []string, err error) {
+func AllVersions(conf config.Config, plugin plugins.Plugin) (versions []string, err error) {
+	return AllVersionsContext(context.Background(), conf, plugin)
+}
+
+// AllVersionsContext is like AllVersions but the list-all callback is killed
+// when ctx is done
+func AllVersionsContext(ctx context.Context, conf config.Config, plugin plugins.Plugin) (versions []string, err error) {
 	var stdout strings.Builder
 	var stderr strings.Builder
 
-	err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stderr)
+	err = plugin.RunCallbackContext(ctx, conf, "list-all", []string{}, map[string]string{}, &stdout, &stderr)
 	if err != nil {
 		return versions, err
 	}
@@ -285,8 +311,14 @@ func AllVersions(plugin plugins.Plugin) (versions []string, err error) {
 
 // AllVersionsFiltered returns a list of existing versions that match a regex
 // query provided by the user.
-func AllVersionsFiltered(plugin plugins.Plugin, query string) (versions []string, err error) {
-	all, err := AllVersions(plugin)
+func AllVersionsFiltered(conf config.Config, plugin plugins.Plugin, query string) (versions []string, err error) {
+	return AllVersionsFilteredContext(context.Background(), conf, plugin, query)
+}
+
+// AllVersionsFilteredContext is like AllVersionsFiltered but the list-all
+// callback is killed when ctx is done
+func AllVersionsFilteredContext(ctx context.Context, conf config.Config, plugin plugins.Plugin, query string) (versions []string, err error) {
+	all, err := AllVersionsContext(ctx, conf, plugin)
 	if err != nil {
 		return versions, err
 	}
@@ -320,7 +352,7 @@ func Uninstall(conf config.Config, plugin plugins.Plugin, rawVersion string, std
 		"ASDF_INSTALL_VERSION": version.Value,
 		"ASDF_INSTALL_PATH":    installDir,
 	}
-	err = plugin.RunCallback("uninstall", []string{}, env, stdout, stderr)
+	err = plugin.RunCallback(conf, "uninstall", []string{}, env, stdout, stderr)
 	if _, ok := err.(plugins.NoCallbackError); !ok && err != nil {
 		return err
 	}
@@ -182,6 +182,17 @@ func TestInstallOneVersion(t *testing.T) {
 		assert.IsType(t, UninstallableVersionError{}, err)
 	})
 
+	t.Run("returns error when plugin manifest does not support platform", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		manifest := []byte("os = [\"plan9\"]\n")
+		assert.Nil(t, os.WriteFile(filepath.Join(plugin.Dir, plugins.ManifestFilename), manifest, 0o666))
+		stdout, stderr := buildOutputs()
+
+		err := InstallOneVersion(conf, plugin, "1.0.0", false, &stdout, &stderr)
+		assert.IsType(t, plugins.UnsupportedPlatformError{}, err)
+		assert.NoDirExists(t, filepath.Join(conf.DataDir, "downloads", plugin.Name, "1.0.0"))
+	})
+
 	t.Run("returns error when version doesn't exist", func(t *testing.T) {
 		version := "other-dummy"
 		conf, plugin := generateConfig(t)
@@ -287,25 +298,25 @@ func TestLatest(t *testing.T) {
 		assert.Nil(t, err)
 		plugin := plugins.New(conf, pluginName)
 
-		version, err := Latest(plugin, "")
+		version, err := Latest(conf, plugin, "")
 		assert.Nil(t, err)
 		assert.Equal(t, "2.0.0", version)
 	})
 
 	t.Run("when given query matching no versions return empty slice of versions", func(t *testing.T) {
-		version, err := Latest(plugin, "impossible-to-satisfy-query")
+		version, err := Latest(conf, plugin, "impossible-to-satisfy-query")
 		assert.Error(t, err, "no latest version found")
 		assert.Equal(t, version, "")
 	})
 
 	t.Run("when given no query returns latest version of plugin", func(t *testing.T) {
-		version, err := Latest(plugin, "")
+		version, err := Latest(conf, plugin, "")
 		assert.Nil(t, err)
 		assert.Equal(t, "5.1.0", version)
 	})
 
 	t.Run("when given no query returns latest version of plugin", func(t *testing.T) {
-		version, err := Latest(plugin, "4")
+		version, err := Latest(conf, plugin, "4")
 		assert.Nil(t, err)
 		assert.Equal(t, "4.0.0", version)
 	})
@@ -319,7 +330,7 @@ func TestAllVersions(t *testing.T) {
 	plugin := plugins.New(conf, pluginName)
 
 	t.Run("returns slice of available versions from plugin", func(t *testing.T) {
-		versions, err := AllVersions(plugin)
+		versions, err := AllVersions(conf, plugin)
 		assert.Nil(t, err)
 		assert.Equal(t, versions, []string{"1.0.0", "1.1.0", "2.0.0"})
 	})
@@ -330,7 +341,7 @@ func TestAllVersions(t *testing.T) {
 		assert.Nil(t, err)
 		plugin := plugins.New(conf, pluginName)
 
-		versions, err := AllVersions(plugin)
+		versions, err := AllVersions(conf, plugin)
 		assert.Equal(t, err.(plugins.NoCallbackError).Error(), "Plugin named list-all-fail does not have a callback named list-all")
 		assert.Empty(t, versions)
 	})
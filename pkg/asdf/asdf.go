@@ -0,0 +1,301 @@
+// Package asdf is the public Go API of asdf. It lets other programs resolve,
+// install and use tool versions the same way the asdf command does, without
+// running the command and parsing its output.
+//
+// Functions return plain structs and errors. They never print to the terminal
+// or exit the program, output of plugin callbacks goes to the writers given in
+// Options.
+//
+//	client, err := asdf.New(asdf.Options{})
+//	if err != nil {
+//		return err
+//	}
+//
+//	toolVersions, err := client.Resolve(dir)
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/execenv"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/resolve"
+	"github.com/asdf-vm/asdf/internal/toolenv"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/versions"
+	"github.com/hashicorp/go-version"
+)
+
+// ErrNoSuchPlugin is returned when a tool has no plugin installed
+var ErrNoSuchPlugin = errors.New("no such plugin")
+
+// ErrNotInstalled is returned when a tool version that must be installed is
+// not
+var ErrNotInstalled = errors.New("version not installed")
+
+// Options configures a Client. The zero value configures the Client the same
+// way the asdf command is configured, from the ASDF_* environment variables
+// and the asdfrc file.
+type Options struct {
+	// DataDir overrides the asdf data directory when set
+	DataDir string
+	// ConfigFile overrides the path of the asdfrc file when set
+	ConfigFile string
+	// Stdout and Stderr receive the output of plugin callbacks and hooks. The
+	// output is discarded when they are nil.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Client gives access to the plugins and tool versions of an asdf data
+// directory
+type Client struct {
+	conf   config.Config
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Plugin is an installed plugin
+type Plugin struct {
+	Name string
+	Dir  string
+	URL  string
+	Ref  string
+}
+
+// ToolVersion is the version of a tool set for a directory
+type ToolVersion struct {
+	Name string
+	// Version is formatted as in a .tool-versions file, e.g. 1.2.3, ref:v1.2.3,
+	// path:/opt/tool or system
+	Version string
+	// Source is the version file, or the environment variable, setting the
+	// version
+	Source    string
+	Installed bool
+	// InstallPath is the directory the version is, or would be, installed in.
+	// It is empty for system and path versions.
+	InstallPath string
+}
+
+// OutdatedTool is a tool whose version set for a directory is older than the
+// latest available version
+type OutdatedTool struct {
+	Name    string
+	Current string
+	Latest  string
+}
+
+// New returns a Client configured with opts
+func New(opts Options) (*Client, error) {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load config: %w", err)
+	}
+
+	if opts.DataDir != "" {
+		conf.DataDir = opts.DataDir
+	}
+	if opts.ConfigFile != "" {
+		conf.ConfigFile = opts.ConfigFile
+	}
+
+	client := &Client{conf: conf, stdout: opts.Stdout, stderr: opts.Stderr}
+	if client.stdout == nil {
+		client.stdout = io.Discard
+	}
+	if client.stderr == nil {
+		client.stderr = io.Discard
+	}
+
+	return client, nil
+}
+
+// Plugins returns the installed plugins ordered by name
+func (c *Client) Plugins() ([]Plugin, error) {
+	installed, err := plugins.List(c.conf, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Plugin, 0, len(installed))
+	for _, plugin := range installed {
+		list = append(list, Plugin{Name: plugin.Name, Dir: plugin.Dir, URL: plugin.URL, Ref: plugin.Ref})
+	}
+
+	return list, nil
+}
+
+// Resolve returns the version set in dir for every installed plugin that has
+// one. When several versions are set for a tool the first installed one is
+// returned, or the first one if none is installed.
+func (c *Client) Resolve(dir string) ([]ToolVersion, error) {
+	installed, err := plugins.List(c.conf, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var toolVersions []ToolVersion
+	for _, plugin := range installed {
+		resolved, found, err := resolve.Version(c.conf, plugin, dir)
+		if err != nil {
+			return toolVersions, err
+		}
+
+		if found && len(resolved.Versions) > 0 {
+			toolVersions = append(toolVersions, c.toolVersion(plugin, resolved))
+		}
+	}
+
+	return toolVersions, nil
+}
+
+func (c *Client) toolVersion(plugin plugins.Plugin, resolved resolve.ToolVersions) ToolVersion {
+	source := resolved.Source
+	if resolved.Directory != "" {
+		source = filepath.Join(resolved.Directory, resolved.Source)
+	}
+
+	parsed := toolversions.ParseSlice(resolved.Versions)
+	version := parsed[0]
+	for _, candidate := range parsed {
+		if c.isInstalled(plugin, candidate) {
+			version = candidate
+			break
+		}
+	}
+
+	toolVersion := ToolVersion{
+		Name:      plugin.Name,
+		Version:   toolversions.Format(version),
+		Source:    source,
+		Installed: c.isInstalled(plugin, version),
+	}
+
+	if version.Type != "system" && version.Type != "path" {
+		toolVersion.InstallPath = installs.InstallPath(c.conf, plugin, version)
+	}
+
+	return toolVersion
+}
+
+func (c *Client) isInstalled(plugin plugins.Plugin, version toolversions.Version) bool {
+	return version.Type == "system" || installs.IsInstalled(c.conf, plugin, version)
+}
+
+// Install installs a version of tool and returns the installed version.
+// version may be latest or latest:<query> to install the latest version
+// matching the query. Installing a version that is already installed is not
+// an error.
+func (c *Client) Install(ctx context.Context, tool, version string) (string, error) {
+	plugin, err := c.plugin(tool)
+	if err != nil {
+		return "", err
+	}
+
+	parsed := toolversions.ParseFromCliArg(version)
+	if parsed.Type == "latest" {
+		latest, err := c.Latest(ctx, tool, parsed.Value)
+		if err != nil {
+			return "", err
+		}
+		parsed = toolversions.Parse(latest)
+	}
+
+	if c.isInstalled(plugin, parsed) {
+		return toolversions.Format(parsed), nil
+	}
+
+	err = versions.InstallOneVersionContext(ctx, c.conf, plugin, toolversions.Format(parsed), false, c.stdout, c.stderr)
+	return toolversions.Format(parsed), err
+}
+
+// ExecEnv returns the environment a version of tool runs in. It is the
+// environment of the current process with the executable directories of the
+// version prepended to PATH and the variables set by the plugin's exec-env
+// callback applied.
+func (c *Client) ExecEnv(tool, version string) (map[string]string, error) {
+	plugin, err := c.plugin(tool)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := toolversions.Parse(version)
+	if !c.isInstalled(plugin, parsed) {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotInstalled, tool, version)
+	}
+
+	toolVersion := toolenv.ToolVersion{Plugin: plugin, Version: parsed}
+	return toolenv.Build(c.conf, []toolenv.ToolVersion{toolVersion}, execenv.CurrentEnv(), c.stderr)
+}
+
+// Latest returns the latest stable version of tool, or of the versions
+// starting with query when it is not empty
+func (c *Client) Latest(ctx context.Context, tool, query string) (string, error) {
+	plugin, err := c.plugin(tool)
+	if err != nil {
+		return "", err
+	}
+
+	return versions.LatestContext(ctx, c.conf, plugin, query)
+}
+
+// Outdated returns the tools set in dir whose version is older than the latest
+// stable version. Only exact versions are compared, ref, path and system
+// versions are never outdated, nor are versions newer than the latest stable
+// one such as release candidates.
+func (c *Client) Outdated(ctx context.Context, dir string) ([]OutdatedTool, error) {
+	toolVersions, err := c.Resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated []OutdatedTool
+	for _, toolVersion := range toolVersions {
+		if toolversions.Parse(toolVersion.Version).Type != "version" {
+			continue
+		}
+
+		latest, err := c.Latest(ctx, toolVersion.Name, "")
+		if err != nil {
+			return outdated, err
+		}
+
+		if older(toolVersion.Version, latest) {
+			outdated = append(outdated, OutdatedTool{Name: toolVersion.Name, Current: toolVersion.Version, Latest: latest})
+		}
+	}
+
+	return outdated, nil
+}
+
+// older reports whether current is an older version than latest. Versions
+// that can't be ordered are older when they differ.
+func older(current, latest string) bool {
+	currentVersion, err := version.NewVersion(current)
+	if err != nil {
+		return current != latest
+	}
+
+	latestVersion, err := version.NewVersion(latest)
+	if err != nil {
+		return current != latest
+	}
+
+	return currentVersion.LessThan(latestVersion)
+}
+
+func (c *Client) plugin(tool string) (plugins.Plugin, error) {
+	plugin := plugins.New(c.conf, tool)
+	if err := plugin.Exists(); err != nil {
+		return plugin, fmt.Errorf("%w: %s", ErrNoSuchPlugin, tool)
+	}
+
+	return plugin, nil
+}
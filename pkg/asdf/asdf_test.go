@@ -0,0 +1,185 @@
+package asdf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/installtest"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginName = "lua"
+
+func TestPlugins(t *testing.T) {
+	client, _ := generateClient(t)
+
+	list, err := client.Plugins()
+	assert.Nil(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, testPluginName, list[0].Name)
+	assert.NotEmpty(t, list[0].URL)
+	assert.NotEmpty(t, list[0].Ref)
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("returns installed version and its source", func(t *testing.T) {
+		client, plugin := generateClient(t)
+		assert.Nil(t, installtest.InstallOneVersion(client.conf, plugin, "version", "1.0.0"))
+		dir := writeToolVersions(t, "lua 2.0.0 1.0.0\n")
+
+		toolVersions, err := client.Resolve(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, []ToolVersion{{
+			Name:        testPluginName,
+			Version:     "1.0.0",
+			Source:      filepath.Join(dir, ".tool-versions"),
+			Installed:   true,
+			InstallPath: filepath.Join(client.conf.DataDir, "installs", testPluginName, "1.0.0"),
+		}}, toolVersions)
+	})
+
+	t.Run("returns first version when none is installed", func(t *testing.T) {
+		client, _ := generateClient(t)
+		dir := writeToolVersions(t, "lua 2.0.0 1.0.0\n")
+
+		toolVersions, err := client.Resolve(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, "2.0.0", toolVersions[0].Version)
+		assert.False(t, toolVersions[0].Installed)
+	})
+
+	t.Run("returns nothing when no version is set", func(t *testing.T) {
+		client, _ := generateClient(t)
+
+		toolVersions, err := client.Resolve(t.TempDir())
+		assert.Nil(t, err)
+		assert.Empty(t, toolVersions)
+	})
+}
+
+func TestInstall(t *testing.T) {
+	t.Run("installs version", func(t *testing.T) {
+		client, _ := generateClient(t)
+
+		version, err := client.Install(context.Background(), testPluginName, "1.0.0")
+		assert.Nil(t, err)
+		assert.Equal(t, "1.0.0", version)
+		assert.DirExists(t, filepath.Join(client.conf.DataDir, "installs", testPluginName, "1.0.0"))
+	})
+
+	t.Run("installs latest version", func(t *testing.T) {
+		client, _ := generateClient(t)
+
+		version, err := client.Install(context.Background(), testPluginName, "latest")
+		assert.Nil(t, err)
+		assert.Equal(t, "2.0.0", version)
+	})
+
+	t.Run("does not return error when version is already installed", func(t *testing.T) {
+		client, plugin := generateClient(t)
+		assert.Nil(t, installtest.InstallOneVersion(client.conf, plugin, "version", "1.0.0"))
+
+		_, err := client.Install(context.Background(), testPluginName, "1.0.0")
+		assert.Nil(t, err)
+	})
+
+	t.Run("returns error when context is canceled", func(t *testing.T) {
+		client, _ := generateClient(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.Install(ctx, testPluginName, "1.0.0")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("returns error when plugin does not exist", func(t *testing.T) {
+		client, _ := generateClient(t)
+
+		_, err := client.Install(context.Background(), "non-existent", "1.0.0")
+		assert.ErrorIs(t, err, ErrNoSuchPlugin)
+	})
+}
+
+func TestExecEnv(t *testing.T) {
+	t.Run("returns environment with version on PATH", func(t *testing.T) {
+		client, plugin := generateClient(t)
+		assert.Nil(t, installtest.InstallOneVersion(client.conf, plugin, "version", "1.0.0"))
+
+		env, err := client.ExecEnv(testPluginName, "1.0.0")
+		assert.Nil(t, err)
+		assert.Contains(t, env["PATH"], filepath.Join(client.conf.DataDir, "installs", testPluginName, "1.0.0", "bin"))
+	})
+
+	t.Run("writes output of exec-env callback to Stderr", func(t *testing.T) {
+		client, plugin := generateClient(t)
+		assert.Nil(t, installtest.InstallOneVersion(client.conf, plugin, "version", "1.0.0"))
+		assert.Nil(t, repotest.WritePluginCallback(plugin.Dir, "exec-env", "#!/usr/bin/env bash\necho setting up lua\nexport LUA_INIT=1"))
+		var stderr strings.Builder
+		client.stderr = &stderr
+
+		env, err := client.ExecEnv(testPluginName, "1.0.0")
+		assert.Nil(t, err)
+		assert.Equal(t, "1", env["LUA_INIT"])
+		assert.Equal(t, "setting up lua\n", stderr.String())
+	})
+
+	t.Run("returns error when version is not installed", func(t *testing.T) {
+		client, _ := generateClient(t)
+
+		_, err := client.ExecEnv(testPluginName, "1.0.0")
+		assert.ErrorIs(t, err, ErrNotInstalled)
+	})
+}
+
+func TestOutdated(t *testing.T) {
+	client, plugin := generateClient(t)
+	assert.Nil(t, installtest.InstallOneVersion(client.conf, plugin, "version", "1.0.0"))
+	dir := writeToolVersions(t, "lua 1.0.0\n")
+
+	outdated, err := client.Outdated(context.Background(), dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []OutdatedTool{{Name: testPluginName, Current: "1.0.0", Latest: "2.0.0"}}, outdated)
+
+	t.Run("does not return versions newer than latest", func(t *testing.T) {
+		assert.Nil(t, installtest.InstallOneVersion(client.conf, plugin, "version", "2.1.0-rc1"))
+		dir := writeToolVersions(t, "lua 2.1.0-rc1\n")
+
+		outdated, err := client.Outdated(context.Background(), dir)
+		assert.Nil(t, err)
+		assert.Empty(t, outdated)
+	})
+}
+
+func TestOlder(t *testing.T) {
+	assert.True(t, older("1.9.0", "1.10.0"))
+	assert.False(t, older("1.10.0", "1.9.0"))
+	assert.False(t, older("2.0.0", "2.0"))
+	assert.True(t, older("lts-hydrogen", "lts-iron"))
+	assert.False(t, older("lts-iron", "lts-iron"))
+}
+
+func generateClient(t *testing.T) (*Client, plugins.Plugin) {
+	t.Helper()
+	testDataDir := t.TempDir()
+
+	_, err := repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	client, err := New(Options{DataDir: testDataDir, ConfigFile: "non-existent"})
+	assert.Nil(t, err)
+
+	return client, plugins.New(config.Config{DataDir: testDataDir}, testPluginName)
+}
+
+func writeToolVersions(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, ".tool-versions"), []byte(content), 0o666))
+	return dir
+}
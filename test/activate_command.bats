@@ -0,0 +1,47 @@
+#!/usr/bin/env bats
+# shellcheck disable=SC2164
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  install_dummy_plugin
+
+  PROJECT_DIR="$HOME/project"
+  mkdir -p "$PROJECT_DIR"
+  cd "$PROJECT_DIR"
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "asdf activate should print hook for shell" {
+  run asdf activate bash
+  [ "$status" -eq 0 ]
+  echo "$output" | grep "asdf hook-env bash"
+}
+
+@test "asdf activate should fail for unknown shell" {
+  run asdf activate foo
+  [ "$status" -eq 1 ]
+  echo "$output" | grep 'No activation available for shell with name "foo"'
+}
+
+@test "asdf hook-env should put install directory on PATH" {
+  echo "dummy 1.0" >"$PROJECT_DIR/.tool-versions"
+  run asdf install
+
+  run bash -c 'eval "$(asdf hook-env bash)" && command -v dummy'
+  [ "$status" -eq 0 ]
+  [ "$output" = "$ASDF_DIR/installs/dummy/1.0/bin/dummy" ]
+}
+
+@test "asdf hook-env should print nothing when called again without changes" {
+  echo "dummy 1.0" >"$PROJECT_DIR/.tool-versions"
+  run asdf install
+
+  run bash -c 'eval "$(asdf hook-env bash)" && asdf hook-env bash'
+  [ "$status" -eq 0 ]
+  [ "$output" = "" ]
+}
@@ -87,6 +87,15 @@ dummy 1.2.0 $PROJECT_DIR/.dummy-version true"
   [ "$output" = "$expected" ]
 }
 
+@test "current should suggest close plugin names when the plugin doesn't exist" {
+  expected="No such plugin: dumy
+did you mean dummy?"
+
+  run asdf current "dumy"
+  [ "$status" -eq 1 ]
+  [ "$output" = "$expected" ]
+}
+
 @test "current should error when no version is set" {
   cd "$PROJECT_DIR"
   expected="Name Version Source Installed
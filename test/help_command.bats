@@ -79,3 +79,13 @@ EOF
   [[ $output == *$'UTILS\n'* ]]
   [[ $output == *$'"Late but latest"\n-- Rajinikanth' ]]
 }
+
+@test "asdf should show help and exit 127 for unknown command" {
+  cd "$PROJECT_DIR"
+
+  run asdf non-existent-command
+
+  [ "$status" -eq 127 ]
+  [[ $output == 'Unknown command: `asdf non-existent-command`'* ]]
+  [[ $output == *$'MANAGE PLUGINS\n'* ]]
+}
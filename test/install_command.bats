@@ -153,6 +153,12 @@ EOM
   [ ! -f "$ASDF_DIR/installs/dummy/1.1.0/version" ]
 }
 
+@test "install_command suggests close plugin names when the plugin is not installed" {
+  run asdf install dumy 1.0.0
+  [ "$status" -eq 1 ]
+  echo "$output" | grep "did you mean dummy"
+}
+
 # `asdf install` now enumerates installed plugins, so if a plugin defined in a
 # .tool-versions file is not installed `asdf install` now skips it.
 #@test "install_command fails if the plugin is not installed" {
@@ -36,6 +36,16 @@ teardown() {
   [ "$status" -eq 0 ]
 }
 
+@test "list_command explains why it fails for untrusted path version" {
+  cd "$PROJECT_DIR"
+  echo "dummy path:$PROJECT_DIR" >"$PROJECT_DIR/.tool-versions"
+  run asdf install dummy 1.0.0
+
+  run asdf list dummy
+  [ "$status" -ne 0 ]
+  [[ "$output" == *"$PROJECT_DIR/.tool-versions is not trusted"* ]]
+}
+
 @test "list_command should continue listing even when no version is installed for any of the plugins" {
   run install_mock_plugin "dummy"
   run install_mock_plugin "mummy"
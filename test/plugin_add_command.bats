@@ -112,6 +112,12 @@ teardown() {
   echo "$output" | grep "plugin does-not-exist not found in repository"
 }
 
+@test "plugin_add command with no URL specified suggests close plugin names" {
+  run asdf plugin add "dumy"
+  [ "$status" -eq 1 ]
+  echo "$output" | grep "plugin dumy not found in repository, did you mean dummy?"
+}
+
 @test "plugin_add command executes post-plugin add script" {
   install_mock_plugin_repo "dummy"
 
@@ -174,3 +180,68 @@ EOM
 ADD"
   [ "$output" = "${expected_output}" ]
 }
+
+@test "plugin_add command with local directory copies the plugin" {
+  cp -r "$BATS_TEST_DIRNAME/fixtures/dummy_plugin" "$BASE_DIR/local-dummy"
+
+  run asdf plugin add "dummy" "$BASE_DIR/local-dummy"
+  [ "$status" -eq 0 ]
+  [ -f "$ASDF_DIR/plugins/dummy/bin/list-all" ]
+  [ ! -L "$ASDF_DIR/plugins/dummy" ]
+
+  run asdf list all dummy
+  [ "$status" -eq 0 ]
+}
+
+@test "plugin_add command with tarball extracts the plugin" {
+  cp -r "$BATS_TEST_DIRNAME/fixtures/dummy_plugin" "$BASE_DIR/asdf-dummy-main"
+  tar -czf "$BASE_DIR/dummy.tar.gz" -C "$BASE_DIR" asdf-dummy-main
+
+  run asdf plugin add "dummy" "file://$BASE_DIR/dummy.tar.gz"
+  [ "$status" -eq 0 ]
+  [ -x "$ASDF_DIR/plugins/dummy/bin/list-all" ]
+}
+
+@test "plugin_link command links plugin to working copy" {
+  cp -r "$BATS_TEST_DIRNAME/fixtures/dummy_plugin" "$BASE_DIR/work-dummy"
+
+  run asdf plugin link "dummy" "$BASE_DIR/work-dummy"
+  [ "$status" -eq 0 ]
+  [ -L "$ASDF_DIR/plugins/dummy" ]
+
+  run asdf plugin list
+  [ "$output" = "dummy" ]
+
+  run asdf plugin update dummy
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"plugin dummy is linked to a directory"* ]]
+
+  run asdf plugin remove dummy
+  [ "$status" -eq 0 ]
+  [ -f "$BASE_DIR/work-dummy/bin/list-all" ]
+}
+
+@test "plugin_link command fails when directory does not exist" {
+  run asdf plugin link "dummy" "$BASE_DIR/missing"
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"is not a directory"* ]]
+}
+
+@test "plugin_add command with --ref checks out tag" {
+  install_mock_plugin_repo "dummy"
+  git -C "$BASE_DIR/repo-dummy" tag v1.0.0
+  expected="$(git -C "$BASE_DIR/repo-dummy" rev-parse v1.0.0)"
+
+  run asdf plugin add --ref v1.0.0 "dummy" "$BASE_DIR/repo-dummy"
+  [ "$status" -eq 0 ]
+  [ "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)" = "$expected" ]
+}
+
+@test "plugin_add command with --ref that does not exist fails" {
+  install_mock_plugin_repo "dummy"
+
+  run asdf plugin add --ref missing "dummy" "$BASE_DIR/repo-dummy"
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"reference not found"* ]]
+  [ ! -d "$ASDF_DIR/plugins/dummy" ]
+}
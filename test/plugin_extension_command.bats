@@ -29,6 +29,20 @@ teardown() {
   echo "$output" | grep "asdf dummy foo-bar"
 }
 
+@test "asdf help shows extension command descriptions from plugin.toml" {
+  local plugin_path
+  plugin_path="$(get_plugin_path dummy)"
+  touch "$plugin_path/lib/commands/command-foo"
+  cat <<'EOF' >"$plugin_path/plugin.toml"
+[commands]
+foo = "Run foo"
+EOF
+
+  run asdf help
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"  asdf dummy foo"*" Run foo"* ]]
+}
+
 @test "asdf help shows extension commands for plugin with hyphens in the name" {
   cd "$PROJECT_DIR"
 
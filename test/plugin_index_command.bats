@@ -0,0 +1,95 @@
+#!/usr/bin/env bats
+# shellcheck disable=SC2030,SC2031
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  setup_repo
+  export ASDF_CONFIG_DEFAULT_FILE="$HOME/.asdfrc"
+  echo 'plugin_repository_last_check_duration = never' >"$ASDF_CONFIG_DEFAULT_FILE"
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+add_index_plugin() {
+  echo "repository = http://example.com/$1" >"$ASDF_DIR/plugin-index-2/plugins/$1"
+  git -C "$ASDF_DIR/plugin-index-2" add -A
+  git -C "$ASDF_DIR/plugin-index-2" commit -q -m "add $1"
+}
+
+@test "plugin_index status shows the commit of the index" {
+  local commit
+  commit="$(git -C "$ASDF_DIR/plugin-index" rev-parse --short=7 HEAD)"
+
+  run asdf plugin index status
+  [ "$status" -eq 0 ]
+  [ "$(echo "$output" | head -n 1)" = "INDEX     URL                                          COMMIT    UPDATED" ]
+  echo "$output" | grep "^default   https://github.com/asdf-vm/asdf-plugins.git  $commit"
+}
+
+@test "plugin_index update updates the index even when it is never synced" {
+  add_index_plugin "lua"
+
+  run asdf plugin index update
+  [ "$status" -eq 0 ]
+  [ "$output" = "Updated plugin index default" ]
+
+  run asdf plugin list all
+  echo "$output" | grep "^lua .*http://example.com/lua$"
+}
+
+@test "plugin_index update fails for an index not in plugin_indexes" {
+  run asdf plugin index update company
+  [ "$status" -eq 1 ]
+  [ "$output" = "No plugin index named company in plugin_indexes" ]
+}
+
+@test "plugin_index pin keeps the index at a commit until unpinned" {
+  local commit
+  commit="$(git -C "$ASDF_DIR/plugin-index" rev-parse HEAD)"
+
+  run asdf plugin index pin "${commit:0:7}"
+  [ "$status" -eq 0 ]
+  [ "$output" = "Pinned plugin index default to $commit" ]
+
+  add_index_plugin "lua"
+  run asdf plugin index update
+  [ "$status" -eq 0 ]
+
+  run asdf plugin index status
+  echo "$output" | grep "${commit:0:7} (pinned)"
+  run asdf plugin list all
+  [[ "$output" != *"lua"* ]]
+
+  run asdf plugin index unpin
+  [ "$status" -eq 0 ]
+  [ "$output" = "Unpinned plugin index default" ]
+
+  run asdf plugin list all
+  echo "$output" | grep "^lua .*http://example.com/lua$"
+}
+
+@test "plugin_index pin fails without a commit" {
+  run asdf plugin index pin
+  [ "$status" -eq 1 ]
+  [ "$output" = "usage: asdf plugin index pin [--index <name>] <commit>" ]
+}
+
+@test "plugin_index unpin fails when the index is not pinned" {
+  run asdf plugin index unpin
+  [ "$status" -eq 1 ]
+  [ "$output" = "plugin index default is not pinned" ]
+}
+
+@test "plugin list all uses the index on disk with a warning when it can't be synced" {
+  echo 'plugin_repository_last_check_duration = 0' >"$ASDF_CONFIG_DEFAULT_FILE"
+  git -C "$ASDF_DIR/plugin-index" remote set-url origin "$ASDF_DIR/does-not-exist"
+
+  run asdf plugin list all
+  [ "$status" -eq 0 ]
+  echo "$output" | grep "warning: using plugin index default as last synced"
+  echo "$output" | grep "^dummy .*http://example.com/dummy$"
+}
@@ -0,0 +1,77 @@
+#!/usr/bin/env bats
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  install_dummy_plugin
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "plugin info shows callbacks, legacy filenames and installed versions" {
+  run asdf install dummy 1.0
+  [ "$status" -eq 0 ]
+
+  run asdf plugin info dummy
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"name                dummy"* ]]
+  [[ "$output" == *"callbacks           download, install, list-all"* ]]
+  [[ "$output" == *"missing callbacks   "*"exec-env"* ]]
+  [[ "$output" == *"legacy filenames    .dummy-version, .dummyrc"* ]]
+  [[ "$output" == *$'versions\n  1.0'* ]]
+}
+
+@test "plugin info shows extension commands" {
+  mkdir -p "$(get_plugin_path dummy)/lib/commands"
+  touch "$(get_plugin_path dummy)/lib/commands/command-foo"
+
+  run asdf plugin info dummy
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"extension commands  asdf dummy foo"* ]]
+}
+
+@test "plugin info shows metadata from plugin.toml" {
+  cat <<'EOF2' >"$(get_plugin_path dummy)/plugin.toml"
+name = "dummy"
+description = "A dummy tool"
+license = "MIT"
+
+[commands]
+foo = "Run foo"
+EOF2
+
+  run asdf plugin info dummy
+  [ "$status" -eq 0 ]
+  [[ "$output" == *$'plugin.toml\n'* ]]
+  [[ "$output" == *"description         A dummy tool"* ]]
+  [[ "$output" == *"license             MIT"* ]]
+  [[ "$output" == *"asdf dummy foo"*"Run foo"* ]]
+}
+
+@test "plugin info --json prints plugin info as JSON" {
+  run asdf install dummy 1.0
+  [ "$status" -eq 0 ]
+
+  run asdf plugin info --json dummy
+  [ "$status" -eq 0 ]
+  [[ "$output" == *'"name": "dummy"'* ]]
+  [[ "$output" == *'"version": "1.0"'* ]]
+  [[ "$output" == *'"missing_callbacks": ['* ]]
+}
+
+@test "plugin info fails for plugin that is not installed" {
+  run asdf plugin info non-existent
+  [ "$status" -eq 1 ]
+  [ "$output" = "Plugin named non-existent not installed" ]
+}
+
+@test "plugin info fails for invalid manifest" {
+  echo "name = " >"$(get_plugin_path dummy)/plugin.toml"
+
+  run asdf plugin info dummy
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"invalid plugin.toml of plugin dummy"* ]]
+}
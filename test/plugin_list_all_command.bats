@@ -72,3 +72,12 @@ foo                           http://example.com/foo"
   [ "$status" -eq 0 ]
   [ "$output" = "$expected" ]
 }
+
+@test "plugin_list_all shows which index each plugin is from when there are several indexes" {
+  echo "plugin_indexes = mirror=$ASDF_DIR/plugin-index-2, default" >"$HOME/.asdfrc"
+
+  run asdf plugin list all
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"foo"*"http://example.com/foo"*"mirror"* ]]
+  [ -d "$ASDF_DIR/plugin-indexes/mirror/plugins" ]
+}
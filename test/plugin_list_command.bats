@@ -0,0 +1,49 @@
+#!/usr/bin/env bats
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  install_mock_plugin_repo "dummy"
+  run asdf plugin add "dummy" "${BASE_DIR}/repo-dummy"
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "asdf plugin list --outdated reports plugins that are up to date" {
+  run asdf plugin list --outdated
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"dummy"*"master"*"up to date"* ]]
+}
+
+@test "asdf plugin list --outdated reports commits behind the remote and local modifications" {
+  touch "$BASE_DIR/repo-dummy/new-file"
+  git -C "$BASE_DIR/repo-dummy" add -A
+  git -C "$BASE_DIR/repo-dummy" commit -q -m "add new file"
+  echo "# modified" >>"$ASDF_DIR/plugins/dummy/bin/list-all"
+
+  run asdf plugin list --outdated
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"dummy"*"master"*"1 behind, 0 ahead, modified"* ]]
+
+  # The plugin is not updated
+  [ "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)" != "$(git -C "$BASE_DIR/repo-dummy" rev-parse HEAD)" ]
+}
+
+@test "asdf plugin list --outdated reports detached HEAD" {
+  git -C "$ASDF_DIR/plugins/dummy" checkout -q --detach
+
+  run asdf plugin list --outdated
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"dummy"*"(detached)"* ]]
+}
+
+@test "asdf plugin list --outdated --json prints statuses as JSON" {
+  run asdf plugin list --outdated --json
+  [ "$status" -eq 0 ]
+  [[ "$output" == *'"name": "dummy"'* ]]
+  [[ "$output" == *'"branch": "master"'* ]]
+  [[ "$output" == *'"behind": 0'* ]]
+}
@@ -0,0 +1,94 @@
+#!/usr/bin/env bats
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  install_mock_plugin_repo "dummy"
+  git -C "$BASE_DIR/repo-dummy" tag v1.0.0
+
+  PROJECT_DIR="$HOME/project"
+  mkdir -p "$PROJECT_DIR"
+  cd "$PROJECT_DIR" || exit
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "plugin_lock command writes plugin URL and commit to .plugin-versions" {
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+  [ "$status" -eq 0 ]
+  ref="$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)"
+
+  run asdf plugin lock
+  [ "$status" -eq 0 ]
+  grep -q "^dummy $BASE_DIR/repo-dummy $ref\$" "$PROJECT_DIR/.plugin-versions"
+}
+
+@test "plugin_lock command trusts the file it writes" {
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+
+  run asdf plugin lock
+  [ "$status" -eq 0 ]
+  rm -rf "$ASDF_DIR/plugins/dummy"
+
+  echo "dummy 1.0.0" >"$PROJECT_DIR/.tool-versions"
+  run asdf install
+  [ "$status" -eq 0 ]
+  [ -d "$ASDF_DIR/plugins/dummy" ]
+}
+
+@test "install command skips plugins pinned in untrusted .plugin-versions" {
+  echo "dummy $BASE_DIR/repo-dummy v1.0.0" >"$PROJECT_DIR/.plugin-versions"
+  echo "dummy 1.0.0" >"$PROJECT_DIR/.tool-versions"
+
+  run asdf install
+  [[ "$output" == *"not syncing plugins pinned in $PROJECT_DIR/.plugin-versions"* ]]
+  [ ! -d "$ASDF_DIR/plugins/dummy" ]
+}
+
+@test "install command installs tools of installed plugins when .plugin-versions is untrusted" {
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+  echo "dummy $BASE_DIR/repo-dummy" >"$PROJECT_DIR/.plugin-versions"
+  echo "other $BASE_DIR/repo-dummy" >>"$PROJECT_DIR/.plugin-versions"
+  echo "dummy 1.0.0" >"$PROJECT_DIR/.tool-versions"
+
+  run asdf install dummy
+  [ "$status" -eq 0 ]
+  [ -d "$ASDF_DIR/installs/dummy/1.0.0" ]
+}
+
+@test "plugin_lock command fails for plugin that is not installed" {
+  run asdf plugin lock dummy
+  [ "$status" -eq 1 ]
+  [ ! -f "$PROJECT_DIR/.plugin-versions" ]
+}
+
+@test "install command adds plugins pinned in .plugin-versions" {
+  echo "dummy $BASE_DIR/repo-dummy v1.0.0" >"$PROJECT_DIR/.plugin-versions"
+  asdf trust "$PROJECT_DIR/.plugin-versions"
+  echo "dummy 1.0.0" >"$PROJECT_DIR/.tool-versions"
+
+  run asdf install
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"added plugin dummy"* ]]
+  [ -f "$ASDF_DIR/installs/dummy/1.0.0/version" ]
+}
+
+@test "install command warns when plugin is at another ref than .plugin-versions" {
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+  touch "$BASE_DIR/repo-dummy/new-file"
+  git -C "$BASE_DIR/repo-dummy" add -A
+  git -C "$BASE_DIR/repo-dummy" commit -q -m "new commit"
+  run asdf plugin update dummy
+
+  echo "dummy $BASE_DIR/repo-dummy v1.0.0" >"$PROJECT_DIR/.plugin-versions"
+  asdf trust "$PROJECT_DIR/.plugin-versions"
+  run asdf install
+  [[ "$output" == *"plugin dummy is at"*"pins v1.0.0"* ]]
+
+  run asdf install --update-plugins
+  [[ "$output" == *"updated plugin dummy to v1.0.0"* ]]
+  [ "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)" = "$(git -C "$BASE_DIR/repo-dummy" rev-parse v1.0.0)" ]
+}
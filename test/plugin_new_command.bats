@@ -0,0 +1,55 @@
+#!/usr/bin/env bats
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "plugin_new_command without a repository prints usage" {
+  run asdf plugin new mytool
+  [ "$status" -eq 1 ]
+  [ "$output" = "usage: asdf plugin new [--strategy <strategy>] --repository <owner>/<name> <name> [<dir>]" ]
+}
+
+@test "plugin_new_command with an invalid strategy prints an error" {
+  run asdf plugin new --strategy homebrew --repository example/mytool mytool "$BASE_DIR/asdf-mytool"
+  [ "$status" -eq 1 ]
+  [[ "$output" == *'invalid strategy "homebrew"'* ]]
+}
+
+@test "plugin_new_command refuses to write into a directory that is not empty" {
+  mkdir -p "$BASE_DIR/asdf-mytool"
+  touch "$BASE_DIR/asdf-mytool/README.md"
+
+  run asdf plugin new --repository example/mytool mytool "$BASE_DIR/asdf-mytool"
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"already exists and is not empty"* ]]
+}
+
+@test "plugin_new_command writes a plugin that passes plugin test" {
+  run asdf plugin new --repository example/mytool mytool "$BASE_DIR/asdf-mytool"
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"bin/download"* ]]
+  [[ "$output" == *"Created plugin mytool in $BASE_DIR/asdf-mytool"* ]]
+  [ -x "$BASE_DIR/asdf-mytool/bin/list-all" ]
+  init_git_repo "$BASE_DIR/asdf-mytool"
+
+  # a local stand in for the GitHub repository of the tool, with a release
+  local platform
+  platform="$(uname -s | tr '[:upper:]' '[:lower:]')-$(uname -m | sed -e s/x86_64/amd64/ -e s/aarch64/arm64/)"
+  local upstream="$BASE_DIR/upstream"
+  mkdir -p "$upstream/mytool-1.0.0/bin" "$upstream/releases/download/v1.0.0"
+  printf '#!/usr/bin/env bash\necho mytool 1.0.0\n' >"$upstream/mytool-1.0.0/bin/mytool"
+  chmod +x "$upstream/mytool-1.0.0/bin/mytool"
+  tar -czf "$upstream/releases/download/v1.0.0/mytool-1.0.0-$platform.tar.gz" -C "$upstream" mytool-1.0.0
+  init_git_repo "$upstream"
+  git -C "$upstream" tag v1.0.0
+
+  ASDF_MYTOOL_REPOSITORY="file://$upstream" run asdf plugin test --asdf-tool-version 1.0.0 mytool "$BASE_DIR/asdf-mytool" mytool
+  [ "$status" -eq 0 ]
+}
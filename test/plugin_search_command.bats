@@ -0,0 +1,50 @@
+#!/usr/bin/env bats
+# shellcheck disable=SC2030,SC2031
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  setup_repo
+  install_dummy_plugin
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "plugin_search lists plugins whose names match the term" {
+  run asdf plugin search dum
+  [ "$status" -eq 0 ]
+  [ "$output" = "dummy                         http://example.com/dummy" ]
+}
+
+@test "plugin_search matches mistyped names" {
+  run asdf plugin search fo0
+  [ "$status" -eq 0 ]
+  [ "$output" = "foo                           http://example.com/foo" ]
+}
+
+@test "plugin_search matches descriptions and keywords" {
+  printf 'repository = http://example.com/bar\ndescription = The Bar language\nkeywords = scripting\n' >"$ASDF_DIR/plugin-index/plugins/bar"
+
+  run asdf plugin search scripting
+  [ "$status" -eq 0 ]
+  [ "$output" = "bar                           http://example.com/bar The Bar language" ]
+
+  run asdf plugin search language
+  [ "$status" -eq 0 ]
+  [ "$output" = "bar                           http://example.com/bar The Bar language" ]
+}
+
+@test "plugin_search fails when no plugin matches" {
+  run asdf plugin search python
+  [ "$status" -eq 1 ]
+  [ "$output" = "No plugins match python" ]
+}
+
+@test "plugin_search fails without a term" {
+  run asdf plugin search
+  [ "$status" -eq 1 ]
+  [ "$output" = "usage: asdf plugin search <term>" ]
+}
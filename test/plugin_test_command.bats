@@ -32,3 +32,39 @@ teardown() {
   run asdf plugin test dummy "${BASE_DIR}/repo-dummy" --asdf-tool-version 1.0.0 --asdf-plugin-gitref master
   [ "$status" -eq 0 ]
 }
+
+@test "plugin_test_command runs the command after -- with each version given" {
+  run asdf plugin test --asdf-tool-version 1.0.0 --asdf-tool-version 2.0.0 dummy "${BASE_DIR}/repo-dummy" -- dummy --version
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"This is Dummy 1.0.0! --version"* ]]
+  [[ "$output" == *"This is Dummy 2.0.0! --version"* ]]
+  [[ "$output" == *"uninstall 2.0.0"*"passed"* ]]
+  [[ "$output" == *"16 passed, 0 failed, 0 skipped"* ]]
+}
+
+@test "plugin_test_command reports every failed check" {
+  rm "${BASE_DIR}/repo-dummy/LICENSE"
+  git -C "${BASE_DIR}/repo-dummy" commit -q -a -m "remove license"
+
+  run asdf plugin test dummy "${BASE_DIR}/repo-dummy" false
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"license"*"failed: LICENSE file must be present in the plugin repository"* ]]
+  [[ "$output" == *"command 1.0.0"*"failed: false exited with an error"* ]]
+  [[ "$output" == *"uninstall 1.0.0"*"passed"* ]]
+  [[ "$output" == *"FAILED: 2 of 12 checks failed"* ]]
+}
+
+@test "plugin_test_command writes a JUnit report" {
+  run asdf plugin test --junit "${BASE_DIR}/report.xml" dummy "${BASE_DIR}/repo-dummy"
+  [ "$status" -eq 0 ]
+  run cat "${BASE_DIR}/report.xml"
+  [[ "$output" == *'<testsuite name="dummy" tests="12" failures="0" skipped="2"'* ]]
+  [[ "$output" == *'<testcase name="install 1.0.0" classname="asdf.plugin.test.dummy"'* ]]
+}
+
+@test "plugin_test_command removes the plugin and its shims afterwards" {
+  run asdf plugin test dummy "${BASE_DIR}/repo-dummy"
+  [ "$status" -eq 0 ]
+  [ ! -d "$ASDF_DATA_DIR/plugins/asdf-test-dummy" ]
+  [ ! -f "$ASDF_DATA_DIR/shims/dummy" ]
+}
@@ -113,6 +113,84 @@ teardown() {
   [ -f "$ASDF_DIR/shims/dummy" ]
 }
 
+@test "asdf plugin update --all prints a result table" {
+  run asdf plugin update --all
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"PLUGIN"*"OLD REF"*"NEW REF"*"RESULT"* ]]
+  [[ "$output" == *"dummy"*"up to date"* ]]
+}
+
+@test "asdf plugin update rolls back when post-plugin-update fails" {
+  old_ref="$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)"
+  printf '#!/usr/bin/env bash\nexit 1\n' >"$BASE_DIR/repo-dummy/bin/post-plugin-update"
+  git -C "$BASE_DIR/repo-dummy" commit -q -a -m "break post-plugin-update"
+
+  run asdf plugin update dummy
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"rolled back plugin dummy to $old_ref"* ]]
+  [ "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)" = "$old_ref" ]
+}
+
+@test "asdf plugin rollback returns plugin to ref before update" {
+  old_ref="$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)"
+  touch "$BASE_DIR/repo-dummy/new-file"
+  git -C "$BASE_DIR/repo-dummy" add -A
+  git -C "$BASE_DIR/repo-dummy" commit -q -m "new commit"
+  run asdf plugin update dummy
+  [ "$status" -eq 0 ]
+  [ "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)" != "$old_ref" ]
+
+  run asdf plugin rollback dummy
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"rolled back dummy to ref $old_ref"* ]]
+  [ "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)" = "$old_ref" ]
+  [ "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse --abbrev-ref HEAD)" = "master" ]
+}
+
+@test "asdf plugin rollback fails when plugin was never updated" {
+  run asdf plugin rollback dummy
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"has no previous ref to roll back to"* ]]
+}
+
+@test "asdf plugin update --show-log prints commits the plugin was updated by" {
+  touch "$BASE_DIR/repo-dummy/new-file"
+  git -C "$BASE_DIR/repo-dummy" add -A
+  git -C "$BASE_DIR/repo-dummy" commit -q -m "add new file"
+
+  run asdf plugin update --show-log dummy
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"add new file (Test)"* ]]
+
+  run asdf plugin log dummy
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"add new file (Test)"* ]]
+  [ "${#lines[@]}" -eq 1 ]
+}
+
+@test "asdf plugin update --show-log prints nothing when plugin is already up to date" {
+  touch "$BASE_DIR/repo-dummy/new-file"
+  git -C "$BASE_DIR/repo-dummy" add -A
+  git -C "$BASE_DIR/repo-dummy" commit -q -m "add new file"
+  run asdf plugin update dummy
+
+  run asdf plugin update --show-log dummy
+  [ "$status" -eq 0 ]
+  [[ "$output" != *"add new file (Test)"* ]]
+}
+
+@test "asdf plugin log prints commits between refs" {
+  first_ref="$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)"
+  touch "$BASE_DIR/repo-dummy/new-file"
+  git -C "$BASE_DIR/repo-dummy" add -A
+  git -C "$BASE_DIR/repo-dummy" commit -q -m "add new file"
+  run asdf plugin update dummy
+
+  run asdf plugin log dummy "$first_ref" HEAD
+  [ "$status" -eq 0 ]
+  [ "$output" = "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse --short=7 HEAD) add new file (Test)" ]
+}
+
 # TODO: Get these tests passing
 #@test "asdf plugin update done for all plugins" {
 #  local command="asdf plugin update --all"
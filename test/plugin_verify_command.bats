@@ -0,0 +1,80 @@
+#!/usr/bin/env bats
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  install_mock_plugin_repo "dummy"
+
+  PROJECT_DIR="$HOME/project"
+  mkdir -p "$PROJECT_DIR"
+  cd "$PROJECT_DIR" || exit
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "plugin_verify command fails when plugin_verification is not set" {
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+
+  run asdf plugin verify
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"plugin_verification is not set"* ]]
+}
+
+@test "plugin_verify command reports plugins at the commit .plugin-versions pins" {
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+  run asdf plugin lock
+  echo 'plugin_verification = pinned' >"$HOME/.asdfrc"
+
+  run asdf plugin verify
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"dummy"*"pinned in $PROJECT_DIR/.plugin-versions"* ]]
+}
+
+@test "plugin update refuses commits that are not pinned" {
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+  run asdf plugin lock
+  ref="$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)"
+  echo 'plugin_verification = pinned' >"$HOME/.asdfrc"
+  git -C "$BASE_DIR/repo-dummy" commit -q --allow-empty -m "not pinned"
+
+  run asdf plugin update dummy
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"but $PROJECT_DIR/.plugin-versions pins $ref"* ]]
+  [ "$(git -C "$ASDF_DIR/plugins/dummy" rev-parse HEAD)" = "$ref" ]
+
+  run asdf plugin verify dummy
+  [ "$status" -eq 0 ]
+}
+
+@test "plugin add refuses plugins whose commit is not signed" {
+  echo "test@example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIFaMAzyYZmFzdVTZnD9f2SaQNmIDUGW0HrOlzG7ZCWI4" >"$HOME/allowed_signers"
+  cat >"$HOME/.asdfrc" <<-EOM
+plugin_verification = signed
+plugin_allowed_signers = $HOME/allowed_signers
+EOM
+
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+  [ "$status" -eq 1 ]
+  [[ "$output" == *"plugin dummy failed verification"*"is not signed"* ]]
+  [ ! -d "$ASDF_DIR/plugins/dummy" ]
+}
+
+@test "plugin add accepts plugins whose commit is signed with an allowed SSH key" {
+  ssh-keygen -q -t ed25519 -N "" -f "$HOME/signing_key" -C test
+  echo "test@example.com $(cat "$HOME/signing_key.pub")" >"$HOME/allowed_signers"
+  git -C "$BASE_DIR/repo-dummy" -c gpg.format=ssh -c user.signingkey="$HOME/signing_key" commit -q -S --allow-empty -m "signed"
+  cat >"$HOME/.asdfrc" <<-EOM
+plugin_verification = signed
+plugin_allowed_signers = $HOME/allowed_signers
+EOM
+
+  run asdf plugin add dummy "$BASE_DIR/repo-dummy"
+  [ "$status" -eq 0 ]
+
+  run asdf plugin verify
+  [ "$status" -eq 0 ]
+  [[ "$output" == *"dummy"*"signed by test@example.com"* ]]
+}
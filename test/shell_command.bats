@@ -0,0 +1,159 @@
+#!/usr/bin/env bats
+# shellcheck disable=SC2030,SC2031,SC2164
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  install_dummy_plugin
+  install_dummy_version "1.0.0"
+  install_dummy_version "1.1.0"
+  install_dummy_version "2.0.0"
+
+  install_dummy_legacy_plugin
+  install_dummy_legacy_version "5.1.0"
+
+  PROJECT_DIR="$HOME/project"
+  mkdir -p "$PROJECT_DIR"
+  cd "$PROJECT_DIR"
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "shell wrapper function should export ENV var" {
+  . "$(dirname "$BATS_TEST_DIRNAME")/asdf.sh"
+  asdf shell "dummy" "1.1.0"
+  [ "$ASDF_DUMMY_VERSION" = "1.1.0" ]
+  unset ASDF_DUMMY_VERSION
+}
+
+@test "shell wrapper function should export version with shell syntax unchanged" {
+  local version_dir="$BASE_DIR/it's \$(touch injected)"
+  mkdir -p "$version_dir"
+  . "$(dirname "$BATS_TEST_DIRNAME")/asdf.sh"
+  asdf shell "dummy" "path:$version_dir"
+  [ "$ASDF_DUMMY_VERSION" = "path:$version_dir" ]
+  [ ! -f injected ]
+  unset ASDF_DUMMY_VERSION
+}
+
+@test "shell wrapper function with --unset should unset ENV var" {
+  . "$(dirname "$BATS_TEST_DIRNAME")/asdf.sh"
+  asdf shell "dummy" "1.1.0"
+  [ "$ASDF_DUMMY_VERSION" = "1.1.0" ]
+  asdf shell "dummy" --unset
+  [ -z "$ASDF_DUMMY_VERSION" ]
+  unset ASDF_DUMMY_VERSION
+}
+
+@test "shell wrapper function should return an error for missing plugins" {
+  . "$(dirname "$BATS_TEST_DIRNAME")/asdf.sh"
+  expected="No such plugin: nonexistent
+version 1.0.0 is not installed for nonexistent"
+
+  run asdf shell "nonexistent" "1.0.0"
+  [ "$status" -eq 1 ]
+  [ "$output" = "$expected" ]
+}
+
+@test "shell wrapper function should support latest" {
+  . "$(dirname "$BATS_TEST_DIRNAME")/asdf.sh"
+  asdf shell "dummy" "latest"
+  [ "$ASDF_DUMMY_VERSION" = "2.0.0" ]
+  unset ASDF_DUMMY_VERSION
+}
+
+@test "shell wrapper function should support latest with filter" {
+  . "$(dirname "$BATS_TEST_DIRNAME")/asdf.sh"
+  asdf shell "dummy" "latest:1"
+  [ "$ASDF_DUMMY_VERSION" = "1.1.0" ]
+  unset ASDF_DUMMY_VERSION
+}
+
+@test "shell wrapper function should replace dashes in the variable name" {
+  . "$(dirname "$BATS_TEST_DIRNAME")/asdf.sh"
+  asdf shell "legacy-dummy" "latest"
+  [ "$ASDF_LEGACY_DUMMY_VERSION" = "5.1.0" ]
+  unset ASDF_LEGACY_DUMMY_VERSION
+}
+
+@test "shell should emit an error when wrapper function is not loaded" {
+  run asdf shell "dummy" "1.1.0"
+  [ "$status" -eq 1 ]
+  [ "$output" = "Shell integration is not enabled. Please ensure you source asdf in your shell setup." ]
+}
+
+@test "export-shell-version should emit an error when plugin does not exist" {
+  expected="No such plugin: nonexistent
+version 1.0.0 is not installed for nonexistent
+false"
+
+  run asdf export-shell-version sh "nonexistent" "1.0.0"
+  [ "$status" -eq 1 ]
+  [ "$output" = "$expected" ]
+}
+
+@test "export-shell-version should emit an error when version does not exist" {
+  expected="version nonexistent is not installed for dummy
+false"
+
+  run asdf export-shell-version sh "dummy" "nonexistent"
+  [ "$status" -eq 1 ]
+  [ "$output" = "$expected" ]
+}
+
+@test "export-shell-version should export version if it exists" {
+  run asdf export-shell-version sh "dummy" "1.1.0"
+  [ "$status" -eq 0 ]
+  [ "$output" = "export ASDF_DUMMY_VERSION='1.1.0'" ]
+}
+
+@test "export-shell-version should use set when shell is fish" {
+  run asdf export-shell-version fish "dummy" "1.1.0"
+  [ "$status" -eq 0 ]
+  [ "$output" = "set -gx ASDF_DUMMY_VERSION '1.1.0'" ]
+}
+
+@test "export-shell-version should use set-env when shell is elvish" {
+  run asdf export-shell-version elvish "dummy" "1.1.0"
+  [ "$status" -eq 0 ]
+  [ "$output" = $'set-env\nASDF_DUMMY_VERSION\n1.1.0' ]
+}
+
+@test "export-shell-version should print a record when shell is nushell" {
+  run asdf export-shell-version nushell "dummy" "1.1.0"
+  [ "$status" -eq 0 ]
+  [ "$output" = '{"ASDF_DUMMY_VERSION": "1.1.0"}' ]
+}
+
+@test "export-shell-version should use Env: when shell is pwsh" {
+  run asdf export-shell-version pwsh "dummy" "1.1.0"
+  [ "$status" -eq 0 ]
+  [ "$output" = "\$Env:ASDF_DUMMY_VERSION = '1.1.0'" ]
+}
+
+@test "export-shell-version should unset when --unset flag is passed" {
+  run asdf export-shell-version sh "dummy" "--unset"
+  [ "$status" -eq 0 ]
+  [ "$output" = "unset ASDF_DUMMY_VERSION" ]
+}
+
+@test "export-shell-version should use set -e when --unset flag is passed and shell is fish" {
+  run asdf export-shell-version fish "dummy" "--unset"
+  [ "$status" -eq 0 ]
+  [ "$output" = "set -e ASDF_DUMMY_VERSION" ]
+}
+
+@test "export-shell-version should use unset-env when --unset flag is passed and shell is elvish" {
+  run asdf export-shell-version elvish "dummy" "--unset"
+  [ "$status" -eq 0 ]
+  [ "$output" = $'unset-env\nASDF_DUMMY_VERSION' ]
+}
+
+@test "export-shell-version should use null when --unset flag is passed and shell is nushell" {
+  run asdf export-shell-version nushell "dummy" "--unset"
+  [ "$status" -eq 0 ]
+  [ "$output" = '{"ASDF_DUMMY_VERSION": null}' ]
+}
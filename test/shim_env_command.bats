@@ -106,3 +106,45 @@ teardown() {
   run grep -q '::' <<<"$path_line"
   [ "$status" -ne 0 ]
 }
+
+@test "asdf env --format=sh prints exports for tools set in current directory" {
+  echo "dummy 1.0" >"$PROJECT_DIR/.tool-versions"
+  run asdf install
+
+  echo '#!/usr/bin/env bash
+  export FOO=bar' >"$ASDF_DIR/plugins/dummy/bin/exec-env"
+  chmod +x "$ASDF_DIR/plugins/dummy/bin/exec-env"
+
+  run asdf env --format=sh
+  [ "$status" -eq 0 ]
+  echo "$output" | grep "^export FOO='bar'$"
+  echo "$output" | grep "^export PATH='$ASDF_DIR/installs/dummy/1.0/bin:"
+}
+
+@test "asdf env --format=sh preserves exec-env values containing equals signs" {
+  echo "dummy 1.0" >"$PROJECT_DIR/.tool-versions"
+  run asdf install
+
+  echo '#!/usr/bin/env bash
+  export JAVA_OPTS=-Dfoo=bar' >"$ASDF_DIR/plugins/dummy/bin/exec-env"
+  chmod +x "$ASDF_DIR/plugins/dummy/bin/exec-env"
+
+  run asdf env --format=sh
+  [ "$status" -eq 0 ]
+  echo "$output" | grep "^export JAVA_OPTS='-Dfoo=bar'$"
+}
+
+@test "asdf env --format=json prints environment of a single tool" {
+  echo "dummy 1.0" >"$PROJECT_DIR/.tool-versions"
+  run asdf install
+
+  run asdf env --format=json dummy
+  [ "$status" -eq 0 ]
+  echo "$output" | grep "\"PATH\": \"$ASDF_DIR/installs/dummy/1.0/bin:"
+}
+
+@test "asdf env --format with unknown format prints error" {
+  run asdf env --format=xml
+  [ "$status" -eq 1 ]
+  echo "$output" | grep 'unknown format "xml"'
+}
@@ -25,6 +25,12 @@ teardown() {
   echo "$output" | grep "usage: asdf exec <command>"
 }
 
+@test "asdf exec should exit 127 for unknown command" {
+  run asdf exec non-existent-command
+  [ "$status" -eq 127 ]
+  [ "$output" = "unknown command: non-existent-command. Perhaps you have to reshim?" ]
+}
+
 @test "asdf exec should pass all arguments to executable" {
   echo "dummy 1.0" >"$PROJECT_DIR/.tool-versions"
   run asdf install
@@ -224,6 +230,11 @@ teardown() {
 
   echo "dummy path:$CUSTOM_DUMMY_PATH" >"$PROJECT_DIR/.tool-versions"
 
+  run "$ASDF_DIR/shims/dummy" hello
+  [ "$status" -ne 0 ]
+  [[ "$output" == *"is not trusted"* ]]
+
+  asdf trust "$PROJECT_DIR/.tool-versions"
   run "$ASDF_DIR/shims/dummy" hello
   [ "$output" = "System" ]
 }
@@ -334,6 +345,37 @@ teardown() {
 #  [ "$output" = "$ASDF_DIR/shims/gummy" ]
 #}
 
+@test "asdf exec should put other tools on PATH when exec_all_tools_on_path is enabled" {
+  cp -rf "$ASDF_DIR/plugins/dummy" "$ASDF_DIR/plugins/gummy"
+  sed -i -e 's/bin\/dummy/bin\/gummy/g' "$ASDF_DIR/plugins/gummy/bin/install"
+
+  echo "dummy 1.0" >"$PROJECT_DIR/.tool-versions"
+  echo "gummy 2.0.0" >>"$PROJECT_DIR/.tool-versions"
+  run asdf install
+
+  printf '#!/usr/bin/env bash\ncommand -v gummy\n' >"$ASDF_DIR/installs/dummy/1.0/bin/dummy"
+  echo 'exec_all_tools_on_path = yes' >"$HOME/.asdfrc"
+
+  run asdf exec dummy
+  [ "$status" -eq 0 ]
+  [ "$output" = "$ASDF_DIR/installs/gummy/2.0.0/bin/gummy" ]
+}
+
+@test "asdf exec should not put other tools on PATH when opted out with ASDF_EXEC_ALL_TOOLS_ON_PATH" {
+  cp -rf "$ASDF_DIR/plugins/dummy" "$ASDF_DIR/plugins/gummy"
+  sed -i -e 's/bin\/dummy/bin\/gummy/g' "$ASDF_DIR/plugins/gummy/bin/install"
+
+  echo "dummy 1.0" >"$PROJECT_DIR/.tool-versions"
+  echo "gummy 2.0.0" >>"$PROJECT_DIR/.tool-versions"
+  run asdf install
+
+  printf '#!/usr/bin/env bash\ncommand -v gummy\n' >"$ASDF_DIR/installs/dummy/1.0/bin/dummy"
+  echo 'exec_all_tools_on_path = yes' >"$HOME/.asdfrc"
+
+  ASDF_EXEC_ALL_TOOLS_ON_PATH=no run asdf exec dummy
+  [ "$output" != "$ASDF_DIR/installs/gummy/2.0.0/bin/gummy" ]
+}
+
 @test "shim exec should remove shim_path from path on system version execution" {
   run asdf install dummy 2.0.0
 
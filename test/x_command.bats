@@ -0,0 +1,58 @@
+#!/usr/bin/env bats
+# shellcheck disable=SC2164
+
+load test_helpers
+
+setup() {
+  setup_asdf_dir
+  install_dummy_plugin
+
+  PROJECT_DIR="$HOME/project"
+  mkdir -p "$PROJECT_DIR"
+  cd "$PROJECT_DIR"
+}
+
+teardown() {
+  clean_asdf_dir
+}
+
+@test "asdf x without command should display usage" {
+  run asdf x dummy@1.0
+  [ "$status" -eq 1 ]
+  echo "$output" | grep "usage: asdf x"
+}
+
+@test "asdf x should install missing version and run command" {
+  run asdf x dummy@1.0 -- dummy
+  [ "$status" -eq 0 ]
+  [ "${lines[-1]}" = "This is Dummy 1.0! " ]
+  [ -d "$ASDF_DIR/installs/dummy/1.0" ]
+  [ ! -f "$PROJECT_DIR/.tool-versions" ]
+}
+
+@test "asdf x should ignore version set in .tool-versions" {
+  echo "dummy 1.1" >"$PROJECT_DIR/.tool-versions"
+
+  run asdf x dummy@1.0 -- dummy
+  [ "$status" -eq 0 ]
+  [ "${lines[-1]}" = "This is Dummy 1.0! " ]
+  [ "$(cat "$PROJECT_DIR/.tool-versions")" = "dummy 1.1" ]
+}
+
+@test "asdf x --rm should uninstall version installed for command" {
+  run asdf x --rm dummy@1.0 -- dummy
+  [ "$status" -eq 0 ]
+  [ ! -d "$ASDF_DIR/installs/dummy/1.0" ]
+}
+
+@test "asdf x --rm should keep versions that were already installed" {
+  run asdf install dummy 1.0
+  run asdf x --rm dummy@1.0 -- dummy
+  [ "$status" -eq 0 ]
+  [ -d "$ASDF_DIR/installs/dummy/1.0" ]
+}
+
+@test "asdf x should exit with status of command" {
+  run asdf x --rm dummy@1.0 -- sh -c 'exit 3'
+  [ "$status" -eq 3 ]
+}